@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/enrich"
+	"github.com/andrebq/vogelnest/internal/hooks"
+	"github.com/andrebq/vogelnest/internal/langid"
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/andrebq/vogelnest/internal/sentiment"
+	"github.com/andrebq/vogelnest/internal/text"
+	"github.com/andrebq/vogelnest/internal/tweets"
+)
+
+var (
+	enrichStages  = flag.String("enrich", "lang,tokens,sentiment,domains,location,hooks", "Comma separated list of stages applied to every tweet, in order")
+	enrichWorkers = flag.Int("enrich-workers", 4, "How many tweets are enriched at once")
+	hooksDir      = flag.String("hooks", "", "Directory with lua scripts run over every tweet, empty disables it")
+	hooksReload   = flag.Duration("hooks-reload", 2*time.Second, "How often the hooks directory is checked for changes")
+	hooksTimeout  = flag.Duration("hooks-timeout", 100*time.Millisecond, "Maximum time a hook can take with one tweet")
+)
+
+type (
+	// stages started by addStages which are used by other services,
+	// nil when disabled
+	stages struct {
+		sentiments *sentiment.Aggregator
+		hooks      *hooks.Hooks
+	}
+)
+
+// addStages adds the stages listed by -enrich to stream
+func addStages(stream *tweets.Stream) (stages, error) {
+	var out stages
+	for _, name := range strings.Split(*enrichStages, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "":
+		case "lang":
+			switch *langDetect {
+			case "always", "unknown":
+				detector := langid.NewDetector(langid.Options{Always: *langDetect == "always", MinConfidence: *langMinConf})
+				stream.Enrich(name, detector.Detect)
+			case "never":
+			default:
+				return out, fmt.Errorf("invalid language detection mode: %v", *langDetect)
+			}
+		case "tokens":
+			// stop words depend on the language, list lang first
+			tokenizer := text.NewTokenizer(text.Options{NGrams: *ngrams, KeepStopWords: !*stopWords})
+			stream.Enrich(name, func(t *schema.Tweet) { t.Tokens = tokenizer.Tokens(t) })
+		case "sentiment":
+			if len(*lexicons) == 0 {
+				continue
+			}
+			lex, err := sentiment.LoadLexicons(*lexicons)
+			if err != nil {
+				return out, err
+			}
+			analyzer := sentiment.NewAnalyzer(lex)
+			sentiments := sentiment.NewAggregator(*sentBucket, *sentBuckets)
+			stream.Enrich(name, func(t *schema.Tweet) {
+				analyzer.Score(t)
+				sentiments.Add(t)
+			})
+			out.sentiments = sentiments
+		case "domains":
+			stream.Enrich(name, enrich.Domains)
+		case "location":
+			stream.Enrich(name, enrich.Location)
+		case "hooks":
+			if len(*hooksDir) == 0 {
+				continue
+			}
+			h, err := hooks.NewHooks(hooks.Options{Dir: *hooksDir, Interval: *hooksReload, Timeout: *hooksTimeout})
+			if err != nil {
+				return out, err
+			}
+			stream.Filter(name, h.Process)
+			out.hooks = h
+		default:
+			return out, fmt.Errorf("unknown enrichment stage: %v", name)
+		}
+	}
+	return out, nil
+}
@@ -4,3 +4,10 @@ env.set('TWITTER_API_SECRET_KEY', '<value here>')
 
 env.set('TWITTER_ACCESS_TOKEN', '<value here>')
 env.set('TWITTER_ACCESS_TOKEN_SECRET', '<value here>')
+
+-- optional, encrypts stored tweets: <id>:<32 bytes hex encoded>
+-- env.set('VOGELNEST_STORAGE_KEYS', '<value here>')
+
+-- optional, credentials for the bucket given by -tier-endpoint
+-- env.set('VOGELNEST_TIER_ACCESS_KEY', '<value here>')
+-- env.set('VOGELNEST_TIER_SECRET_KEY', '<value here>')
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/andrebq/vogelnest/internal/storage"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// exportWindow returns the default range of export: the 24 hours
+// before the current one, which a running server keeps locked
+func exportWindow(now time.Time) (from, to time.Time) {
+	to = now.Truncate(time.Hour)
+	return to.Add(-24 * time.Hour), to
+}
+
+// export writes tweets as JSON lines to stdout and returns the process exit code
+func export(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	start, end := exportWindow(time.Now())
+	from := fs.String("from", start.Format(time.RFC3339), "First moment to export (RFC3339)")
+	to := fs.String("to", end.Format(time.RFC3339), "Export tweets saved before this moment (RFC3339), badger can't read the hour a running server is writing")
+	fs.Parse(args)
+
+	logctx := log.With().Str("command", "export").Logger()
+	var err error
+	start, err = time.Parse(time.RFC3339, *from)
+	if err != nil {
+		logctx.Error().Err(err).Msg("Invalid -from")
+		return 2
+	}
+	end, err = time.Parse(time.RFC3339, *to)
+	if err != nil {
+		logctx.Error().Err(err).Msg("Invalid -to")
+		return 2
+	}
+	keys, err := loadKeys()
+	if err != nil {
+		logctx.Error().Err(err).Msg("Unable to load storage keys")
+		return 2
+	}
+	tier, err := loadTier()
+	if err != nil {
+		logctx.Error().Err(err).Msg("Unable to connect to the bucket")
+		return 2
+	}
+	reader, err := storage.OpenReader(*storageKind, *storageDir, storage.StoreOptions{Keys: keys, Tier: tier})
+	if err != nil {
+		logctx.Error().Err(err).Msg("Unable to open storage")
+		return 2
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	err = reader.ReadRange(start, end, func(t *schema.Tweet) error {
+		buf, err := protojson.Marshal(t)
+		if err != nil {
+			return err
+		}
+		out.Write(buf)
+		return out.WriteByte('\n')
+	})
+	if err != nil {
+		logctx.Error().Err(err).Msg("Unable to export tweets")
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/andrebq/vogelnest/internal/storage"
+)
+
+func TestExportWindow(t *testing.T) {
+	now := time.Date(2020, 10, 18, 10, 25, 0, 0, time.UTC)
+	from, to := exportWindow(now)
+	if want := time.Date(2020, 10, 18, 10, 0, 0, 0, time.UTC); !to.Equal(want) {
+		t.Errorf("expecting -to %v got %v", want, to)
+	}
+	if want := time.Date(2020, 10, 17, 10, 0, 0, 0, time.UTC); !from.Equal(want) {
+		t.Errorf("expecting -from %v got %v", want, from)
+	}
+}
+
+func TestExportWhileWriting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vogelnest-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	// the writer locks the current hour
+	store, err := storage.OpenStore("badger", dir, storage.StoreOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.Append(&schema.Tweet{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := storage.NewLogReader(dir, storage.StoreOptions{})
+	noop := func(*schema.Tweet) error { return nil }
+	from, to := exportWindow(now)
+	if err := reader.ReadRange(from, to, noop); err != nil {
+		t.Errorf("the default window should skip the current hour: %v", err)
+	}
+	if err := reader.ReadRange(from, time.Now().Add(time.Hour), noop); err == nil {
+		t.Error("expecting an error while reading the hour being written")
+	}
+}
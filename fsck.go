@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/andrebq/vogelnest/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// fsck checks the tweet log and returns the process exit code
+func fsck(args []string) int {
+	fs := flag.NewFlagSet("fsck", flag.ExitOnError)
+	quarantine := fs.Bool("quarantine", false, "Move damaged entries to a quarantine database")
+	fs.Parse(args)
+
+	keys, err := loadKeys()
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to load storage keys")
+		return 2
+	}
+	reports, err := storage.Fsck(*storageDir, storage.FsckOptions{Quarantine: *quarantine, Keys: keys})
+	if err != nil {
+		log.Error().Err(err).Str("storage", *storageDir).Msg("Unable to check tweet log")
+		return 2
+	}
+	code := 0
+	for _, r := range reports {
+		if r.Err != nil {
+			fmt.Printf("%v: unable to check: %v\n", r.DB, r.Err)
+			code = 1
+			continue
+		}
+		for _, p := range r.Damaged {
+			fmt.Printf("%v: %x: %v\n", r.DB, p.Key, p.Reason)
+		}
+		fmt.Printf("%v: %v entries, %v damaged, %v quarantined, %v unchecked (encrypted)\n", r.DB, r.Entries, len(r.Damaged), r.Quarantined, r.Unchecked)
+		if len(r.Damaged) > r.Quarantined {
+			code = 1
+		}
+	}
+	return code
+}
@@ -3,15 +3,19 @@ module github.com/andrebq/vogelnest
 go 1.14
 
 require (
+	github.com/abadojack/whatlanggo v1.0.1
 	github.com/dghubble/go-twitter v0.0.0-20200725221434-4bc8ad7ad1b4
 	github.com/dghubble/oauth1 v0.6.0
 	github.com/dgraph-io/badger v1.6.2
 	github.com/golang/protobuf v1.4.2
 	github.com/golang/snappy v0.0.2
 	github.com/gorilla/websocket v1.4.2
+	github.com/minio/minio-go/v6 v6.0.55
 	github.com/prometheus/client_golang v1.7.1
 	github.com/rs/cors v1.7.0
 	github.com/rs/zerolog v1.20.0
 	github.com/thejerf/suture v3.0.3+incompatible
+	github.com/yuin/gopher-lua v0.0.0-20200816102855-ee81675732da
+	golang.org/x/text v0.3.0
 	google.golang.org/protobuf v1.23.0
 )
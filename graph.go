@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/graph"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	graphDir     = flag.String("graph-dir", "/var/data/vogelnest/graph", "Where to keep the graphs built from tweets")
+	graphNames   = flag.String("graphs", "hashtags,users", "Comma separated list of graphs to build: "+strings.Join(graph.Kinds(), ", ")+", empty disables it. entities has a node per tweet, so it is only built when listed; /graph/bridges needs it")
+	graphBucket  = flag.Duration("graph-bucket", time.Hour, "Time span of the counts kept for each graph, only used when -graph-dir is created")
+	graphFlushes = flag.Duration("graph-flush-interval", 10*time.Second, "Maximum time graph counts wait in memory before being saved")
+
+	graphAnalyze        = flag.String("graph-analyze", "hashtags,users", "Comma separated list of graphs analysed periodically, empty disables it")
+	graphAnalyses       = flag.String("graph-analyses", "communities,pagerank,indegree,outdegree,betweenness", "Comma separated list of analyses run over each graph: "+strings.Join(graph.Analyses(), ", "))
+	graphAnalysisEvery  = flag.Duration("graph-analysis-interval", time.Hour, "Time between analyses of each graph")
+	graphAnalysisWindow = flag.Duration("graph-analysis-window", 24*time.Hour, "How far back each analysis looks")
+	graphAnalysisTop    = flag.Int("graph-analysis-top", 10000, "Analyse only the N heaviest nodes of each graph, zero analyses every node")
+	graphAnalysisKeep   = flag.Int("graph-analysis-keep", 168, "Number of runs kept for each graph and analysis, zero keeps every run")
+)
+
+// graphList returns the graphs listed by -graphs
+func graphList() []string {
+	return splitList(*graphNames)
+}
+
+func splitList(list string) []string {
+	var out []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); len(name) > 0 {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// newAnalyzer returns the service analysing the graphs of store
+// listed by -graph-analyze, nil when there isn't one. Runs record
+// the terms returned by terms
+func newAnalyzer(store *graph.Store, terms func() []string) (*graph.Analyzer, error) {
+	graphs, names := splitList(*graphAnalyze), splitList(*graphAnalyses)
+	if len(graphs) == 0 || len(names) == 0 {
+		return nil, nil
+	}
+	return graph.NewAnalyzer(store, graph.AnalyzerOptions{
+		Graphs:   graphs,
+		Analyses: names,
+		Interval: *graphAnalysisEvery,
+		Window:   *graphAnalysisWindow,
+		Top:      *graphAnalysisTop,
+		Keep:     *graphAnalysisKeep,
+		Terms:    terms,
+	})
+}
+
+// openGraphs returns the store of -graph-dir, nil when no graph is built
+func openGraphs() (*graph.Store, error) {
+	if len(graphList()) == 0 {
+		return nil, nil
+	}
+	return graph.OpenStore(*graphDir, graph.StoreOptions{Bucket: *graphBucket})
+}
+
+// graphCommand runs the graph subcommands and returns the process exit code
+func graphCommand(args []string) int {
+	if len(args) == 0 {
+		log.Error().Str("command", "graph").Msg("Expecting a subcommand: export")
+		return 2
+	}
+	switch args[0] {
+	case "export":
+		return graphExport(args[1:])
+	}
+	log.Error().Str("command", "graph").Str("subcommand", args[0]).Msg("Unknown subcommand")
+	return 2
+}
+
+// graphExport writes a graph to stdout, or to -o
+func graphExport(args []string) int {
+	fs := flag.NewFlagSet("graph export", flag.ExitOnError)
+	name := fs.String("graph", "hashtags", "Graph to export: "+strings.Join(graph.Kinds(), ", "))
+	format := fs.String("format", "gexf", "Output format: "+strings.Join(graph.Formats(), ", "))
+	from := fs.String("from", "", "Start of the window (RFC3339), empty exports from the beginning")
+	to := fs.String("to", "", "End of the window (RFC3339), empty exports up to now")
+	since := fs.Duration("since", 0, "Start the window this long before now, instead of -from")
+	minWeight := fs.Int64("min-weight", 0, "Skip edges lighter than this")
+	top := fs.Int("top", 0, "Keep only the N heaviest nodes, zero keeps every node")
+	output := fs.String("o", "", "Output file, empty writes to stdout")
+	fs.Parse(args)
+
+	logctx := log.With().Str("command", "graph-export").Logger()
+	f := graph.Filter{MinWeight: *minWeight, Top: *top}
+	var err error
+	if len(*from) > 0 {
+		f.From, err = time.Parse(time.RFC3339, *from)
+		if err != nil {
+			logctx.Error().Err(err).Msg("Invalid -from")
+			return 2
+		}
+	}
+	if *since > 0 {
+		f.From = time.Now().Add(-*since)
+	}
+	if len(*to) > 0 {
+		f.To, err = time.Parse(time.RFC3339, *to)
+		if err != nil {
+			logctx.Error().Err(err).Msg("Invalid -to")
+			return 2
+		}
+	}
+	// the server keeps the store locked, use /graph/export while it runs
+	store, err := graph.OpenStore(*graphDir, graph.StoreOptions{ReadOnly: true})
+	if err != nil {
+		logctx.Error().Err(err).Msg("Unable to open graph store")
+		return 2
+	}
+	defer store.Close()
+
+	out := os.Stdout
+	if len(*output) > 0 {
+		out, err = os.Create(*output)
+		if err != nil {
+			logctx.Error().Err(err).Msg("Unable to create output")
+			return 2
+		}
+		defer out.Close()
+	}
+	w := bufio.NewWriter(out)
+	err = store.Export(w, *name, *format, f)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		logctx.Error().Err(err).Msg("Unable to export graph")
+		return 1
+	}
+	return 0
+}
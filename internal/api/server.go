@@ -10,11 +10,20 @@ import (
 
 	"github.com/rs/cors"
 
-	"github.com/dghubble/go-twitter/twitter"
+	"github.com/andrebq/vogelnest/internal/schema"
 	"github.com/gorilla/websocket"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// jsonProtocol and protobufProtocol are the websocket subprotocols
+	// used to pick how tweets are encoded
+	jsonProtocol     = "vogelnest.json"
+	protobufProtocol = "vogelnest.protobuf"
 )
 
 type (
@@ -26,8 +35,10 @@ type (
 		port              int
 		serveStatic       string
 		setTerms          func([]string) bool
-		addsink           func(int) <-chan *twitter.Tweet
-		removesink        func(<-chan *twitter.Tweet)
+		addsink           func(int) <-chan *schema.Tweet
+		removesink        func(<-chan *schema.Tweet)
+		health            func() error
+		handlers          map[string]http.Handler
 		logCtx            zerolog.Logger
 		sampledCtx        zerolog.Logger
 		corsOrigins       []string
@@ -38,8 +49,9 @@ type (
 func NewServer(addr string, port int, serveStatic string,
 	corsOrigins []string,
 	setTerms func([]string) bool,
-	addsink func(int) <-chan *twitter.Tweet,
-	removesink func(<-chan *twitter.Tweet)) *Server {
+	addsink func(int) <-chan *schema.Tweet,
+	removesink func(<-chan *schema.Tweet),
+	health func() error) *Server {
 	s := &Server{
 		addr:        addr,
 		port:        port,
@@ -47,9 +59,11 @@ func NewServer(addr string, port int, serveStatic string,
 		setTerms:    setTerms,
 		addsink:     addsink,
 		removesink:  removesink,
+		health:      health,
 
 		upgrader: &websocket.Upgrader{
-			CheckOrigin: func(_ *http.Request) bool { return true },
+			CheckOrigin:  func(_ *http.Request) bool { return true },
+			Subprotocols: []string{protobufProtocol, jsonProtocol},
 		},
 
 		logCtx:      log.With().Str("service", "api-server").Logger(),
@@ -59,6 +73,15 @@ func NewServer(addr string, port int, serveStatic string,
 	return s
 }
 
+// Handle adds h to the routes served by s, it must
+// be called before Serve
+func (s *Server) Handle(pattern string, h http.Handler) {
+	if s.handlers == nil {
+		s.handlers = make(map[string]http.Handler)
+	}
+	s.handlers[pattern] = h
+}
+
 // Serve incoming requests using the default mux
 func (s *Server) Serve() {
 	logCtx := s.logCtx
@@ -85,6 +108,10 @@ func (s *Server) rootHandler() http.Handler {
 	mux.Handle("/metrics", promhttp.Handler())
 	mux.HandleFunc("/stream/terms", s.handleSetTerms)
 	mux.HandleFunc("/stream/ws", s.handleWebsocket)
+	mux.HandleFunc("/healthz", s.handleHealth)
+	for pattern, h := range s.handlers {
+		mux.Handle(pattern, h)
+	}
 	if len(s.serveStatic) > 0 {
 		fs := http.FileServer(http.Dir(s.serveStatic))
 		mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
@@ -115,6 +142,17 @@ func (s *Server) rootHandler() http.Handler {
 	return c.Handler(mux)
 }
 
+func (s *Server) handleHealth(w http.ResponseWriter, req *http.Request) {
+	if s.health != nil {
+		if err := s.health(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.Header().Set("content-type", "text/plain")
+	fmt.Fprintln(w, "ok")
+}
+
 func (s *Server) handleSetTerms(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
 	case "PUT":
@@ -137,19 +175,58 @@ func (s *Server) handleSetTerms(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// handleWebsocket sends schema.Tweet messages as protojson text frames,
+// clients which ask for the protobuf subprotocol (or ?format=protobuf)
+// receive binary protobuf frames instead
 func (s *Server) handleWebsocket(w http.ResponseWriter, req *http.Request) {
 	c, err := s.upgrader.Upgrade(w, req, nil)
 	if err != nil {
 		s.logCtx.Error().Err(err).Str("action", "handle-websocket").Msg("Unable to setup ws connection")
 		return
 	}
-	s.sampledCtx.Info().Str("conn", c.RemoteAddr().String()).Msg("New WebSocket connection")
+	binary := c.Subprotocol() == protobufProtocol || req.URL.Query().Get("format") == "protobuf"
+	s.sampledCtx.Info().Str("conn", c.RemoteAddr().String()).Bool("protobuf", binary).Msg("New WebSocket connection")
 	defer c.Close()
 	output := s.addsink(100)
-	for v := range output {
-		err := c.WriteJSON(v)
+	defer s.removesink(output)
+
+	// clients don't send messages, reading is needed to notice
+	// when they go away without waiting for the next tweet
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := c.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	for {
+		var v *schema.Tweet
+		var ok bool
+		select {
+		case <-closed:
+			return
+		case v, ok = <-output:
+		}
+		if !ok {
+			return
+		}
+		var buf []byte
+		msgType := websocket.TextMessage
+		if binary {
+			msgType = websocket.BinaryMessage
+			buf, err = proto.Marshal(v)
+		} else {
+			buf, err = protojson.Marshal(v)
+		}
 		if err != nil {
-			s.removesink(output)
+			s.logCtx.Error().Err(err).Str("action", "encode-tweet").Int64("tweet", v.Id).Send()
+			continue
+		}
+		err = c.WriteMessage(msgType, buf)
+		if err != nil {
+			return
 		}
 	}
 }
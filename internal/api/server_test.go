@@ -0,0 +1,133 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+type testSinks struct {
+	added   chan chan *schema.Tweet
+	removed chan (<-chan *schema.Tweet)
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *testSinks) {
+	sinks := &testSinks{
+		added:   make(chan chan *schema.Tweet, 1),
+		removed: make(chan (<-chan *schema.Tweet), 1),
+	}
+	s := NewServer("", 0, "", nil, nil,
+		func(size int) <-chan *schema.Tweet {
+			sink := make(chan *schema.Tweet, size)
+			sinks.added <- sink
+			return sink
+		},
+		func(sink <-chan *schema.Tweet) { sinks.removed <- sink },
+		nil)
+	srv := httptest.NewServer(s.rootHandler())
+	t.Cleanup(srv.Close)
+	return srv, sinks
+}
+
+func (ts *testSinks) next(t *testing.T) chan *schema.Tweet {
+	t.Helper()
+	select {
+	case sink := <-ts.added:
+		return sink
+	case <-time.After(5 * time.Second):
+		t.Fatal("the sink wasn't added")
+	}
+	return nil
+}
+
+func dial(t *testing.T, srv *httptest.Server, query string, protocols ...string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/stream/ws" + query
+	c, _, err := (&websocket.Dialer{Subprotocols: protocols}).Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestWebsocketFormat(t *testing.T) {
+	srv, sinks := newTestServer(t)
+	for _, tc := range []struct {
+		name      string
+		query     string
+		protocols []string
+		binary    bool
+	}{
+		{name: "default"},
+		{name: "json protocol", protocols: []string{jsonProtocol}},
+		{name: "protobuf protocol", protocols: []string{protobufProtocol}, binary: true},
+		{name: "protobuf query", query: "?format=protobuf", binary: true},
+		{name: "first protocol", protocols: []string{"unknown", protobufProtocol, jsonProtocol}, binary: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := dial(t, srv, tc.query, tc.protocols...)
+			defer c.Close()
+			sink := sinks.next(t)
+			sink <- &schema.Tweet{Id: 42, Text: "hello"}
+
+			msgType, buf, err := c.ReadMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got schema.Tweet
+			if tc.binary {
+				if msgType != websocket.BinaryMessage {
+					t.Fatalf("expecting a binary frame got %v", msgType)
+				}
+				err = proto.Unmarshal(buf, &got)
+			} else {
+				if msgType != websocket.TextMessage {
+					t.Fatalf("expecting a text frame got %v", msgType)
+				}
+				err = protojson.Unmarshal(buf, &got)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Id != 42 || got.Text != "hello" {
+				t.Errorf("unexpected tweet %v", &got)
+			}
+		})
+	}
+}
+
+func TestWebsocketDisconnect(t *testing.T) {
+	srv, sinks := newTestServer(t)
+	c := dial(t, srv, "")
+	sink := sinks.next(t)
+
+	// no tweet is sent, the sink is removed as soon as the client leaves
+	c.Close()
+	select {
+	case removed := <-sinks.removed:
+		if removed != (<-chan *schema.Tweet)(sink) {
+			t.Error("expecting the sink of the connection to be removed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the sink wasn't removed")
+	}
+
+	// closing the sink ends the connection
+	c = dial(t, srv, "")
+	defer c.Close()
+	close(sinks.next(t))
+	if _, _, err := c.ReadMessage(); err == nil {
+		t.Error("expecting the connection to be closed")
+	}
+	select {
+	case <-sinks.removed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the sink wasn't removed")
+	}
+}
@@ -0,0 +1,41 @@
+// Package enrich has the stages which derive data from
+// the fields of tweets, without external services
+package enrich
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+// Domains sets the domains of t to the hosts of its links, lowercase
+// and without www. Retweets use the links of the original tweet
+func Domains(t *schema.Tweet) {
+	content := t
+	if t.Retweet != nil {
+		content = t.Retweet
+	}
+	seen := make(map[string]struct{})
+	t.Domains = nil
+	for _, u := range content.GetEntities().GetUrls() {
+		d := domain(u.ExpandedUrl)
+		if len(d) == 0 {
+			continue
+		}
+		if _, ok := seen[d]; ok {
+			continue
+		}
+		seen[d] = struct{}{}
+		t.Domains = append(t.Domains, d)
+	}
+}
+
+func domain(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	return strings.TrimPrefix(host, "www.")
+}
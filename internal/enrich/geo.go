@@ -0,0 +1,25 @@
+package enrich
+
+import (
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+// Location sets the location of t to its coordinates, or to the
+// center of the bounding box of its place when it isn't geotagged
+func Location(t *schema.Tweet) {
+	if c := t.Coordinates; c != nil && (c.Lat != 0 || c.Long != 0) {
+		t.Location = &schema.Coordinates{Lat: c.Lat, Long: c.Long, Type: c.Type}
+		return
+	}
+	box := t.GetPlace().GetBoundingBox()
+	if len(box) == 0 {
+		return
+	}
+	var lat, long float64
+	for _, c := range box {
+		lat += c.Lat
+		long += c.Long
+	}
+	n := float64(len(box))
+	t.Location = &schema.Coordinates{Lat: lat / n, Long: long / n, Type: "Centroid"}
+}
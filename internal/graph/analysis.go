@@ -0,0 +1,307 @@
+package graph
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger"
+)
+
+type (
+	// Run is the result of one analysis of a graph, the value computed
+	// for each node is kept apart, see RunValues
+	Run struct {
+		Analysis string    `json:"analysis"`
+		Graph    string    `json:"graph"`
+		At       time.Time `json:"at"`
+		// From and To are the window analysed
+		From  time.Time `json:"from"`
+		To    time.Time `json:"to"`
+		Nodes int       `json:"nodes"`
+		// Score summarizes the run, eg.: the modularity of communities
+		Score float64 `json:"score"`
+		// Groups is the number of distinct values, eg.: communities
+		Groups int `json:"groups,omitempty"`
+		// Terms tracked by the stream when the run started, sorted
+		Terms []string `json:"terms,omitempty"`
+	}
+
+	// RunOptions control how runs are saved
+	RunOptions struct {
+		// Keep is the number of runs kept for each graph and
+		// analysis, zero keeps every run
+		Keep int
+		// Terms tracked by the stream, see Run
+		Terms []string
+	}
+
+	// analysis computes a value for each node of a graph
+	analysis struct {
+		name string
+		// run returns the value of each node of g, the score
+		// and the number of groups of the run
+		run func(g *Graph) (values []float64, score float64, groups int)
+	}
+)
+
+var (
+	analyses = make(map[string]*analysis)
+)
+
+func registerAnalysis(a *analysis) {
+	analyses[a.name] = a
+}
+
+// Analyses returns the name of every analysis, sorted
+func Analyses() []string {
+	out := make([]string, 0, len(analyses))
+	for name := range analyses {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func errUnknownAnalysis(name string) error {
+	return fmt.Errorf("unknown analysis %v, expecting one of %v", name, Analyses())
+}
+
+// Analyze loads the part of graph selected by f and runs each of
+// the given analyses over it
+func (s *Store) Analyze(graph string, names []string, f Filter, opts RunOptions) ([]Run, error) {
+	for _, name := range names {
+		if analyses[name] == nil {
+			return nil, errUnknownAnalysis(name)
+		}
+	}
+	g, err := s.Load(graph, f)
+	if err != nil {
+		return nil, err
+	}
+	at := time.Now().UTC()
+	terms := normalizeTerms(opts.Terms)
+	out := make([]Run, 0, len(names))
+	for _, name := range names {
+		values, score, groups := analyses[name].run(g)
+		r := Run{
+			Analysis: name,
+			Graph:    graph,
+			At:       at,
+			From:     f.From,
+			To:       f.To,
+			Nodes:    len(g.IDs),
+			Score:    score,
+			Groups:   groups,
+			Terms:    terms,
+		}
+		err = s.saveRun(&r, g, values, opts.Keep)
+		if err != nil {
+			return out, fmt.Errorf("unable to save %v of %v: %w", name, graph, err)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// runPrefix returns the prefix of the runs of analysis over graph
+func runPrefix(prefix byte, analysis, graph string) []byte {
+	out := join([]byte{prefix}, analysis, graph)
+	return append(out, sep)
+}
+
+func runKey(prefix byte, r *Run) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(r.At.UnixNano()))
+	return append(runPrefix(prefix, r.Analysis, r.Graph), b[:]...)
+}
+
+// saveRun keeps r with the value of each node of g, only the
+// last keep runs of the same analysis and graph are kept
+func (s *Store) saveRun(r *Run, g *Graph, values []float64, keep int) error {
+	if !validNames(r.Analysis, r.Graph) {
+		return ErrInvalidName
+	}
+	buf, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	err = s.write(func(w *writer) error {
+		prefix := runKey(prefixRunValue, r)
+		for i, id := range g.IDs {
+			var v [8]byte
+			binary.BigEndian.PutUint64(v[:], math.Float64bits(values[i]))
+			err := w.set(append(append([]byte(nil), prefix...), id...), v[:])
+			if err != nil {
+				return err
+			}
+		}
+		// the run is only listed once every value is saved
+		return w.set(runKey(prefixRun, r), buf)
+	})
+	if err != nil {
+		return err
+	}
+	return s.pruneRuns(r.Analysis, r.Graph, keep)
+}
+
+// Runs returns the runs of analysis over graph, newest first
+func (s *Store) Runs(analysis, graph string) ([]Run, error) {
+	out := []Run{}
+	prefix := runPrefix(prefixRun, analysis, graph)
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		// reverse iteration starts at the last key before the seek key
+		for it.Seek(append(append([]byte(nil), prefix...), 0xff)); it.ValidForPrefix(prefix); it.Next() {
+			var r Run
+			err := it.Item().Value(func(v []byte) error {
+				return json.Unmarshal(v, &r)
+			})
+			if err != nil {
+				return err
+			}
+			out = append(out, r)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// LatestRun returns the most recent run of analysis over graph,
+// ErrNotFound if there isn't one
+func (s *Store) LatestRun(analysis, graph string) (*Run, error) {
+	runs, err := s.Runs(analysis, graph)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, ErrNotFound
+	}
+	return &runs[0], nil
+}
+
+// RunValues calls fn with the value of every node in r, ordered by ID
+func (s *Store) RunValues(r *Run, fn func(node string, v float64) error) error {
+	prefix := runKey(prefixRunValue, r)
+	return s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			node := string(it.Item().Key()[len(prefix):])
+			var v float64
+			err := it.Item().Value(func(buf []byte) error {
+				v = math.Float64frombits(binary.BigEndian.Uint64(buf))
+				return nil
+			})
+			if err == nil {
+				err = fn(node, v)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RunValue returns the value of node in r, ok is false
+// if the node wasn't part of it
+func (s *Store) RunValue(r *Run, node string) (v float64, ok bool, err error) {
+	err = s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(append(runKey(prefixRunValue, r), node...))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		ok = true
+		return item.Value(func(buf []byte) error {
+			v = math.Float64frombits(binary.BigEndian.Uint64(buf))
+			return nil
+		})
+	})
+	return v, ok, err
+}
+
+// pruneRuns removes the runs of analysis over graph older than
+// the last keep ones
+func (s *Store) pruneRuns(analysis, graph string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	runs, err := s.Runs(analysis, graph)
+	if err != nil || len(runs) <= keep {
+		return err
+	}
+	for i := range runs[keep:] {
+		r := &runs[keep+i]
+		var keys [][]byte
+		prefix := runKey(prefixRunValue, r)
+		err = s.db.View(func(txn *badger.Txn) error {
+			opts := badger.DefaultIteratorOptions
+			opts.PrefetchValues = false
+			opts.Prefix = prefix
+			it := txn.NewIterator(opts)
+			defer it.Close()
+			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+				keys = append(keys, it.Item().KeyCopy(nil))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// the run goes first so it is never listed without its values
+		keys = append([][]byte{runKey(prefixRun, r)}, keys...)
+		err = s.write(func(w *writer) error {
+			for _, k := range keys {
+				if err := w.delete(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeTerms returns the lower case terms, sorted and without
+// duplicates, so runs over the same terms can be matched
+func normalizeTerms(terms []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, t := range terms {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if len(t) == 0 || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sameTerms(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
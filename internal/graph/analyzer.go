@@ -0,0 +1,155 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// Analyzer is a suture Service which periodically runs
+	// analyses over the latest window of some graphs
+	Analyzer struct {
+		store  *Store
+		opts   AnalyzerOptions
+		logCtx zerolog.Logger
+
+		done chan struct{}
+		stop chan struct{}
+	}
+
+	// AnalyzerOptions control what is analysed and how often
+	AnalyzerOptions struct {
+		// Graphs to analyse, see Kinds
+		Graphs []string
+		// Analyses to run over each graph, see Analyses
+		Analyses []string
+		// Interval between runs, zero uses one hour
+		Interval time.Duration
+		// Window is how far back each run looks, zero uses one day
+		Window time.Duration
+		// MinWeight and Top limit the graph loaded in memory
+		MinWeight int64
+		Top       int
+		// Keep is the number of runs kept for each graph and
+		// analysis, zero keeps every run
+		Keep int
+		// Terms returns the terms tracked by the stream, may be nil
+		Terms func() []string
+	}
+)
+
+var (
+	analysisLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:      "analysisLatency",
+		Namespace: "vogelnest",
+		Subsystem: "graph",
+		Help:      "Seconds taken to load and analyse a graph",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14),
+	}, []string{"graph"})
+	analysisErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "analysisErrors",
+		Namespace: "vogelnest",
+		Subsystem: "graph",
+	}, []string{"graph"})
+)
+
+func init() {
+	prometheus.MustRegister(analysisLatency, analysisErrors)
+}
+
+// NewAnalyzer running analyses over the graphs of store
+func NewAnalyzer(store *Store, opts AnalyzerOptions) (*Analyzer, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Hour
+	}
+	if opts.Window <= 0 {
+		opts.Window = 24 * time.Hour
+	}
+	for _, name := range opts.Graphs {
+		if KindOf(name) == nil {
+			return nil, errUnknownGraph(name)
+		}
+	}
+	for _, name := range opts.Analyses {
+		if analyses[name] == nil {
+			return nil, errUnknownAnalysis(name)
+		}
+	}
+	return &Analyzer{store: store, opts: opts}, nil
+}
+
+// Serve runs the analyses every interval until Stop is called. Graphs
+// whose latest run is recent enough are skipped, so restarts don't
+// repeat them
+func (a *Analyzer) Serve() {
+	a.stop = make(chan struct{})
+	a.done = make(chan struct{})
+	defer close(a.done)
+	a.logCtx = log.Logger.With().Str("service", "graph-analyzer").Logger()
+
+	ticker := time.NewTicker(a.opts.Interval)
+	defer ticker.Stop()
+	a.runDue()
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+			a.runDue()
+		}
+	}
+}
+
+// runDue analyses the graphs without a run in the last interval,
+// a run which takes a while delays Stop
+func (a *Analyzer) runDue() {
+	for _, graph := range a.opts.Graphs {
+		select {
+		case <-a.stop:
+			return
+		default:
+		}
+		if !a.due(graph) {
+			continue
+		}
+		now := time.Now().UTC()
+		f := Filter{
+			Window:    Window{From: now.Add(-a.opts.Window), To: now},
+			MinWeight: a.opts.MinWeight,
+			Top:       a.opts.Top,
+		}
+		opts := RunOptions{Keep: a.opts.Keep}
+		if a.opts.Terms != nil {
+			opts.Terms = a.opts.Terms()
+		}
+		_, err := a.store.Analyze(graph, a.opts.Analyses, f, opts)
+		analysisLatency.WithLabelValues(graph).Observe(time.Since(now).Seconds())
+		if err != nil {
+			analysisErrors.WithLabelValues(graph).Inc()
+			a.logCtx.Error().Err(err).Str("action", "analyse").Str("graph", graph).Msg("Unable to analyse graph")
+		}
+	}
+}
+
+// due returns true if any analysis of graph didn't run in the last
+// interval (with some slack for the time taken by the previous run)
+func (a *Analyzer) due(graph string) bool {
+	for _, name := range a.opts.Analyses {
+		r, err := a.store.LatestRun(name, graph)
+		if err != nil || time.Since(r.At) > a.opts.Interval*9/10 {
+			return true
+		}
+	}
+	return false
+}
+
+// Stop the service, waiting for the current run
+func (a *Analyzer) Stop() {
+	close(a.stop)
+	<-a.done
+}
+
+func (a *Analyzer) String() string { return "graph-analyzer" }
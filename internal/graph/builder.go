@@ -0,0 +1,204 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/andrebq/vogelnest/internal/tweets"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// Builder is a suture Service which adds the tweets of a stream
+	// to the graphs kept in a store
+	Builder struct {
+		store  *Store
+		stream *tweets.Stream
+		opts   BuilderOptions
+		kinds  []*Kind
+
+		// pending counts, written on every flush
+		pending *counts
+		logCtx  zerolog.Logger
+
+		done chan struct{}
+		stop chan struct{}
+	}
+
+	// BuilderOptions control which graphs are built
+	BuilderOptions struct {
+		// Graphs to build, see Kinds
+		Graphs []string
+		// FlushInterval is the maximum time counts are kept in memory
+		FlushInterval time.Duration
+	}
+)
+
+var (
+	graphTweets = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "tweets",
+		Namespace: "vogelnest",
+		Subsystem: "graph",
+	})
+	graphFlushLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:      "flushLatency",
+		Namespace: "vogelnest",
+		Subsystem: "graph",
+		Help:      "Seconds taken to add the pending counts to the store",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	})
+	graphFlushErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "flushErrors",
+		Namespace: "vogelnest",
+		Subsystem: "graph",
+	})
+	graphDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "dropped",
+		Namespace: "vogelnest",
+		Subsystem: "graph",
+		Help:      "Tweets left out of the graphs because the builder was behind",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(graphTweets, graphFlushLatency, graphFlushErrors, graphDropped)
+}
+
+// NewBuilder adding the tweets of stream to store
+func NewBuilder(store *Store, stream *tweets.Stream, opts BuilderOptions) (*Builder, error) {
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 10 * time.Second
+	}
+	b := &Builder{store: store, stream: stream, opts: opts}
+	for _, name := range opts.Graphs {
+		k := KindOf(name)
+		if k == nil {
+			return nil, errUnknownGraph(name)
+		}
+		b.kinds = append(b.kinds, k)
+	}
+	return b, nil
+}
+
+// Serve adds tweets to the pending counts until Stop is called
+func (b *Builder) Serve() {
+	b.stop = make(chan struct{})
+	b.done = make(chan struct{})
+	defer close(b.done)
+	b.logCtx = log.Logger.With().Str("service", "graph-builder").Logger()
+
+	// graphs are an aggregate, dropping tweets is better than holding
+	// back the other sinks, but the builder keeps track of them
+	sub := b.stream.NewLosslessSink(100)
+	queue := make(chan *schema.Tweet, 1000)
+	receiverDone := make(chan struct{})
+	go b.receive(sub, queue, receiverDone)
+	defer func() {
+		b.stream.RemoveSink(sub)
+		<-receiverDone
+		for len(queue) > 0 {
+			b.Add(<-queue)
+		}
+		b.flush()
+	}()
+
+	ticker := time.NewTicker(b.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			b.flush()
+		case <-receiverDone:
+			b.logCtx.Warn().Str("action", "subscription-closed").Msg("Input stream closed. There won't be any new messages")
+			return
+		case t := <-queue:
+			b.Add(t)
+		}
+	}
+}
+
+// receive sends tweets from sub to queue, they are dropped
+// when the queue is full
+func (b *Builder) receive(sub <-chan *schema.Tweet, queue chan<- *schema.Tweet, done chan<- struct{}) {
+	defer close(done)
+	for {
+		select {
+		case t, open := <-sub:
+			if !open {
+				return
+			}
+			select {
+			case queue <- t:
+			default:
+				graphDropped.Inc()
+			}
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// Add the nodes and edges of t to the pending counts,
+// they are saved on the next flush
+func (b *Builder) Add(t *schema.Tweet) {
+	graphTweets.Inc()
+	if b.pending == nil {
+		b.pending = &counts{
+			nodes:  make(map[nodeKey]int64),
+			edges:  make(map[edgeKey]int64),
+			labels: make(map[string]string),
+		}
+	}
+	bucket := b.bucketOf(t)
+	for _, k := range b.kinds {
+		a := newAdder(k)
+		k.build(t, a)
+		for id := range a.nodes {
+			b.pending.nodes[nodeKey{graph: k.Name, bucket: bucket, id: id}]++
+		}
+		for e := range a.edges {
+			b.pending.edges[edgeKey{graph: k.Name, bucket: bucket, from: e[0], to: e[1], typ: e[2]}]++
+		}
+		for id, l := range a.labels {
+			b.pending.labels[id] = l
+		}
+	}
+}
+
+// bucketOf returns the bucket of the creation time of t,
+// tweets without one use the current time
+func (b *Builder) bucketOf(t *schema.Tweet) time.Time {
+	moment, err := time.Parse(time.RFC3339, t.CreatedAt)
+	if err != nil {
+		moment = time.Now()
+	}
+	return moment.UTC().Truncate(b.store.Bucket())
+}
+
+// flush writes the pending counts, they are discarded when the write
+// fails as part of them might have been saved
+func (b *Builder) flush() {
+	if b.pending == nil {
+		return
+	}
+	start := time.Now()
+	err := b.store.apply(b.pending)
+	graphFlushLatency.Observe(time.Since(start).Seconds())
+	if err != nil {
+		graphFlushErrors.Inc()
+		b.logCtx.Error().Err(err).Str("action", "flush").Int("nodes", len(b.pending.nodes)).Int("edges", len(b.pending.edges)).Msg("Unable to save graph counts")
+	}
+	b.pending = nil
+}
+
+// Stop the service, pending counts are saved
+func (b *Builder) Stop() {
+	close(b.stop)
+	<-b.done
+}
+
+func (b *Builder) String() string { return "graph-builder" }
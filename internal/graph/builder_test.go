@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func hashtagTweet(at time.Time, tags ...string) *schema.Tweet {
+	e := &schema.Entities{}
+	for _, tag := range tags {
+		e.Hashtags = append(e.Hashtags, &schema.Hashtag{Text: tag})
+	}
+	return &schema.Tweet{CreatedAt: at.Format(time.RFC3339), Entities: e}
+}
+
+func TestBuilderAdd(t *testing.T) {
+	s := openStore(t)
+	b, err := NewBuilder(s, nil, BuilderOptions{Graphs: []string{"hashtags"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Add(hashtagTweet(testBucket.Add(time.Minute), "Go", "rust", "go"))
+	// retweets use the hashtags of the original
+	b.Add(&schema.Tweet{CreatedAt: testBucket.Add(time.Hour).Format(time.RFC3339), Retweet: hashtagTweet(testBucket, "go", "zig")})
+	b.flush()
+
+	want := []Node{{ID: "hashtag:go", Label: "go", Weight: 2}, {ID: "hashtag:rust", Label: "rust", Weight: 1}, {ID: "hashtag:zig", Label: "zig", Weight: 1}}
+	if got := allNodes(t, s, "hashtags", Window{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting nodes %v got %v", want, got)
+	}
+	wantEdges := []Edge{
+		{From: "hashtag:go", To: "hashtag:rust", Type: EdgeCoOccurs, Weight: 1},
+		{From: "hashtag:go", To: "hashtag:zig", Type: EdgeCoOccurs, Weight: 1},
+	}
+	if got := allEdges(t, s, "hashtags", Window{}); !reflect.DeepEqual(got, wantEdges) {
+		t.Errorf("expecting edges %v got %v", wantEdges, got)
+	}
+	// tweets are counted in the bucket they were created
+	buckets, err := s.Buckets("hashtags", Window{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets) != 2 || !buckets[0].Equal(testBucket) {
+		t.Errorf("expecting two buckets from %v got %v", testBucket, buckets)
+	}
+
+	if _, err := NewBuilder(s, nil, BuilderOptions{Graphs: []string{"nope"}}); err == nil {
+		t.Error("expecting an error for unknown graphs")
+	}
+}
+
+func TestBuilderDrops(t *testing.T) {
+	b := &Builder{stop: make(chan struct{})}
+	sub := make(chan *schema.Tweet, 5)
+	queue := make(chan *schema.Tweet, 2)
+	done := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		sub <- &schema.Tweet{Id: int64(i)}
+	}
+	close(sub)
+
+	before := testutil.ToFloat64(graphDropped)
+	b.receive(sub, queue, done)
+	select {
+	case <-done:
+	default:
+		t.Fatal("receive should finish once the sink is closed")
+	}
+	if len(queue) != 2 || (<-queue).Id != 0 {
+		t.Errorf("expecting the first tweets in the queue")
+	}
+	if dropped := testutil.ToFloat64(graphDropped) - before; dropped != 3 {
+		t.Errorf("expecting 3 dropped tweets got %v", dropped)
+	}
+}
@@ -0,0 +1,289 @@
+package graph
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+type (
+	// Ranking lists the nodes of a run by decreasing value
+	Ranking struct {
+		Run   Run      `json:"run"`
+		Nodes []Ranked `json:"nodes"`
+	}
+
+	// Ranked is a node and its value in a run, ranks start at 1
+	Ranked struct {
+		Rank  int     `json:"rank"`
+		ID    string  `json:"id"`
+		Label string  `json:"label,omitempty"`
+		Value float64 `json:"value"`
+	}
+
+	// RankingQuery selects the runs returned by Rankings
+	RankingQuery struct {
+		Graph    string
+		Analysis string
+		// Terms keeps only the runs made while the stream
+		// tracked the same terms, empty keeps every run
+		Terms []string
+		// Limit is the number of nodes of each ranking, zero uses 20
+		Limit int
+		// Runs is the number of rankings returned, newest
+		// first, zero returns only the latest
+		Runs int
+	}
+
+	// distItem is a node waiting to be visited by betweenness
+	distItem struct {
+		node int
+		dist float64
+	}
+	distQueue []distItem
+)
+
+const (
+	// AnalysisPageRank ranks nodes by weighted PageRank
+	AnalysisPageRank = "pagerank"
+	// AnalysisInDegree is the weight of the edges arriving at a node
+	AnalysisInDegree = "indegree"
+	// AnalysisOutDegree is the weight of the edges leaving a node
+	AnalysisOutDegree = "outdegree"
+	// AnalysisBetweenness is the betweenness of each node estimated
+	// from the paths starting at a sample of nodes
+	AnalysisBetweenness = "betweenness"
+
+	pageRankDamping = 0.85
+	// pageRankTolerance is the change of each node which
+	// stops iterating before pageRankIterations
+	pageRankTolerance  = 1e-6
+	pageRankIterations = 100
+
+	// betweennessSamples is the number of sources used to
+	// estimate betweenness, smaller graphs use every node
+	betweennessSamples = 256
+)
+
+func init() {
+	registerAnalysis(&analysis{name: AnalysisPageRank, run: pageRank})
+	registerAnalysis(&analysis{name: AnalysisInDegree, run: inDegree})
+	registerAnalysis(&analysis{name: AnalysisOutDegree, run: outDegree})
+	registerAnalysis(&analysis{name: AnalysisBetweenness, run: betweenness})
+}
+
+// pageRank follows edges proportionally to their weight, nodes
+// without outgoing edges jump to any node. The score is the
+// number of iterations
+func pageRank(g *Graph) ([]float64, float64, int) {
+	n := len(g.IDs)
+	if n == 0 {
+		return nil, 0, 0
+	}
+	strength := make([]float64, n)
+	for i, arcs := range g.Out {
+		for _, a := range arcs {
+			strength[i] += a.Weight
+		}
+	}
+	rank := make([]float64, n)
+	next := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+	iterations := 0
+	for iterations < pageRankIterations {
+		iterations++
+		var dangling float64
+		for i, r := range rank {
+			if strength[i] == 0 {
+				dangling += r
+			}
+		}
+		base := (1-pageRankDamping)/float64(n) + pageRankDamping*dangling/float64(n)
+		var diff float64
+		for j, arcs := range g.In {
+			sum := 0.0
+			for _, a := range arcs {
+				sum += rank[a.To] * a.Weight / strength[a.To]
+			}
+			next[j] = base + pageRankDamping*sum
+			diff += math.Abs(next[j] - rank[j])
+		}
+		rank, next = next, rank
+		if diff < pageRankTolerance*float64(n) {
+			break
+		}
+	}
+	return rank, float64(iterations), 0
+}
+
+func inDegree(g *Graph) ([]float64, float64, int) {
+	return strengths(g.In), 0, 0
+}
+
+func outDegree(g *Graph) ([]float64, float64, int) {
+	return strengths(g.Out), 0, 0
+}
+
+func strengths(arcs [][]Arc) []float64 {
+	out := make([]float64, len(arcs))
+	for i, list := range arcs {
+		for _, a := range list {
+			out[i] += a.Weight
+		}
+	}
+	return out
+}
+
+// betweenness uses Brandes' algorithm from a random (but always
+// the same) sample of sources, scaled to the size of the graph. Like
+// ShortestPath the cost of an edge is the inverse of its weight.
+// The score is the number of sources
+func betweenness(g *Graph) ([]float64, float64, int) {
+	n := len(g.IDs)
+	bc := make([]float64, n)
+	sources := rand.New(rand.NewSource(1)).Perm(n)
+	if len(sources) > betweennessSamples {
+		sources = sources[:betweennessSamples]
+	}
+	dist := make([]float64, n)
+	sigma := make([]float64, n)
+	delta := make([]float64, n)
+	preds := make([][]int, n)
+	var order []int
+	var pq distQueue
+	for _, s := range sources {
+		for i := range dist {
+			dist[i] = math.Inf(1)
+			sigma[i] = 0
+			delta[i] = 0
+			preds[i] = preds[i][:0]
+		}
+		order = order[:0]
+		dist[s], sigma[s] = 0, 1
+		pq = append(pq[:0], distItem{node: s})
+		for pq.Len() > 0 {
+			it := heap.Pop(&pq).(distItem)
+			if it.dist > dist[it.node] {
+				continue
+			}
+			order = append(order, it.node)
+			for _, a := range g.Out[it.node] {
+				if a.To == it.node || a.Weight <= 0 {
+					continue
+				}
+				d := it.dist + 1/a.Weight
+				switch {
+				case d < dist[a.To]-1e-12:
+					dist[a.To] = d
+					sigma[a.To] = sigma[it.node]
+					preds[a.To] = append(preds[a.To][:0], it.node)
+					heap.Push(&pq, distItem{node: a.To, dist: d})
+				case math.Abs(d-dist[a.To]) <= 1e-12:
+					sigma[a.To] += sigma[it.node]
+					preds[a.To] = append(preds[a.To], it.node)
+				}
+			}
+		}
+		for i := len(order) - 1; i >= 0; i-- {
+			w := order[i]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				bc[w] += delta[w]
+			}
+		}
+	}
+	if len(sources) > 0 {
+		scale := float64(n) / float64(len(sources))
+		if !g.Directed {
+			// each path was counted from both ends
+			scale /= 2
+		}
+		for i := range bc {
+			bc[i] *= scale
+		}
+	}
+	return bc, float64(len(sources)), 0
+}
+
+// Rankings returns the heaviest nodes of the latest runs of q.Analysis
+// over q.Graph, ErrNotFound if there isn't one
+func (s *Store) Rankings(q RankingQuery) ([]Ranking, error) {
+	if q.Limit <= 0 {
+		q.Limit = 20
+	}
+	if q.Runs <= 0 {
+		q.Runs = 1
+	}
+	if analyses[q.Analysis] == nil {
+		return nil, fmt.Errorf("%w: %v", errBadQuery, errUnknownAnalysis(q.Analysis))
+	}
+	runs, err := s.Runs(q.Analysis, q.Graph)
+	if err != nil {
+		return nil, err
+	}
+	terms := normalizeTerms(q.Terms)
+	out := []Ranking{}
+	for i := range runs {
+		if len(out) == q.Runs {
+			break
+		}
+		if len(terms) > 0 && !sameTerms(terms, runs[i].Terms) {
+			continue
+		}
+		r, err := s.ranking(&runs[i], q.Limit)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: no %v runs over %v", ErrNotFound, q.Analysis, q.Graph)
+	}
+	return out, nil
+}
+
+// ranking returns the limit nodes of r with the largest values
+func (s *Store) ranking(r *Run, limit int) (Ranking, error) {
+	out := Ranking{Run: *r, Nodes: []Ranked{}}
+	err := s.RunValues(r, func(node string, v float64) error {
+		out.Nodes = append(out.Nodes, Ranked{ID: node, Value: v})
+		return nil
+	})
+	if err != nil {
+		return out, err
+	}
+	sort.Slice(out.Nodes, func(i, j int) bool {
+		if out.Nodes[i].Value != out.Nodes[j].Value {
+			return out.Nodes[i].Value > out.Nodes[j].Value
+		}
+		return out.Nodes[i].ID < out.Nodes[j].ID
+	})
+	if len(out.Nodes) > limit {
+		out.Nodes = out.Nodes[:limit]
+	}
+	for i := range out.Nodes {
+		out.Nodes[i].Rank = i + 1
+		out.Nodes[i].Label, err = s.Label(out.Nodes[i].ID)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+func (dq distQueue) Len() int            { return len(dq) }
+func (dq distQueue) Less(i, j int) bool  { return dq[i].dist < dq[j].dist }
+func (dq distQueue) Swap(i, j int)       { dq[i], dq[j] = dq[j], dq[i] }
+func (dq *distQueue) Push(x interface{}) { *dq = append(*dq, x.(distItem)) }
+func (dq *distQueue) Pop() interface{} {
+	old := *dq
+	it := old[len(old)-1]
+	*dq = old[:len(old)-1]
+	return it
+}
@@ -0,0 +1,165 @@
+package graph
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// path returns the undirected path 0 - 1 - ... - n-1, directed paths
+// have the edges in both directions
+func path(directed bool, n int) *Graph {
+	var edges []testEdge
+	for i := 0; i+1 < n; i++ {
+		edges = append(edges, testEdge{i, i + 1, 1})
+		if directed {
+			edges = append(edges, testEdge{i + 1, i, 1})
+		}
+	}
+	return buildGraph(directed, n, edges...)
+}
+
+func expectValues(t *testing.T, got []float64, want ...float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expecting %v got %v", want, got)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("expecting %v got %v", want, got)
+		}
+	}
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func TestPageRank(t *testing.T) {
+	star := buildGraph(false, 5, testEdge{0, 1, 1}, testEdge{0, 2, 1}, testEdge{0, 3, 1}, testEdge{0, 4, 1})
+	// the chain ends in a dangling node
+	chain := buildGraph(true, 3, testEdge{0, 1, 1}, testEdge{1, 2, 1})
+	for _, tc := range []struct {
+		name string
+		g    *Graph
+		// order has the nodes from the highest rank
+		order []int
+	}{
+		{"star", star, []int{0, 1}},
+		{"chain", chain, []int{2, 1, 0}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rank, iterations, _ := pageRank(tc.g)
+			if math.Abs(sum(rank)-1) > 1e-6 {
+				t.Errorf("ranks should add up to 1, got %v", sum(rank))
+			}
+			if iterations >= pageRankIterations {
+				t.Errorf("expecting convergence before %v iterations", pageRankIterations)
+			}
+			for i := 1; i < len(tc.order); i++ {
+				if rank[tc.order[i-1]] <= rank[tc.order[i]] {
+					t.Errorf("expecting node %v above node %v, got %v", tc.order[i-1], tc.order[i], rank)
+				}
+			}
+		})
+	}
+
+	// leaves of the star are the same
+	rank, _, _ := pageRank(star)
+	for i := 2; i < len(rank); i++ {
+		if math.Abs(rank[i]-rank[1]) > 1e-9 {
+			t.Errorf("expecting the same rank for every leaf, got %v", rank)
+		}
+	}
+}
+
+func TestBetweenness(t *testing.T) {
+	bc, sources, _ := betweenness(path(false, 5))
+	if sources != 5 {
+		t.Errorf("small graphs should use every node, got %v sources", sources)
+	}
+	// pairs whose paths go through each node
+	expectValues(t, bc, 0, 3, 4, 3, 0)
+
+	// directed paths are counted once from each end
+	bc, _, _ = betweenness(path(true, 5))
+	expectValues(t, bc, 0, 6, 8, 6, 0)
+
+	bc, _, _ = betweenness(buildGraph(true, 3, testEdge{0, 1, 1}, testEdge{1, 2, 1}))
+	expectValues(t, bc, 0, 1, 0)
+}
+
+func TestBetweennessWeights(t *testing.T) {
+	// 0 - 1 - 3 is heavier, so shorter, than 0 - 2 - 3, while 1 and 2
+	// have two paths with the same cost through 0 and 3
+	g := buildGraph(false, 4, testEdge{0, 1, 4}, testEdge{1, 3, 4}, testEdge{0, 2, 1}, testEdge{2, 3, 1})
+	bc, _, _ := betweenness(g)
+	expectValues(t, bc, 0.5, 1, 0, 0.5)
+}
+
+func TestRankingsTerms(t *testing.T) {
+	s := openStore(t)
+	addGraph(t, s, "hashtags", exportNodes, exportEdges)
+
+	var runs []Run
+	for _, terms := range [][]string{{"Go", "rust"}, {"python"}, {"rust ", "go"}} {
+		r, err := s.Analyze("hashtags", []string{AnalysisInDegree}, Filter{}, RunOptions{Terms: terms})
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, r[0])
+	}
+
+	at := func(rankings []Ranking) []int64 {
+		var out []int64
+		for _, r := range rankings {
+			out = append(out, r.Run.At.UnixNano())
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		name  string
+		terms []string
+		want  []Run
+	}{
+		{"every run", nil, []Run{runs[2], runs[1], runs[0]}},
+		{"same terms", []string{"RUST", "go", "go"}, []Run{runs[2], runs[0]}},
+		{"other terms", []string{"python"}, []Run{runs[1]}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rankings, err := s.Rankings(RankingQuery{Graph: "hashtags", Analysis: AnalysisInDegree, Terms: tc.terms, Runs: 5})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want []int64
+			for _, r := range tc.want {
+				want = append(want, r.At.UnixNano())
+			}
+			if got := at(rankings); !reflect.DeepEqual(got, want) {
+				t.Errorf("expecting runs %v got %v", want, got)
+			}
+		})
+	}
+
+	_, err := s.Rankings(RankingQuery{Graph: "hashtags", Analysis: AnalysisInDegree, Terms: []string{"java"}})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expecting ErrNotFound got %v", err)
+	}
+
+	rankings, err := s.Rankings(RankingQuery{Graph: "hashtags", Analysis: AnalysisInDegree, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, n := range rankings[0].Nodes {
+		ids = append(ids, n.ID)
+	}
+	if want := []string{"hashtag:go", "hashtag:rust"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("expecting %v got %v", want, ids)
+	}
+}
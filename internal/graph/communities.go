@@ -0,0 +1,170 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+type (
+	// Community is a group of nodes more connected among
+	// themselves than with the rest of the graph
+	Community struct {
+		ID   int `json:"id"`
+		Size int `json:"size"`
+		// Weight is the sum of the weight of the members
+		Weight int64 `json:"weight"`
+		// Members are the heaviest nodes of the community
+		Members []Member `json:"members"`
+	}
+
+	// Member of a community
+	Member struct {
+		ID     string `json:"id"`
+		Label  string `json:"label,omitempty"`
+		Weight int64  `json:"weight"`
+	}
+
+	// Communities found by a run, the score of the run is the modularity
+	Communities struct {
+		Run         Run         `json:"run"`
+		Communities []Community `json:"communities"`
+	}
+
+	// CommunityQuery selects the communities returned by Communities
+	CommunityQuery struct {
+		Graph string
+		// Node limits the result to the community of this node
+		Node string
+		// Members is the number of members listed for each community,
+		// zero uses 10
+		Members int
+		// Limit is the number of communities, zero uses 50
+		Limit int
+	}
+)
+
+const (
+	// AnalysisCommunities assigns the nodes of a graph to communities
+	// using the Louvain method, the value of each node is its community
+	AnalysisCommunities = "communities"
+)
+
+func init() {
+	registerAnalysis(&analysis{name: AnalysisCommunities, run: communities})
+}
+
+// communities ignores the direction of edges, directed graphs
+// have the edges between two nodes merged
+func communities(g *Graph) ([]float64, float64, int) {
+	comm, q := louvain(g.undirected())
+	values := make([]float64, len(comm))
+	groups := 0
+	for i, c := range comm {
+		values[i] = float64(c)
+		if c >= groups {
+			groups = c + 1
+		}
+	}
+	return values, q, groups
+}
+
+// DetectCommunities in the part of graph selected by f, see Analyze
+func (s *Store) DetectCommunities(graph string, f Filter, opts RunOptions) (*Run, error) {
+	runs, err := s.Analyze(graph, []string{AnalysisCommunities}, f, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+// Communities returns the largest communities found by the latest run
+// over q.Graph, ErrNotFound if there isn't one or q.Node isn't part of it
+func (s *Store) Communities(q CommunityQuery) (*Communities, error) {
+	if q.Members <= 0 {
+		q.Members = 10
+	}
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	r, err := s.LatestRun(AnalysisCommunities, q.Graph)
+	if err != nil {
+		return nil, err
+	}
+	only := -1
+	if len(q.Node) > 0 {
+		v, ok, err := s.RunValue(r, q.Node)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %v isn't part of the communities of %v", ErrNotFound, q.Node, q.Graph)
+		}
+		only = int(v)
+	}
+	// communities are numbered by size, the first ones are the largest
+	limit := q.Limit
+	if only >= 0 {
+		limit = only + 1
+	}
+	members := make(map[string]int)
+	err = s.RunValues(r, func(node string, v float64) error {
+		if c := int(v); c < limit && (only < 0 || c == only) {
+			members[node] = c
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*Community)
+	err = s.Nodes(q.Graph, Window{From: r.From, To: r.To}, func(n Node) error {
+		c, ok := members[n.ID]
+		if !ok {
+			return nil
+		}
+		com := byID[c]
+		if com == nil {
+			com = &Community{ID: c, Members: []Member{}}
+			byID[c] = com
+		}
+		com.Size++
+		com.Weight += n.Weight
+		com.Members = append(com.Members, Member{ID: n.ID, Label: n.Label, Weight: n.Weight})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := &Communities{Run: *r, Communities: []Community{}}
+	for _, com := range byID {
+		sort.Slice(com.Members, func(i, j int) bool {
+			if com.Members[i].Weight != com.Members[j].Weight {
+				return com.Members[i].Weight > com.Members[j].Weight
+			}
+			return com.Members[i].ID < com.Members[j].ID
+		})
+		if len(com.Members) > q.Members {
+			com.Members = com.Members[:q.Members]
+		}
+		out.Communities = append(out.Communities, *com)
+	}
+	sort.Slice(out.Communities, func(i, j int) bool { return out.Communities[i].ID < out.Communities[j].ID })
+	return out, nil
+}
+
+// communityOf returns the community of each node in the latest run
+// over graph, nil if there isn't one
+func (s *Store) communityOf(graph string) (map[string]int, error) {
+	r, err := s.LatestRun(AnalysisCommunities, graph)
+	if err == ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	out := make(map[string]int)
+	err = s.RunValues(r, func(node string, v float64) error {
+		out[node] = int(v)
+		return nil
+	})
+	return out, err
+}
@@ -0,0 +1,262 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+// Edge types of the entities graph
+const (
+	// EdgePosted goes from a user to their tweet
+	EdgePosted = "POSTED"
+	// EdgeUses goes from a user to the hashtags and symbols of their tweets
+	EdgeUses = "USES"
+	// EdgeContains goes from a tweet to its words, hashtags and symbols
+	EdgeContains = "CONTAINS"
+	// EdgeLinksTo goes from a tweet to the domains of its links
+	EdgeLinksTo = "LINKS_TO"
+)
+
+type (
+	// Bridge is a node reached from two sets of nodes, scores are the
+	// sum over the paths found of the lightest edge in each path
+	Bridge struct {
+		Node   string `json:"node"`
+		Label  string `json:"label"`
+		ScoreA int64  `json:"scoreA"`
+		ScoreB int64  `json:"scoreB"`
+	}
+
+	// BridgeQuery selects the bridges between two sets of nodes
+	BridgeQuery struct {
+		Graph string
+		A, B  []string
+		// Type of the bridges (eg.: word), empty allows every type
+		Type string
+		// Hops is the maximum distance from each set, default 2
+		Hops int
+		// Expand is the maximum number of nodes explored in each hop,
+		// heaviest first, default 1000
+		Expand int
+		// Limit is the maximum number of bridges returned, default 50
+		Limit int
+		Window
+	}
+)
+
+func init() {
+	register(&Kind{Name: "entities", Directed: true, build: buildEntities})
+}
+
+// buildEntities adds the tweet with the text of t, its author,
+// words, hashtags, symbols, mentions and domains. Retweets add weight
+// to the original tweet
+func buildEntities(t *schema.Tweet, a *adder) {
+	c := content(t)
+	if c.Id == 0 {
+		return
+	}
+	tweet := "tweet:" + strconv.FormatInt(c.Id, 10)
+	a.node(tweet, "")
+	var author string
+	if c.User != nil && c.User.Id != 0 {
+		author = userID(c.User.Id)
+		a.node(author, c.User.ScreenName)
+		a.edge(author, tweet, EdgePosted)
+	}
+	tag := func(id, label string) {
+		a.node(id, label)
+		a.edge(tweet, id, EdgeContains)
+		if len(author) > 0 {
+			a.edge(author, id, EdgeUses)
+		}
+	}
+	for _, h := range c.GetEntities().GetHashtags() {
+		tag(hashtagID(h.Text), h.Text)
+	}
+	for _, s := range c.GetEntities().GetSymbols() {
+		tag(symbolID(s.Text), s.Text)
+	}
+	for _, m := range c.GetEntities().GetMentions() {
+		if m.Id == 0 {
+			continue
+		}
+		id := userID(m.Id)
+		a.node(id, m.ScreenName)
+		a.edge(tweet, id, EdgeMentions)
+	}
+	for _, w := range t.Tokens {
+		if strings.IndexByte(w, ' ') >= 0 {
+			// n-grams are left out of the graph
+			continue
+		}
+		id := "word:" + w
+		a.node(id, w)
+		a.edge(tweet, id, EdgeContains)
+	}
+	for _, d := range t.Domains {
+		id := "domain:" + d
+		a.node(id, d)
+		a.edge(tweet, id, EdgeLinksTo)
+	}
+}
+
+// Bridges returns the nodes which can be reached from both sets of q,
+// eg.: the words which connect two hashtags or the users between two
+// communities. Bridges with the highest minimum score come first
+func (s *Store) Bridges(q BridgeQuery) ([]Bridge, error) {
+	if q.Hops <= 0 {
+		q.Hops = 2
+	}
+	if q.Expand <= 0 {
+		q.Expand = 1000
+	}
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	fromA, err := s.reach(q.Graph, q.A, q.Hops, q.Expand, q.Window)
+	if err != nil {
+		return nil, err
+	}
+	fromB, err := s.reach(q.Graph, q.B, q.Hops, q.Expand, q.Window)
+	if err != nil {
+		return nil, err
+	}
+	ends := make(map[string]bool)
+	for _, n := range append(append([]string(nil), q.A...), q.B...) {
+		ends[n] = true
+	}
+	out := []Bridge{}
+	for n, a := range fromA {
+		b, ok := fromB[n]
+		if !ok || ends[n] || (len(q.Type) > 0 && NodeType(n) != q.Type) {
+			continue
+		}
+		out = append(out, Bridge{Node: n, ScoreA: a, ScoreB: b})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		mi, mj := min64(out[i].ScoreA, out[i].ScoreB), min64(out[j].ScoreA, out[j].ScoreB)
+		if mi != mj {
+			return mi > mj
+		}
+		return out[i].Node < out[j].Node
+	})
+	if len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	for i := range out {
+		out[i].Label, err = s.Label(out[i].Node)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// reach returns the nodes up to hops away from start, ignoring the
+// direction of edges, with the sum over the paths found of the lightest
+// edge of each path. Each hop expands at most expand nodes, heaviest first
+func (s *Store) reach(graph string, start []string, hops, expand int, w Window) (map[string]int64, error) {
+	const unbounded = int64(1<<63 - 1)
+	visited := make(map[string]bool)
+	frontier := make(map[string]int64)
+	for _, n := range start {
+		visited[n] = true
+		frontier[n] = unbounded
+	}
+	out := make(map[string]int64)
+	for hop := 0; hop < hops && len(frontier) > 0; hop++ {
+		next := make(map[string]int64)
+		for _, n := range heaviest(frontier, expand) {
+			score := frontier[n]
+			err := s.Neighbors(graph, n, w, func(e Edge) error {
+				other := e.To
+				if other == n {
+					other = e.From
+				}
+				if visited[other] {
+					return nil
+				}
+				next[other] += min64(score, e.Weight)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		for n, score := range next {
+			visited[n] = true
+			out[n] += score
+		}
+		frontier = next
+	}
+	return out, nil
+}
+
+// heaviest returns up to n keys of scores, highest scores first
+func heaviest(scores map[string]int64, n int) []string {
+	out := make([]string, 0, len(scores))
+	for k := range scores {
+		out = append(out, k)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if scores[out[i]] != scores[out[j]] {
+			return scores[out[i]] > scores[out[j]]
+		}
+		return out[i] < out[j]
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// nodeRefs returns the node IDs of refs, #tag, $symbol and @user are
+// accepted for hashtags, symbols and users, other references must be node IDs
+func (s *Store) nodeRefs(refs []string) ([]string, error) {
+	var out []string
+	for _, r := range refs {
+		switch {
+		case strings.HasPrefix(r, "#"):
+			out = append(out, hashtagID(r[1:]))
+		case strings.HasPrefix(r, "$"):
+			out = append(out, symbolID(r[1:]))
+		case strings.HasPrefix(r, "@"):
+			ids, err := s.resolveUser(r)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, ids...)
+		default:
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// nodeRef returns the node ID of a single reference, see nodeRefs,
+// screen names used by more than one user are rejected
+func (s *Store) nodeRef(ref string) (string, error) {
+	ids, err := s.nodeRefs([]string{ref})
+	if err != nil {
+		return "", err
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("%w: %v", ErrNotFound, ref)
+	case 1:
+		return ids[0], nil
+	}
+	return "", fmt.Errorf("%w: %v is ambiguous, use one of %v", errBadQuery, ref, ids)
+}
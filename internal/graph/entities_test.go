@@ -0,0 +1,156 @@
+package graph
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+func TestBuildEntities(t *testing.T) {
+	tweet := &schema.Tweet{
+		Id:   10,
+		User: user(1, "alice"),
+		Entities: &schema.Entities{
+			Hashtags: []*schema.Hashtag{{Text: "Go"}},
+			Symbols:  []*schema.Symbol{{Text: "TWTR"}},
+			Mentions: []*schema.Mention{{Id: 2, ScreenName: "bob"}, {ScreenName: "unknown"}},
+		},
+		Tokens:  []string{"fast", "big data"},
+		Domains: []string{"golang.org"},
+	}
+	nodes, edges := buildKind("entities", tweet)
+	wantNodes := map[string]string{
+		"tweet:10":          "",
+		"user:1":            "alice",
+		"user:2":            "bob",
+		"hashtag:go":        "Go",
+		"symbol:twtr":       "TWTR",
+		"word:fast":         "fast",
+		"domain:golang.org": "golang.org",
+	}
+	if !reflect.DeepEqual(nodes, wantNodes) {
+		t.Errorf("expecting nodes %v got %v", wantNodes, nodes)
+	}
+	wantEdges := [][3]string{
+		{"tweet:10", "domain:golang.org", EdgeLinksTo},
+		{"tweet:10", "hashtag:go", EdgeContains},
+		{"tweet:10", "symbol:twtr", EdgeContains},
+		{"tweet:10", "user:2", EdgeMentions},
+		{"tweet:10", "word:fast", EdgeContains},
+		{"user:1", "hashtag:go", EdgeUses},
+		{"user:1", "symbol:twtr", EdgeUses},
+		{"user:1", "tweet:10", EdgePosted},
+	}
+	if !reflect.DeepEqual(edges, wantEdges) {
+		t.Errorf("expecting edges %v got %v", wantEdges, edges)
+	}
+
+	// retweets add to the original tweet and its author, the enrichment
+	// of the retweet is used
+	retweet := &schema.Tweet{Id: 11, User: user(3, "carol"), Retweet: &schema.Tweet{Id: 10, User: user(1, "alice")}, Tokens: []string{"fast"}}
+	nodes, edges = buildKind("entities", retweet)
+	wantNodes = map[string]string{"tweet:10": "", "user:1": "alice", "word:fast": "fast"}
+	if !reflect.DeepEqual(nodes, wantNodes) {
+		t.Errorf("expecting nodes %v got %v", wantNodes, nodes)
+	}
+	if len(edges) != 2 {
+		t.Errorf("expecting POSTED and CONTAINS got %v", edges)
+	}
+
+	if nodes, _ := buildKind("entities", &schema.Tweet{Text: "no id"}); nodes != nil {
+		t.Errorf("tweets without an id should be skipped, got %v", nodes)
+	}
+}
+
+func TestBridges(t *testing.T) {
+	s := openStore(t)
+	addGraph(t, s, "entities", []Node{{ID: "word:fast", Label: "fast", Weight: 1}}, []Edge{
+		{From: "tweet:1", To: "hashtag:go", Type: EdgeContains, Weight: 3},
+		{From: "tweet:1", To: "word:fast", Type: EdgeContains, Weight: 3},
+		{From: "tweet:1", To: "word:simple", Type: EdgeContains, Weight: 2},
+		{From: "tweet:2", To: "hashtag:rust", Type: EdgeContains, Weight: 2},
+		{From: "tweet:2", To: "word:fast", Type: EdgeContains, Weight: 1},
+		{From: "tweet:2", To: "word:safe", Type: EdgeContains, Weight: 4},
+		{From: "user:1", To: "tweet:1", Type: EdgePosted, Weight: 1},
+		{From: "user:1", To: "tweet:2", Type: EdgePosted, Weight: 1},
+	})
+	query := BridgeQuery{Graph: "entities", A: []string{"hashtag:go"}, B: []string{"hashtag:rust"}}
+	for _, tc := range []struct {
+		name   string
+		change func(q *BridgeQuery)
+		want   []Bridge
+	}{
+		{
+			// ties on the lowest score are sorted by node
+			name: "two hops",
+			want: []Bridge{{Node: "user:1", ScoreA: 1, ScoreB: 1}, {Node: "word:fast", Label: "fast", ScoreA: 3, ScoreB: 1}},
+		},
+		{
+			name:   "by type",
+			change: func(q *BridgeQuery) { q.Type = "word" },
+			want:   []Bridge{{Node: "word:fast", Label: "fast", ScoreA: 3, ScoreB: 1}},
+		},
+		{
+			name:   "limit",
+			change: func(q *BridgeQuery) { q.Limit = 1 },
+			want:   []Bridge{{Node: "user:1", ScoreA: 1, ScoreB: 1}},
+		},
+		{
+			name:   "one hop",
+			change: func(q *BridgeQuery) { q.Hops = 1 },
+			want:   []Bridge{},
+		},
+		{
+			// the ends are never bridges
+			name:   "overlapping sets",
+			change: func(q *BridgeQuery) { q.B = append(q.B, "word:fast") },
+			want:   []Bridge{{Node: "tweet:1", ScoreA: 3, ScoreB: 3}, {Node: "word:simple", ScoreA: 2, ScoreB: 2}, {Node: "user:1", ScoreA: 1, ScoreB: 2}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			q := query
+			q.B = append([]string(nil), query.B...)
+			if tc.change != nil {
+				tc.change(&q)
+			}
+			got, err := s.Bridges(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expecting %v got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNodeRefs(t *testing.T) {
+	s := openStore(t)
+	addGraph(t, s, "users", queryUsers, nil)
+	got, err := s.nodeRefs([]string{"#Go", "$TWTR", "@ALICE", "@nobody", "@bob", "word:fast"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"hashtag:go", "symbol:twtr", "user:1", "user:4", "user:2", "word:fast"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %v got %v", want, got)
+	}
+
+	for _, tc := range []struct {
+		ref string
+		id  string
+		err error
+	}{
+		{ref: "@bob", id: "user:2"},
+		{ref: "user:9", id: "user:9"},
+		{ref: "@alice", err: errBadQuery},
+		{ref: "@nobody", err: ErrNotFound},
+	} {
+		id, err := s.nodeRef(tc.ref)
+		if id != tc.id || !errors.Is(err, tc.err) {
+			t.Errorf("%v: expecting %q (%v) got %q (%v)", tc.ref, tc.id, tc.err, id, err)
+		}
+	}
+}
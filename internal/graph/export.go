@@ -0,0 +1,425 @@
+package graph
+
+import (
+	"archive/zip"
+	"bufio"
+	"container/heap"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Filter selects part of a graph
+	Filter struct {
+		Window
+		// MinWeight skips lighter edges
+		MinWeight int64
+		// Top keeps only the heaviest nodes, zero keeps every node
+		Top int
+	}
+
+	// exporter writes a graph in one format, every node
+	// is written before the first edge. When communities is set
+	// nodes have the community found by the latest run, or -1
+	exporter interface {
+		begin(graph string, directed, communities bool) error
+		node(n Node, community int) error
+		edge(e Edge) error
+		end() error
+	}
+
+	graphmlExporter struct {
+		w        *bufio.Writer
+		directed bool
+	}
+
+	gexfExporter struct {
+		w     *bufio.Writer
+		edges bool
+		id    int
+	}
+
+	dotExporter struct {
+		w  *bufio.Writer
+		op string
+	}
+
+	// neo4jExporter writes a zip with the CSV files
+	// read by neo4j-admin import
+	neo4jExporter struct {
+		zip   *zip.Writer
+		csv   *csv.Writer
+		edges bool
+
+		communities bool
+	}
+
+	// lightest is a min-heap which keeps the heaviest nodes seen,
+	// the lightest of them is on top
+	lightest []weighted
+	weighted struct {
+		id     string
+		weight int64
+	}
+)
+
+var (
+	dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// Formats returns the formats accepted by Export
+func Formats() []string {
+	return []string{"dot", "gexf", "graphml", "neo4j"}
+}
+
+func validFormat(format string) bool {
+	for _, f := range Formats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType returns the media type and file extension of format
+func ContentType(format string) (string, string) {
+	switch format {
+	case "graphml":
+		return "application/graphml+xml", "graphml"
+	case "gexf":
+		return "application/gexf+xml", "gexf"
+	case "dot":
+		return "text/vnd.graphviz", "dot"
+	case "neo4j":
+		return "application/zip", "zip"
+	}
+	return "application/octet-stream", "bin"
+}
+
+// Export writes the nodes and edges of graph selected by f to w.
+//
+// Nodes and edges are written while they are read from the store,
+// only the weight of each node is kept in memory when f.Top is set.
+// Nodes have the community found by the latest run, if there is one
+func (s *Store) Export(w io.Writer, graph, format string, f Filter) error {
+	k := KindOf(graph)
+	if k == nil {
+		return errUnknownGraph(graph)
+	}
+	var ex exporter
+	switch format {
+	case "graphml":
+		ex = &graphmlExporter{w: bufio.NewWriter(w)}
+	case "gexf":
+		ex = &gexfExporter{w: bufio.NewWriter(w)}
+	case "dot":
+		ex = &dotExporter{w: bufio.NewWriter(w)}
+	case "neo4j":
+		ex = &neo4jExporter{zip: zip.NewWriter(w)}
+	default:
+		return fmt.Errorf("unknown format %v, expecting one of %v", format, Formats())
+	}
+	keep, err := s.topNodes(graph, f)
+	if err != nil {
+		return err
+	}
+	communities, err := s.communityOf(graph)
+	if err != nil {
+		return err
+	}
+	err = ex.begin(graph, k.Directed, communities != nil)
+	if err != nil {
+		return err
+	}
+	err = s.Nodes(graph, f.Window, func(n Node) error {
+		if keep != nil && !keep[n.ID] {
+			return nil
+		}
+		c, ok := communities[n.ID]
+		if !ok {
+			c = -1
+		}
+		return ex.node(n, c)
+	})
+	if err != nil {
+		return err
+	}
+	err = s.Edges(graph, f.Window, func(e Edge) error {
+		if e.Weight < f.MinWeight {
+			return nil
+		}
+		if keep != nil && (!keep[e.From] || !keep[e.To]) {
+			return nil
+		}
+		return ex.edge(e)
+	})
+	if err != nil {
+		return err
+	}
+	return ex.end()
+}
+
+// topNodes returns the f.Top heaviest nodes, nil when every node is kept.
+// Ties keep the smallest IDs
+func (s *Store) topNodes(graph string, f Filter) (map[string]bool, error) {
+	if f.Top <= 0 {
+		return nil, nil
+	}
+	var top lightest
+	err := s.Nodes(graph, f.Window, func(n Node) error {
+		w := weighted{n.ID, n.Weight}
+		if len(top) < f.Top {
+			heap.Push(&top, w)
+		} else if top.heavier(w, top[0]) {
+			top[0] = w
+			heap.Fix(&top, 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool, len(top))
+	for _, n := range top {
+		keep[n.id] = true
+	}
+	return keep, nil
+}
+
+// heavier returns true if a comes before b in the export
+func (lightest) heavier(a, b weighted) bool {
+	if a.weight != b.weight {
+		return a.weight > b.weight
+	}
+	return a.id < b.id
+}
+
+func (l lightest) Len() int            { return len(l) }
+func (l lightest) Less(i, j int) bool  { return l.heavier(l[j], l[i]) }
+func (l lightest) Swap(i, j int)       { l[i], l[j] = l[j], l[i] }
+func (l *lightest) Push(x interface{}) { *l = append(*l, x.(weighted)) }
+func (l *lightest) Pop() interface{} {
+	old := *l
+	w := old[len(old)-1]
+	*l = old[:len(old)-1]
+	return w
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func (g *graphmlExporter) begin(graph string, directed, communities bool) error {
+	g.directed = directed
+	edgedefault := "undirected"
+	if directed {
+		edgedefault = "directed"
+	}
+	fmt.Fprintf(g.w, `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+<key id="label" for="node" attr.name="label" attr.type="string"/>
+<key id="type" for="node" attr.name="type" attr.type="string"/>
+<key id="weight" for="node" attr.name="weight" attr.type="long"/>
+<key id="community" for="node" attr.name="community" attr.type="int"><default>-1</default></key>
+<key id="etype" for="edge" attr.name="type" attr.type="string"/>
+<key id="eweight" for="edge" attr.name="weight" attr.type="long"/>
+<graph id="%v" edgedefault="%v">
+`, xmlEscape(graph), edgedefault)
+	return nil
+}
+
+func (g *graphmlExporter) node(n Node, community int) error {
+	var data string
+	if community >= 0 {
+		data = fmt.Sprintf(`<data key="community">%v</data>`, community)
+	}
+	_, err := fmt.Fprintf(g.w, `<node id="%v"><data key="label">%v</data><data key="type">%v</data><data key="weight">%v</data>%v</node>
+`, xmlEscape(n.ID), xmlEscape(n.Label), xmlEscape(NodeType(n.ID)), n.Weight, data)
+	return err
+}
+
+func (g *graphmlExporter) edge(e Edge) error {
+	_, err := fmt.Fprintf(g.w, `<edge source="%v" target="%v"><data key="etype">%v</data><data key="eweight">%v</data></edge>
+`, xmlEscape(e.From), xmlEscape(e.To), xmlEscape(e.Type), e.Weight)
+	return err
+}
+
+func (g *graphmlExporter) end() error {
+	g.w.WriteString("</graph>\n</graphml>\n")
+	return g.w.Flush()
+}
+
+func (g *gexfExporter) begin(graph string, directed, communities bool) error {
+	edgetype := "undirected"
+	if directed {
+		edgetype = "directed"
+	}
+	fmt.Fprintf(g.w, `<?xml version="1.0" encoding="UTF-8"?>
+<gexf xmlns="http://www.gexf.net/1.2draft" version="1.2">
+<meta><description>%v</description></meta>
+<graph mode="static" defaultedgetype="%v">
+<attributes class="node">
+<attribute id="type" title="type" type="string"/>
+<attribute id="weight" title="weight" type="long"/>
+<attribute id="community" title="community" type="integer"/>
+</attributes>
+<attributes class="edge">
+<attribute id="type" title="type" type="string"/>
+</attributes>
+<nodes>
+`, xmlEscape(graph), edgetype)
+	return nil
+}
+
+func (g *gexfExporter) node(n Node, community int) error {
+	label := n.Label
+	if len(label) == 0 {
+		label = n.ID
+	}
+	var attr string
+	if community >= 0 {
+		attr = fmt.Sprintf(`<attvalue for="community" value="%v"/>`, community)
+	}
+	_, err := fmt.Fprintf(g.w, `<node id="%v" label="%v"><attvalues><attvalue for="type" value="%v"/><attvalue for="weight" value="%v"/>%v</attvalues></node>
+`, xmlEscape(n.ID), xmlEscape(label), xmlEscape(NodeType(n.ID)), n.Weight, attr)
+	return err
+}
+
+func (g *gexfExporter) edge(e Edge) error {
+	if !g.edges {
+		g.edges = true
+		g.w.WriteString("</nodes>\n<edges>\n")
+	}
+	g.id++
+	_, err := fmt.Fprintf(g.w, `<edge id="%v" source="%v" target="%v" label="%v" weight="%v"><attvalues><attvalue for="type" value="%v"/></attvalues></edge>
+`, g.id, xmlEscape(e.From), xmlEscape(e.To), xmlEscape(e.Type), e.Weight, xmlEscape(e.Type))
+	return err
+}
+
+func (g *gexfExporter) end() error {
+	if !g.edges {
+		g.w.WriteString("</nodes>\n<edges>\n")
+	}
+	g.w.WriteString("</edges>\n</graph>\n</gexf>\n")
+	return g.w.Flush()
+}
+
+// dotQuote returns s as a DOT quoted string, only quotes and
+// backslashes are escaped so labels keep their UTF-8 text
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+func (d *dotExporter) begin(graph string, directed, communities bool) error {
+	kind := "graph"
+	d.op = "--"
+	if directed {
+		kind = "digraph"
+		d.op = "->"
+	}
+	fmt.Fprintf(d.w, "%v %v {\n", kind, dotQuote(graph))
+	return nil
+}
+
+func (d *dotExporter) node(n Node, community int) error {
+	label := n.Label
+	if len(label) == 0 {
+		label = n.ID
+	}
+	var attr string
+	if community >= 0 {
+		attr = fmt.Sprintf(", community=%v", community)
+	}
+	_, err := fmt.Fprintf(d.w, "\t%v [label=%v, type=%v, weight=%v%v];\n", dotQuote(n.ID), dotQuote(label), dotQuote(NodeType(n.ID)), n.Weight, attr)
+	return err
+}
+
+func (d *dotExporter) edge(e Edge) error {
+	_, err := fmt.Fprintf(d.w, "\t%v %v %v [label=%v, weight=%v];\n", dotQuote(e.From), d.op, dotQuote(e.To), dotQuote(e.Type), e.Weight)
+	return err
+}
+
+func (d *dotExporter) end() error {
+	d.w.WriteString("}\n")
+	return d.w.Flush()
+}
+
+func (n *neo4jExporter) begin(graph string, directed, communities bool) error {
+	n.communities = communities
+	f, err := n.zip.Create("nodes.csv")
+	if err != nil {
+		return err
+	}
+	n.csv = csv.NewWriter(f)
+	header := []string{"id:ID", "label", "weight:long", ":LABEL"}
+	if communities {
+		header = append(header, "community:int")
+	}
+	return n.csv.Write(header)
+}
+
+func (n *neo4jExporter) node(node Node, community int) error {
+	row := []string{node.ID, node.Label, strconv.FormatInt(node.Weight, 10), neo4jLabel(node.ID)}
+	if n.communities {
+		// empty values are not imported
+		c := ""
+		if community >= 0 {
+			c = strconv.Itoa(community)
+		}
+		row = append(row, c)
+	}
+	return n.csv.Write(row)
+}
+
+// startEdges closes nodes.csv and starts relationships.csv
+func (n *neo4jExporter) startEdges() error {
+	n.edges = true
+	n.csv.Flush()
+	if err := n.csv.Error(); err != nil {
+		return err
+	}
+	f, err := n.zip.Create("relationships.csv")
+	if err != nil {
+		return err
+	}
+	n.csv = csv.NewWriter(f)
+	return n.csv.Write([]string{":START_ID", ":END_ID", ":TYPE", "weight:long"})
+}
+
+func (n *neo4jExporter) edge(e Edge) error {
+	if !n.edges {
+		if err := n.startEdges(); err != nil {
+			return err
+		}
+	}
+	return n.csv.Write([]string{e.From, e.To, e.Type, strconv.FormatInt(e.Weight, 10)})
+}
+
+func (n *neo4jExporter) end() error {
+	if !n.edges {
+		if err := n.startEdges(); err != nil {
+			return err
+		}
+	}
+	n.csv.Flush()
+	if err := n.csv.Error(); err != nil {
+		return err
+	}
+	return n.zip.Close()
+}
+
+// neo4jLabel returns the label of the node with the given id,
+// its type with the first letter in upper case (eg.: Hashtag)
+func neo4jLabel(id string) string {
+	t := NodeType(id)
+	if len(t) == 0 {
+		return "Node"
+	}
+	return strings.ToUpper(t[:1]) + t[1:]
+}
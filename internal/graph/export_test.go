@@ -0,0 +1,262 @@
+package graph
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+var (
+	testBucket = time.Date(2020, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	// escaped is a label which needs escaping in every format
+	escaped = `R"ust <&> \ 日本`
+
+	exportNodes = []Node{
+		{ID: "hashtag:go", Label: "Go", Weight: 5},
+		{ID: "hashtag:rust", Label: escaped, Weight: 3},
+		{ID: "hashtag:zig", Label: "zig", Weight: 1},
+		{ID: "hashtag:日本", Label: "日本", Weight: 2},
+	}
+	exportEdges = []Edge{
+		{From: "hashtag:go", To: "hashtag:rust", Type: EdgeCoOccurs, Weight: 3},
+		{From: "hashtag:go", To: "hashtag:日本", Type: EdgeCoOccurs, Weight: 2},
+		{From: "hashtag:rust", To: "hashtag:zig", Type: EdgeCoOccurs, Weight: 1},
+	}
+)
+
+func openStore(t *testing.T) *Store {
+	dir, err := ioutil.TempDir("", "vogelnest-graph")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	s, err := OpenStore(dir, StoreOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// addGraph saves nodes and edges in graph, all in the same bucket
+func addGraph(t *testing.T, s *Store, graph string, nodes []Node, edges []Edge) {
+	c := &counts{
+		nodes:  make(map[nodeKey]int64),
+		edges:  make(map[edgeKey]int64),
+		labels: make(map[string]string),
+	}
+	for _, n := range nodes {
+		c.nodes[nodeKey{graph: graph, bucket: testBucket, id: n.ID}] = n.Weight
+		if len(n.Label) > 0 {
+			c.labels[n.ID] = n.Label
+		}
+	}
+	for _, e := range edges {
+		c.edges[edgeKey{graph: graph, bucket: testBucket, from: e.From, to: e.To, typ: e.Type}] = e.Weight
+	}
+	if err := s.apply(c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type exported struct {
+	// nodes has the id and label of each node
+	nodes [][2]string
+	// edges has the source and target of each edge
+	edges [][2]string
+}
+
+func parseGraphML(t *testing.T, buf []byte) exported {
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID   string `xml:"id,attr"`
+				Data []struct {
+					Key   string `xml:"key,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"data"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(buf, &doc); err != nil {
+		t.Fatalf("invalid graphml: %v\n%s", err, buf)
+	}
+	var out exported
+	for _, n := range doc.Graph.Nodes {
+		var label string
+		for _, d := range n.Data {
+			if d.Key == "label" {
+				label = d.Value
+			}
+		}
+		out.nodes = append(out.nodes, [2]string{n.ID, label})
+	}
+	for _, e := range doc.Graph.Edges {
+		out.edges = append(out.edges, [2]string{e.Source, e.Target})
+	}
+	return out
+}
+
+func parseGEXF(t *testing.T, buf []byte) exported {
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID    string `xml:"id,attr"`
+				Label string `xml:"label,attr"`
+			} `xml:"nodes>node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edges>edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(buf, &doc); err != nil {
+		t.Fatalf("invalid gexf: %v\n%s", err, buf)
+	}
+	var out exported
+	for _, n := range doc.Graph.Nodes {
+		out.nodes = append(out.nodes, [2]string{n.ID, n.Label})
+	}
+	for _, e := range doc.Graph.Edges {
+		out.edges = append(out.edges, [2]string{e.Source, e.Target})
+	}
+	return out
+}
+
+// readZip returns the rows of each CSV file in the neo4j export
+func readZip(t *testing.T, buf []byte) map[string][][]string {
+	zr, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(map[string][][]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := csv.NewReader(r).ReadAll()
+		r.Close()
+		if err != nil {
+			t.Fatalf("%v: %v", f.Name, err)
+		}
+		out[f.Name] = rows
+	}
+	return out
+}
+
+func export(t *testing.T, s *Store, format string, f Filter) []byte {
+	var buf bytes.Buffer
+	if err := s.Export(&buf, "hashtags", format, f); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExportXML(t *testing.T) {
+	s := openStore(t)
+	addGraph(t, s, "hashtags", exportNodes, exportEdges)
+
+	for _, tc := range []struct {
+		name   string
+		filter Filter
+		want   exported
+	}{
+		{
+			name: "all",
+			want: exported{
+				nodes: [][2]string{{"hashtag:go", "Go"}, {"hashtag:rust", escaped}, {"hashtag:zig", "zig"}, {"hashtag:日本", "日本"}},
+				edges: [][2]string{{"hashtag:go", "hashtag:rust"}, {"hashtag:go", "hashtag:日本"}, {"hashtag:rust", "hashtag:zig"}},
+			},
+		},
+		{
+			name:   "top",
+			filter: Filter{Top: 2},
+			want: exported{
+				nodes: [][2]string{{"hashtag:go", "Go"}, {"hashtag:rust", escaped}},
+				edges: [][2]string{{"hashtag:go", "hashtag:rust"}},
+			},
+		},
+		{
+			name:   "min-weight",
+			filter: Filter{MinWeight: 2},
+			want: exported{
+				nodes: [][2]string{{"hashtag:go", "Go"}, {"hashtag:rust", escaped}, {"hashtag:zig", "zig"}, {"hashtag:日本", "日本"}},
+				edges: [][2]string{{"hashtag:go", "hashtag:rust"}, {"hashtag:go", "hashtag:日本"}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseGraphML(t, export(t, s, "graphml", tc.filter))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("graphml: expecting %v got %v", tc.want, got)
+			}
+			got = parseGEXF(t, export(t, s, "gexf", tc.filter))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("gexf: expecting %v got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestExportDOT(t *testing.T) {
+	s := openStore(t)
+	addGraph(t, s, "hashtags", exportNodes, exportEdges)
+
+	got := string(export(t, s, "dot", Filter{Top: 3, MinWeight: 2}))
+	want := `graph "hashtags" {
+	"hashtag:go" [label="Go", type="hashtag", weight=5];
+	"hashtag:rust" [label="R\"ust <&> \\ 日本", type="hashtag", weight=3];
+	"hashtag:日本" [label="日本", type="hashtag", weight=2];
+	"hashtag:go" -- "hashtag:rust" [label="CO_OCCURS", weight=3];
+	"hashtag:go" -- "hashtag:日本" [label="CO_OCCURS", weight=2];
+}
+`
+	if got != want {
+		t.Errorf("expecting\n%v\ngot\n%v", want, got)
+	}
+}
+
+func TestExportNeo4j(t *testing.T) {
+	s := openStore(t)
+	addGraph(t, s, "hashtags", exportNodes, exportEdges)
+
+	files := readZip(t, export(t, s, "neo4j", Filter{Top: 3}))
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"nodes.csv", "relationships.csv"}) {
+		t.Fatalf("expecting nodes.csv and relationships.csv got %v", names)
+	}
+	nodes := [][]string{
+		{"id:ID", "label", "weight:long", ":LABEL"},
+		{"hashtag:go", "Go", "5", "Hashtag"},
+		{"hashtag:rust", escaped, "3", "Hashtag"},
+		{"hashtag:日本", "日本", "2", "Hashtag"},
+	}
+	if !reflect.DeepEqual(files["nodes.csv"], nodes) {
+		t.Errorf("nodes.csv: expecting %v got %v", nodes, files["nodes.csv"])
+	}
+	edges := [][]string{
+		{":START_ID", ":END_ID", ":TYPE", "weight:long"},
+		{"hashtag:go", "hashtag:rust", EdgeCoOccurs, "3"},
+		{"hashtag:go", "hashtag:日本", EdgeCoOccurs, "2"},
+	}
+	if !reflect.DeepEqual(files["relationships.csv"], edges) {
+		t.Errorf("relationships.csv: expecting %v got %v", edges, files["relationships.csv"])
+	}
+}
@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/andrebq/vogelnest/internal/text"
+)
+
+const (
+	// EdgeCoOccurs links hashtags and symbols used in the same tweet
+	EdgeCoOccurs = "CO_OCCURS"
+)
+
+func init() {
+	register(&Kind{Name: "hashtags", build: buildHashtags})
+}
+
+// hashtagID returns the ID of the node of a hashtag, hashtags
+// are normalized so #Go and #go are the same node
+func hashtagID(tag string) string {
+	return "hashtag:" + text.Normalize(tag)
+}
+
+// symbolID returns the ID of the node of a symbol (cashtag),
+// normalized like hashtags
+func symbolID(symbol string) string {
+	return "symbol:" + text.Normalize(symbol)
+}
+
+// buildHashtags links every pair of hashtags and symbols of t, retweets
+// count as a new use of the hashtags of the original tweet
+func buildHashtags(t *schema.Tweet, a *adder) {
+	var ids []string
+	add := func(id, label string) {
+		if _, ok := a.nodes[id]; ok {
+			return
+		}
+		a.node(id, label)
+		ids = append(ids, id)
+	}
+	e := content(t).GetEntities()
+	for _, h := range e.GetHashtags() {
+		add(hashtagID(h.Text), h.Text)
+	}
+	for _, s := range e.GetSymbols() {
+		add(symbolID(s.Text), s.Text)
+	}
+	for i := range ids {
+		for j := i + 1; j < len(ids); j++ {
+			a.edge(ids[i], ids[j], EdgeCoOccurs)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// Handler serves queries over the graphs of a store,
+	// it should be mounted at /graph/
+	Handler struct {
+		store *Store
+		terms func() []string
+	}
+)
+
+const (
+	// maxHops limits how far queries walk from their nodes
+	maxHops = 3
+	// maxLimit limits the number of results of a query
+	maxLimit = 1000
+)
+
+var (
+	errBadQuery = errors.New("invalid query")
+)
+
+// NewHandler serving the graphs of store, terms returns the
+// terms tracked by the stream and may be nil
+func NewHandler(store *Store, terms func() []string) *Handler {
+	return &Handler{store: store, terms: terms}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	name := strings.TrimPrefix(req.URL.Path, "/graph/")
+	// POST runs an analysis instead of reading the latest one
+	if req.Method != "GET" && (req.Method != "POST" || (name != "communities" && name != "rankings")) {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	window, err := parseWindow(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := req.URL.Query()
+	var out interface{}
+	switch name {
+	case "export":
+		h.export(w, q, window)
+		return
+	case "interactions":
+		if len(q.Get("user")) == 0 {
+			http.Error(w, "missing user", http.StatusBadRequest)
+			return
+		}
+		out, err = h.store.Interactions(q.Get("user"), window)
+	case "bridges":
+		out, err = h.bridges(q, window)
+	case "node", "neighborhood", "path", "edges", "subgraph":
+		out, err = h.query(name, q, window)
+	case "communities":
+		out, err = h.communities(req.Method == "POST", q, window)
+	case "rankings":
+		out, err = h.rankings(req.Method == "POST", q, window)
+	case "runs":
+		if len(q.Get("analysis")) == 0 {
+			http.Error(w, "missing analysis", http.StatusBadRequest)
+			return
+		}
+		out, err = h.store.Runs(q.Get("analysis"), q.Get("graph"))
+	default:
+		http.NotFound(w, req)
+		return
+	}
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, errBadQuery) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// export writes ?graph in ?format, see Store.Export. Errors after
+// the first byte can only be reported by closing the connection
+func (h *Handler) export(w http.ResponseWriter, q url.Values, window Window) {
+	f, err := parseFilter(q, window)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	graph, format := q.Get("graph"), q.Get("format")
+	if KindOf(graph) == nil {
+		http.Error(w, fmt.Sprintf("unknown graph, expecting one of %v", Kinds()), http.StatusBadRequest)
+		return
+	}
+	if !validFormat(format) {
+		http.Error(w, fmt.Sprintf("unknown format, expecting one of %v", Formats()), http.StatusBadRequest)
+		return
+	}
+	ctype, ext := ContentType(format)
+	w.Header().Set("content-type", ctype)
+	w.Header().Set("content-disposition", fmt.Sprintf("attachment; filename=%q", graph+"."+ext))
+	err = h.store.Export(w, graph, format, f)
+	if err != nil {
+		log.Error().Err(err).Str("service", "graph-api").Str("action", "export").Str("graph", graph).Msg("Unable to export graph")
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// parseFilter reads ?min-weight and ?top
+func parseFilter(q url.Values, window Window) (Filter, error) {
+	f := Filter{Window: window}
+	var err error
+	if s := q.Get("min-weight"); len(s) > 0 {
+		f.MinWeight, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return f, fmt.Errorf("invalid min-weight: %w", err)
+		}
+	}
+	if s := q.Get("top"); len(s) > 0 {
+		f.Top, err = strconv.Atoi(s)
+		if err != nil {
+			return f, fmt.Errorf("invalid top: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// intParam reads ?name, which must be between min and max,
+// def is returned when it is missing
+func intParam(q url.Values, name string, def, min, max int) (int, error) {
+	s := q.Get(name)
+	if len(s) == 0 {
+		return def, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("%w: %v must be between %v and %v", errBadQuery, name, min, max)
+	}
+	return v, nil
+}
+
+// query runs the queries over one ?graph, nodes are given by ?id (or
+// ?source and ?target for paths) as node IDs, #tag or @user
+func (h *Handler) query(name string, q url.Values, window Window) (interface{}, error) {
+	f, err := parseFilter(q, window)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadQuery, err)
+	}
+	gq := Query{Graph: q.Get("graph"), Window: window, MinWeight: f.MinWeight}
+	if KindOf(gq.Graph) == nil {
+		return nil, fmt.Errorf("%w: unknown graph, expecting one of %v", errBadQuery, Kinds())
+	}
+	gq.Limit, err = intParam(q, "limit", 0, 1, maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	if name == "path" {
+		if len(q.Get("source")) == 0 || len(q.Get("target")) == 0 {
+			return nil, fmt.Errorf("%w: expecting source and target", errBadQuery)
+		}
+		// each end must be a single node
+		source, err := h.store.nodeRef(q.Get("source"))
+		if err != nil {
+			return nil, err
+		}
+		target, err := h.store.nodeRef(q.Get("target"))
+		if err != nil {
+			return nil, err
+		}
+		return h.store.ShortestPath(gq, source, target)
+	}
+	if len(q.Get("id")) == 0 {
+		return nil, fmt.Errorf("%w: missing id", errBadQuery)
+	}
+	if name == "subgraph" {
+		ids, err := h.store.nodeRefs(q["id"])
+		if err != nil {
+			return nil, err
+		}
+		return h.store.Induced(gq, ids)
+	}
+	id, err := h.store.nodeRef(q.Get("id"))
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "node":
+		n, ok, err := h.store.Node(gq.Graph, id, window)
+		if err == nil && !ok {
+			err = fmt.Errorf("%w: %v", ErrNotFound, id)
+		}
+		return Element{ElementData{ID: n.ID, Label: n.Label, Type: NodeType(n.ID), Weight: n.Weight}}, err
+	case "neighborhood":
+		hops, err := intParam(q, "hops", 1, 1, maxHops)
+		if err != nil {
+			return nil, err
+		}
+		return h.store.Neighborhood(gq, id, hops)
+	}
+	return h.store.TopEdges(gq, id)
+}
+
+// communities of ?graph found by the latest run, limited to the one
+// with ?node if given. When run is set the communities are detected
+// first, over the window and ?min-weight and ?top
+func (h *Handler) communities(run bool, q url.Values, window Window) (*Communities, error) {
+	cq := CommunityQuery{Graph: q.Get("graph")}
+	if KindOf(cq.Graph) == nil {
+		return nil, fmt.Errorf("%w: unknown graph, expecting one of %v", errBadQuery, Kinds())
+	}
+	var err error
+	if len(q.Get("node")) > 0 {
+		cq.Node, err = h.store.nodeRef(q.Get("node"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	cq.Members, err = intParam(q, "members", 0, 1, maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	cq.Limit, err = intParam(q, "limit", 0, 1, maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	if run {
+		f, err := parseFilter(q, window)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errBadQuery, err)
+		}
+		_, err = h.store.DetectCommunities(cq.Graph, f, h.runOptions())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h.store.Communities(cq)
+}
+
+// rankings of ?graph by ?analysis (pagerank by default) from the last
+// ?runs runs, made while the stream tracked ?terms if given. When run is
+// set the analysis runs first, like communities
+func (h *Handler) rankings(run bool, q url.Values, window Window) ([]Ranking, error) {
+	rq := RankingQuery{Graph: q.Get("graph"), Analysis: q.Get("analysis")}
+	if KindOf(rq.Graph) == nil {
+		return nil, fmt.Errorf("%w: unknown graph, expecting one of %v", errBadQuery, Kinds())
+	}
+	if len(rq.Analysis) == 0 {
+		rq.Analysis = AnalysisPageRank
+	}
+	for _, t := range q["terms"] {
+		rq.Terms = append(rq.Terms, strings.Split(t, ",")...)
+	}
+	var err error
+	rq.Limit, err = intParam(q, "limit", 0, 1, maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	rq.Runs, err = intParam(q, "runs", 0, 1, maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	if run {
+		if analyses[rq.Analysis] == nil {
+			return nil, fmt.Errorf("%w: %v", errBadQuery, errUnknownAnalysis(rq.Analysis))
+		}
+		f, err := parseFilter(q, window)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errBadQuery, err)
+		}
+		_, err = h.store.Analyze(rq.Graph, []string{rq.Analysis}, f, h.runOptions())
+		if err != nil {
+			return nil, err
+		}
+		// the run just made is always returned
+		rq.Terms = nil
+	}
+	return h.store.Rankings(rq)
+}
+
+// bridges between the nodes in ?a and ?b (which can be repeated)
+// of ?graph, filtered by ?type and limited by ?hops and ?limit
+func (h *Handler) bridges(q url.Values, window Window) ([]Bridge, error) {
+	bq := BridgeQuery{Graph: q.Get("graph"), Type: q.Get("type"), Window: window}
+	if len(bq.Graph) == 0 {
+		bq.Graph = "entities"
+	}
+	if KindOf(bq.Graph) == nil {
+		return nil, fmt.Errorf("%w: unknown graph, expecting one of %v", errBadQuery, Kinds())
+	}
+	var err error
+	bq.A, err = h.store.nodeRefs(q["a"])
+	if err != nil {
+		return nil, err
+	}
+	bq.B, err = h.store.nodeRefs(q["b"])
+	if err != nil {
+		return nil, err
+	}
+	bq.Hops, err = intParam(q, "hops", 0, 1, maxHops)
+	if err != nil {
+		return nil, err
+	}
+	bq.Limit, err = intParam(q, "limit", 0, 1, maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	return h.store.Bridges(bq)
+}
+
+// parseWindow reads the window from ?from and ?to (RFC3339), or from
+// ?since which is relative to now (eg.: 24h)
+func parseWindow(req *http.Request) (Window, error) {
+	var w Window
+	q := req.URL.Query()
+	if s := q.Get("since"); len(s) > 0 {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return w, fmt.Errorf("invalid since: %w", err)
+		}
+		w.From = time.Now().Add(-d)
+	}
+	for _, p := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &w.From}, {"to", &w.To}} {
+		s := q.Get(p.name)
+		if len(s) == 0 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return w, fmt.Errorf("invalid %v: %w", p.name, err)
+		}
+		*p.t = t
+	}
+	return w, nil
+}
+
+// runOptions of the analyses run on demand, they don't remove
+// older runs
+func (h *Handler) runOptions() RunOptions {
+	var opts RunOptions
+	if h.terms != nil {
+		opts.Terms = h.terms()
+	}
+	return opts
+}
@@ -0,0 +1,168 @@
+package graph
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+var (
+	// alice is used by two accounts
+	queryUsers = []Node{
+		{ID: "user:1", Label: "alice", Weight: 3},
+		{ID: "user:2", Label: "bob", Weight: 2},
+		{ID: "user:3", Label: "carol", Weight: 1},
+		{ID: "user:4", Label: "Alice", Weight: 1},
+	}
+	queryInteractions = []Edge{
+		{From: "user:1", To: "user:2", Type: EdgeMentions, Weight: 3},
+		{From: "user:2", To: "user:3", Type: EdgeRepliesTo, Weight: 1},
+	}
+)
+
+func queryHandler(t *testing.T) *Handler {
+	s := openStore(t)
+	addGraph(t, s, "hashtags", exportNodes, exportEdges)
+	addGraph(t, s, "users", queryUsers, queryInteractions)
+	return NewHandler(s, nil)
+}
+
+// get returns the status and decodes the body of a successful query into out
+func get(t *testing.T, h *Handler, url string, out interface{}) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+	if w.Code == http.StatusOK && out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatalf("%v: %v", url, err)
+		}
+	}
+	return w.Code
+}
+
+func nodeIDs(elements []Element) []string {
+	var out []string
+	for _, e := range elements {
+		out = append(out, e.Data.ID)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func edgeEnds(elements []Element) [][2]string {
+	var out [][2]string
+	for _, e := range elements {
+		out = append(out, [2]string{e.Data.Source, e.Data.Target})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i][0]+out[i][1] < out[j][0]+out[j][1] })
+	return out
+}
+
+func TestQueryNode(t *testing.T) {
+	h := queryHandler(t)
+	var n Element
+	if code := get(t, h, "/graph/node?graph=hashtags&id=%23go", &n); code != http.StatusOK {
+		t.Fatalf("expecting 200 got %v", code)
+	}
+	if want := (ElementData{ID: "hashtag:go", Label: "Go", Type: "hashtag", Weight: 5}); n.Data != want {
+		t.Errorf("expecting %v got %v", want, n.Data)
+	}
+	get(t, h, "/graph/node?graph=users&id=%40bob", &n)
+	if n.Data.ID != "user:2" {
+		t.Errorf("expecting user:2 got %v", n.Data)
+	}
+}
+
+func TestQueryNeighborhood(t *testing.T) {
+	h := queryHandler(t)
+	for _, tc := range []struct {
+		url   string
+		nodes []string
+	}{
+		{"/graph/neighborhood?graph=hashtags&id=%23zig", []string{"hashtag:rust", "hashtag:zig"}},
+		{"/graph/neighborhood?graph=hashtags&id=%23zig&hops=2", []string{"hashtag:go", "hashtag:rust", "hashtag:zig"}},
+		{"/graph/neighborhood?graph=hashtags&id=%23zig&hops=3&min-weight=2", []string{"hashtag:zig"}},
+	} {
+		var sg Subgraph
+		if code := get(t, h, tc.url, &sg); code != http.StatusOK {
+			t.Fatalf("%v: expecting 200 got %v", tc.url, code)
+		}
+		if got := nodeIDs(sg.Nodes); !reflect.DeepEqual(got, tc.nodes) {
+			t.Errorf("%v: expecting %v got %v", tc.url, tc.nodes, got)
+		}
+	}
+}
+
+func TestQueryEdges(t *testing.T) {
+	h := queryHandler(t)
+	var sg Subgraph
+	get(t, h, "/graph/edges?graph=hashtags&id=%23go&limit=1", &sg)
+	if got, want := edgeEnds(sg.Edges), [][2]string{{"hashtag:go", "hashtag:rust"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting the heaviest edge %v got %v", want, got)
+	}
+	get(t, h, "/graph/edges?graph=hashtags&id=%23go", &sg)
+	if len(sg.Edges) != 2 {
+		t.Errorf("expecting every edge of #go got %v", edgeEnds(sg.Edges))
+	}
+}
+
+func TestQuerySubgraph(t *testing.T) {
+	h := queryHandler(t)
+	var sg Subgraph
+	get(t, h, "/graph/subgraph?graph=hashtags&id=%23go&id=%23zig&id=hashtag:rust", &sg)
+	if got, want := nodeIDs(sg.Nodes), []string{"hashtag:go", "hashtag:rust", "hashtag:zig"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting nodes %v got %v", want, got)
+	}
+	want := [][2]string{{"hashtag:go", "hashtag:rust"}, {"hashtag:rust", "hashtag:zig"}}
+	if got := edgeEnds(sg.Edges); !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting edges %v got %v", want, got)
+	}
+}
+
+func TestQueryPath(t *testing.T) {
+	h := queryHandler(t)
+	var sg Subgraph
+	if code := get(t, h, "/graph/path?graph=hashtags&source=%23go&target=%23zig", &sg); code != http.StatusOK {
+		t.Fatalf("expecting 200 got %v", code)
+	}
+	if got, want := nodeIDs(sg.Nodes), []string{"hashtag:go", "hashtag:rust", "hashtag:zig"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting nodes %v got %v", want, got)
+	}
+	if want := 1.0/3 + 1; sg.Cost < want-1e-9 || sg.Cost > want+1e-9 {
+		t.Errorf("expecting cost %v got %v", want, sg.Cost)
+	}
+
+	get(t, h, "/graph/path?graph=users&source=%40bob&target=%40carol", &sg)
+	if got, want := nodeIDs(sg.Nodes), []string{"user:2", "user:3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting nodes %v got %v", want, got)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	h := queryHandler(t)
+	for _, tc := range []struct {
+		url  string
+		code int
+	}{
+		{"/graph/node?graph=nope&id=%23go", http.StatusBadRequest},
+		{"/graph/node?graph=hashtags", http.StatusBadRequest},
+		{"/graph/node?graph=hashtags&id=%23java", http.StatusNotFound},
+		{"/graph/node?graph=users&id=%40alice", http.StatusBadRequest},
+		{"/graph/neighborhood?graph=hashtags&id=%23go&hops=4", http.StatusBadRequest},
+		{"/graph/edges?graph=hashtags&id=%23go&limit=0", http.StatusBadRequest},
+		{"/graph/path?graph=hashtags&source=%23go", http.StatusBadRequest},
+		// each end must be one user
+		{"/graph/path?graph=users&source=%40alice&target=%40carol", http.StatusBadRequest},
+		{"/graph/path?graph=users&source=%40bob&target=%40nobody", http.StatusNotFound},
+		{"/graph/path?graph=users&source=%40nobody&target=%40bob", http.StatusNotFound},
+		{"/graph/bridges?graph=nope&a=%23go&b=%23zig", http.StatusBadRequest},
+		{"/graph/unknown", http.StatusNotFound},
+	} {
+		if code := get(t, h, tc.url, nil); code != tc.code {
+			t.Errorf("%v: expecting %v got %v", tc.url, tc.code, code)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Keys start with one of these prefixes, followed by the graph name and
+// a zero byte. Bucketed keys continue with the start of the bucket
+// (unix seconds, big endian) so every key of one bucket is contiguous.
+//
+//	b graph 0 bucket                      -> empty, buckets with data
+//	n graph 0 bucket node                 -> count
+//	e graph 0 bucket from 0 to 0 type     -> count
+//	r graph 0 bucket to 0 from 0 type     -> count, reverse of e
+//	l node                                -> label
+//	i label 0 node                        -> empty, index of labels
+//	a analysis 0 graph 0 at               -> run (JSON)
+//	v analysis 0 graph 0 at node          -> value of node (float64)
+//	m name                                -> settings of the store
+const (
+	prefixBucket  = 'b'
+	prefixNode    = 'n'
+	prefixEdge    = 'e'
+	prefixReverse = 'r'
+	prefixLabel   = 'l'
+	prefixIndex   = 'i'
+	// runs have the time they were computed (unix nanoseconds)
+	// instead of a bucket
+	prefixRun      = 'a'
+	prefixRunValue = 'v'
+	prefixMeta     = 'm'
+
+	sep = 0
+)
+
+// graphPrefix returns the prefix of every key of graph
+func graphPrefix(prefix byte, graph string) []byte {
+	out := make([]byte, 0, len(graph)+2+8)
+	out = append(out, prefix)
+	out = append(out, graph...)
+	return append(out, sep)
+}
+
+// bucketPrefix returns the prefix of every key of graph in bucket
+func bucketPrefix(prefix byte, graph string, bucket time.Time) []byte {
+	out := graphPrefix(prefix, graph)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(bucket.Unix()))
+	return append(out, b[:]...)
+}
+
+// join parts separated by zero bytes
+func join(prefix []byte, parts ...string) []byte {
+	out := append([]byte(nil), prefix...)
+	for i, p := range parts {
+		if i > 0 {
+			out = append(out, sep)
+		}
+		out = append(out, p...)
+	}
+	return out
+}
+
+// split the zero separated parts of key after prefixLen bytes
+func split(key []byte, prefixLen int, n int) ([]string, error) {
+	if len(key) < prefixLen {
+		return nil, fmt.Errorf("key too short")
+	}
+	parts := bytes.SplitN(key[prefixLen:], []byte{sep}, n)
+	if len(parts) != n {
+		return nil, fmt.Errorf("expecting %v parts in key, got %v", n, len(parts))
+	}
+	out := make([]string, n)
+	for i, p := range parts {
+		out[i] = string(p)
+	}
+	return out, nil
+}
+
+func decodeBucket(key []byte, prefixLen int) (time.Time, error) {
+	if len(key) < prefixLen+8 {
+		return time.Time{}, fmt.Errorf("key too short")
+	}
+	return time.Unix(int64(binary.BigEndian.Uint64(key[prefixLen:])), 0).UTC(), nil
+}
+
+func encodeCount(c int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append([]byte(nil), b[:binary.PutVarint(b[:], c)]...)
+}
+
+func decodeCount(v []byte) (int64, error) {
+	c, n := binary.Varint(v)
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid count")
+	}
+	return c, nil
+}
@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+type (
+	// Kind is a graph built from tweets
+	Kind struct {
+		// Name of the graph in the store
+		Name string
+		// Directed graphs keep the order of From and To, undirected
+		// graphs store edges with From < To
+		Directed bool
+		// build adds the nodes and edges of one tweet
+		build func(t *schema.Tweet, a *adder)
+	}
+
+	// adder collects the nodes and edges of one tweet,
+	// each is counted once per tweet
+	adder struct {
+		kind   *Kind
+		nodes  map[string]struct{}
+		edges  map[[3]string]struct{}
+		labels map[string]string
+	}
+)
+
+var (
+	kinds = make(map[string]*Kind)
+)
+
+func register(k *Kind) {
+	kinds[k.Name] = k
+}
+
+// Kinds returns the name of every graph which can be built, sorted
+func Kinds() []string {
+	out := make([]string, 0, len(kinds))
+	for name := range kinds {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// KindOf returns the kind of the graph called name, nil if there isn't one
+func KindOf(name string) *Kind {
+	return kinds[name]
+}
+
+func errUnknownGraph(name string) error {
+	return fmt.Errorf("unknown graph %v, expecting one of %v", name, Kinds())
+}
+
+func newAdder(k *Kind) *adder {
+	return &adder{
+		kind:   k,
+		nodes:  make(map[string]struct{}),
+		edges:  make(map[[3]string]struct{}),
+		labels: make(map[string]string),
+	}
+}
+
+// node adds id, label is kept when not empty
+func (a *adder) node(id, label string) {
+	a.nodes[id] = struct{}{}
+	if len(label) > 0 {
+		a.labels[id] = label
+	}
+}
+
+// edge adds an edge between two nodes which were already added
+func (a *adder) edge(from, to, typ string) {
+	if !a.kind.Directed && to < from {
+		from, to = to, from
+	}
+	a.edges[[3]string{from, to, typ}] = struct{}{}
+}
+
+// content returns the tweet which has the text and entities of t,
+// retweets carry a truncated copy of the original
+func content(t *schema.Tweet) *schema.Tweet {
+	if t.Retweet != nil {
+		return t.Retweet
+	}
+	return t
+}
@@ -0,0 +1,216 @@
+package graph
+
+import (
+	"sort"
+)
+
+const (
+	// louvainMinGain ignores moves that don't improve modularity
+	// by at least this much, it avoids loops caused by rounding
+	louvainMinGain = 1e-12
+	// louvainMaxPasses bounds the local moves of each level
+	louvainMaxPasses = 100
+)
+
+// louvain splits the undirected graph given by adj in communities, it
+// returns the community of each node, numbered from 0 by decreasing size,
+// and the modularity of the partition.
+//
+// Self loops are listed once in adj, every other edge twice. Nodes are
+// visited in order so the same graph always gets the same communities
+func louvain(adj [][]Arc) ([]int, float64) {
+	assign := make([]int, len(adj))
+	for i := range assign {
+		assign[i] = i
+	}
+	level := adj
+	for {
+		comm, moved := louvainMove(level)
+		if !moved {
+			break
+		}
+		n := renumber(comm)
+		for i, c := range assign {
+			assign[i] = comm[c]
+		}
+		if n == len(level) {
+			break
+		}
+		level = aggregate(level, comm, n)
+	}
+	renumber(assign)
+	bySize(assign)
+	return assign, modularity(adj, assign)
+}
+
+// degrees returns the weighted degree of each node, self loops count
+// twice, and their total
+func degrees(adj [][]Arc) ([]float64, float64) {
+	k := make([]float64, len(adj))
+	var total float64
+	for i, arcs := range adj {
+		for _, a := range arcs {
+			k[i] += a.Weight
+			if a.To == i {
+				k[i] += a.Weight
+			}
+		}
+		total += k[i]
+	}
+	return k, total
+}
+
+// louvainMove moves each node to the neighbor community with the best
+// modularity gain until no node moves
+func louvainMove(adj [][]Arc) ([]int, bool) {
+	k, m2 := degrees(adj)
+	comm := make([]int, len(adj))
+	tot := make([]float64, len(adj))
+	for i := range comm {
+		comm[i] = i
+		tot[i] = k[i]
+	}
+	if m2 == 0 {
+		return comm, false
+	}
+	moved := false
+	links := make(map[int]float64)
+	var order []int
+	for pass := 0; pass < louvainMaxPasses; pass++ {
+		changed := false
+		for i, arcs := range adj {
+			for c := range links {
+				delete(links, c)
+			}
+			order = order[:0]
+			for _, a := range arcs {
+				if a.To == i {
+					continue
+				}
+				c := comm[a.To]
+				if _, ok := links[c]; !ok {
+					order = append(order, c)
+				}
+				links[c] += a.Weight
+			}
+			old := comm[i]
+			tot[old] -= k[i]
+			best, bestGain := old, links[old]-tot[old]*k[i]/m2
+			for _, c := range order {
+				gain := links[c] - tot[c]*k[i]/m2
+				if gain > bestGain+louvainMinGain {
+					best, bestGain = c, gain
+				}
+			}
+			tot[best] += k[i]
+			comm[i] = best
+			if best != old {
+				changed, moved = true, true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return comm, moved
+}
+
+// renumber changes comm to use the numbers 0..n-1 in order
+// of first appearance and returns n
+func renumber(comm []int) int {
+	ids := make(map[int]int)
+	for i, c := range comm {
+		id, ok := ids[c]
+		if !ok {
+			id = len(ids)
+			ids[c] = id
+		}
+		comm[i] = id
+	}
+	return len(ids)
+}
+
+// bySize renumbers comm so the largest community is 0, ties keep
+// the order of first appearance
+func bySize(comm []int) {
+	var sizes []int
+	for _, c := range comm {
+		for len(sizes) <= c {
+			sizes = append(sizes, 0)
+		}
+		sizes[c]++
+	}
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return sizes[order[i]] > sizes[order[j]] })
+	rank := make([]int, len(order))
+	for r, c := range order {
+		rank[c] = r
+	}
+	for i, c := range comm {
+		comm[i] = rank[c]
+	}
+}
+
+// aggregate returns the graph where each of the n communities
+// is a node, edges inside a community become a self loop
+func aggregate(adj [][]Arc, comm []int, n int) [][]Arc {
+	weights := make([]map[int]float64, n)
+	for i := range weights {
+		weights[i] = make(map[int]float64)
+	}
+	for i, arcs := range adj {
+		for _, a := range arcs {
+			ci, cj := comm[i], comm[a.To]
+			if ci == cj && a.To != i {
+				// listed in both directions
+				weights[ci][ci] += a.Weight / 2
+			} else {
+				weights[ci][cj] += a.Weight
+			}
+		}
+	}
+	out := make([][]Arc, n)
+	for c, w := range weights {
+		for to, weight := range w {
+			out[c] = append(out[c], Arc{To: to, Weight: weight})
+		}
+		sortArcs(out[c])
+	}
+	return out
+}
+
+// modularity of the partition comm of adj
+func modularity(adj [][]Arc, comm []int) float64 {
+	k, m2 := degrees(adj)
+	if m2 == 0 {
+		return 0
+	}
+	n := 0
+	for _, c := range comm {
+		if c >= n {
+			n = c + 1
+		}
+	}
+	in := make([]float64, n)
+	tot := make([]float64, n)
+	for i, arcs := range adj {
+		tot[comm[i]] += k[i]
+		for _, a := range arcs {
+			if comm[a.To] != comm[i] {
+				continue
+			}
+			in[comm[i]] += a.Weight
+			if a.To == i {
+				in[comm[i]] += a.Weight
+			}
+		}
+	}
+	var q float64
+	for c := range in {
+		q += in[c]/m2 - (tot[c]/m2)*(tot[c]/m2)
+	}
+	return q
+}
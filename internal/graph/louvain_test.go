@@ -0,0 +1,112 @@
+package graph
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type testEdge struct {
+	from, to int
+	weight   float64
+}
+
+// buildGraph returns a graph with n nodes and the given edges,
+// laid out like the graphs returned by Load
+func buildGraph(directed bool, n int, edges ...testEdge) *Graph {
+	g := &Graph{Directed: directed, index: make(map[string]int)}
+	for i := 0; i < n; i++ {
+		id := "node:" + strconv.Itoa(i)
+		g.index[id] = i
+		g.IDs = append(g.IDs, id)
+		g.Weights = append(g.Weights, 1)
+	}
+	g.Out = make([][]Arc, n)
+	g.In = g.Out
+	if directed {
+		g.In = make([][]Arc, n)
+	}
+	for _, e := range edges {
+		g.Out[e.from] = append(g.Out[e.from], Arc{To: e.to, Weight: e.weight})
+		if directed {
+			g.In[e.to] = append(g.In[e.to], Arc{To: e.from, Weight: e.weight})
+		} else if e.from != e.to {
+			g.Out[e.to] = append(g.Out[e.to], Arc{To: e.from, Weight: e.weight})
+		}
+	}
+	for i := range g.Out {
+		sortArcs(g.Out[i])
+		if directed {
+			sortArcs(g.In[i])
+		}
+	}
+	return g
+}
+
+// cliques returns two cliques of size nodes joined by a single edge
+func cliques(size int) *Graph {
+	var edges []testEdge
+	for c := 0; c < 2; c++ {
+		for i := 0; i < size; i++ {
+			for j := i + 1; j < size; j++ {
+				edges = append(edges, testEdge{c*size + i, c*size + j, 1})
+			}
+		}
+	}
+	edges = append(edges, testEdge{size - 1, size, 1})
+	return buildGraph(false, size*2, edges...)
+}
+
+func TestLouvainCliques(t *testing.T) {
+	comm, q := louvain(cliques(4).undirected())
+	want := []int{0, 0, 0, 0, 1, 1, 1, 1}
+	if !reflect.DeepEqual(comm, want) {
+		t.Fatalf("expecting communities %v got %v", want, comm)
+	}
+	// 13 edges, each community has 6 inside and a degree of 13:
+	// 2 * (6/13 - (13/26)^2)
+	if expected := 11.0 / 26; math.Abs(q-expected) > 1e-9 {
+		t.Errorf("expecting modularity %v got %v", expected, q)
+	}
+}
+
+func TestLouvainEmpty(t *testing.T) {
+	comm, q := louvain(buildGraph(false, 3).undirected())
+	if !reflect.DeepEqual(comm, []int{0, 1, 2}) || q != 0 {
+		t.Errorf("expecting every node alone, got %v with modularity %v", comm, q)
+	}
+}
+
+func TestUndirected(t *testing.T) {
+	g := buildGraph(true, 3,
+		testEdge{0, 1, 2},
+		testEdge{1, 0, 3},
+		testEdge{1, 1, 4},
+		testEdge{1, 2, 1},
+	)
+	want := [][]Arc{
+		{{To: 1, Weight: 5}},
+		// the self loop is listed once, like in undirected graphs
+		{{To: 0, Weight: 5}, {To: 1, Weight: 4}, {To: 2, Weight: 1}},
+		{{To: 1, Weight: 1}},
+	}
+	got := g.undirected()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expecting %v got %v", want, got)
+	}
+
+	same := buildGraph(false, 3, testEdge{0, 1, 5}, testEdge{1, 1, 4}, testEdge{1, 2, 1})
+	if !reflect.DeepEqual(same.undirected(), want) {
+		t.Fatalf("expecting the undirected graph %v got %v", want, same.undirected())
+	}
+	k, m2 := degrees(got)
+	if !reflect.DeepEqual(k, []float64{5, 14, 1}) || m2 != 20 {
+		t.Errorf("self loops should count twice, got degrees %v and total %v", k, m2)
+	}
+	values, q, _ := communities(g)
+	svalues, sq, _ := communities(same)
+	if !reflect.DeepEqual(values, svalues) || math.Abs(q-sq) > 1e-12 {
+		t.Errorf("expecting %v (%v) got %v (%v)", svalues, sq, values, q)
+	}
+}
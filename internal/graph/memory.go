@@ -0,0 +1,130 @@
+package graph
+
+import (
+	"sort"
+)
+
+type (
+	// Graph is a graph loaded in memory for analysis, edges of every type
+	// between the same nodes are merged. Nodes are numbered in ID order
+	Graph struct {
+		Name     string
+		Directed bool
+		IDs      []string
+		// Weights of each node
+		Weights []int64
+		// Out has the arcs leaving each node, In the ones arriving.
+		// Undirected graphs have each edge in both directions and
+		// share the lists
+		Out [][]Arc
+		In  [][]Arc
+
+		index map[string]int
+	}
+
+	// Arc is one direction of an edge
+	Arc struct {
+		To     int
+		Weight float64
+	}
+)
+
+// Load reads the part of graph selected by f into memory
+func (s *Store) Load(graph string, f Filter) (*Graph, error) {
+	k := KindOf(graph)
+	if k == nil {
+		return nil, errUnknownGraph(graph)
+	}
+	keep, err := s.topNodes(graph, f)
+	if err != nil {
+		return nil, err
+	}
+	g := &Graph{Name: graph, Directed: k.Directed, index: make(map[string]int)}
+	err = s.Nodes(graph, f.Window, func(n Node) error {
+		if keep != nil && !keep[n.ID] {
+			return nil
+		}
+		g.index[n.ID] = len(g.IDs)
+		g.IDs = append(g.IDs, n.ID)
+		g.Weights = append(g.Weights, n.Weight)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[[2]int]float64)
+	err = s.Edges(graph, f.Window, func(e Edge) error {
+		if e.Weight < f.MinWeight {
+			return nil
+		}
+		from, ok := g.index[e.From]
+		if !ok {
+			return nil
+		}
+		to, ok := g.index[e.To]
+		if !ok {
+			return nil
+		}
+		merged[[2]int{from, to}] += float64(e.Weight)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.Out = make([][]Arc, len(g.IDs))
+	g.In = g.Out
+	if g.Directed {
+		g.In = make([][]Arc, len(g.IDs))
+	}
+	for e, w := range merged {
+		g.Out[e[0]] = append(g.Out[e[0]], Arc{To: e[1], Weight: w})
+		if g.Directed {
+			g.In[e[1]] = append(g.In[e[1]], Arc{To: e[0], Weight: w})
+		} else if e[0] != e[1] {
+			g.Out[e[1]] = append(g.Out[e[1]], Arc{To: e[0], Weight: w})
+		}
+	}
+	// map iteration is random, analyses should not be
+	for i := range g.Out {
+		sortArcs(g.Out[i])
+		if g.Directed {
+			sortArcs(g.In[i])
+		}
+	}
+	return g, nil
+}
+
+// Index returns the number of the node with the given ID
+func (g *Graph) Index(id string) (int, bool) {
+	i, ok := g.index[id]
+	return i, ok
+}
+
+// undirected returns the arcs of each node ignoring their direction,
+// arcs between the same nodes are merged
+func (g *Graph) undirected() [][]Arc {
+	if !g.Directed {
+		return g.Out
+	}
+	out := make([][]Arc, len(g.IDs))
+	for i := range g.IDs {
+		weights := make(map[int]float64)
+		for _, a := range g.Out[i] {
+			weights[a.To] += a.Weight
+		}
+		for _, a := range g.In[i] {
+			if a.To != i {
+				weights[a.To] += a.Weight
+			}
+		}
+		for to, w := range weights {
+			out[i] = append(out[i], Arc{To: to, Weight: w})
+		}
+		sortArcs(out[i])
+	}
+	return out
+}
+
+func sortArcs(arcs []Arc) {
+	sort.Slice(arcs, func(i, j int) bool { return arcs[i].To < arcs[j].To })
+}
@@ -0,0 +1,322 @@
+package graph
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+
+	"github.com/dgraph-io/badger"
+)
+
+type (
+	// Subgraph is a set of nodes and edges, encoded as the elements
+	// accepted by cytoscape (cy.add), edges reference nodes by ID
+	Subgraph struct {
+		Nodes []Element `json:"nodes"`
+		Edges []Element `json:"edges"`
+		// Cost of the path, only set by ShortestPath
+		Cost float64 `json:"cost,omitempty"`
+
+		index map[string]bool
+	}
+
+	// Element is a node or an edge of a subgraph
+	Element struct {
+		Data ElementData `json:"data"`
+	}
+
+	// ElementData has the fields of nodes and edges, source and target
+	// are only set for edges
+	ElementData struct {
+		ID     string `json:"id"`
+		Label  string `json:"label,omitempty"`
+		Type   string `json:"type"`
+		Weight int64  `json:"weight"`
+		Source string `json:"source,omitempty"`
+		Target string `json:"target,omitempty"`
+	}
+
+	// Query selects the edges used to explore a graph
+	Query struct {
+		Graph string
+		Window
+		// MinWeight skips lighter edges
+		MinWeight int64
+		// Limit is the maximum number of nodes (or edges for TopEdges)
+		// in the result, see each query for its default
+		Limit int
+	}
+
+	// pathItem is a node waiting to be visited by ShortestPath
+	pathItem struct {
+		node string
+		cost float64
+	}
+	pathQueue []pathItem
+)
+
+const (
+	// maxPathVisits limits the nodes visited looking for a path
+	maxPathVisits = 10000
+)
+
+// Node returns id with its weight in w, ok is false if
+// it has no count in w
+func (s *Store) Node(graph, id string, w Window) (n Node, ok bool, err error) {
+	n.ID = id
+	err = s.db.View(func(txn *badger.Txn) error {
+		buckets, err := s.buckets(txn, graph, w)
+		if err != nil {
+			return err
+		}
+		for _, b := range buckets {
+			item, err := txn.Get(join(bucketPrefix(prefixNode, graph, b), id))
+			if err == badger.ErrKeyNotFound {
+				continue
+			} else if err != nil {
+				return err
+			}
+			err = item.Value(func(v []byte) error {
+				c, err := decodeCount(v)
+				n.Weight += c
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			ok = true
+		}
+		n.Label, err = label(txn, id)
+		return err
+	})
+	return n, ok, err
+}
+
+// Neighborhood returns the nodes up to hops away from id and the edges
+// used to reach them, heavier edges are followed first. The default
+// limit is 500 nodes
+func (s *Store) Neighborhood(q Query, id string, hops int) (*Subgraph, error) {
+	if q.Limit <= 0 {
+		q.Limit = 500
+	}
+	sg := newSubgraph()
+	err := s.addNode(sg, q, id)
+	if err != nil {
+		return nil, err
+	}
+	frontier := map[string]int64{id: 1<<63 - 1}
+	for hop := 0; hop < hops && len(frontier) > 0; hop++ {
+		next := make(map[string]int64)
+		for _, n := range heaviest(frontier, len(frontier)) {
+			edges, err := s.edgesOf(q, n)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range edges {
+				other := otherEnd(e, n)
+				if !sg.has(other) {
+					if len(sg.Nodes) >= q.Limit {
+						continue
+					}
+					err = s.addNode(sg, q, other)
+					if err != nil {
+						return nil, err
+					}
+					next[other] += e.Weight
+				}
+				sg.addEdge(e)
+			}
+		}
+		frontier = next
+	}
+	return sg, nil
+}
+
+// ShortestPath returns the path from a to b with the lowest cost, the
+// cost of an edge is the inverse of its weight so heavier edges make
+// shorter paths. Directed graphs are followed in both directions
+func (s *Store) ShortestPath(q Query, a, b string) (*Subgraph, error) {
+	dist := map[string]float64{a: 0}
+	prev := make(map[string]Edge)
+	done := make(map[string]bool)
+	queue := &pathQueue{{node: a}}
+	for queue.Len() > 0 && len(done) < maxPathVisits {
+		it := heap.Pop(queue).(pathItem)
+		if done[it.node] {
+			continue
+		}
+		done[it.node] = true
+		if it.node == b {
+			break
+		}
+		edges, err := s.edgesOf(q, it.node)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range edges {
+			other := otherEnd(e, it.node)
+			cost := it.cost + 1/float64(e.Weight)
+			if d, ok := dist[other]; !ok || cost < d {
+				dist[other] = cost
+				prev[other] = e
+				heap.Push(queue, pathItem{node: other, cost: cost})
+			}
+		}
+	}
+	if !done[b] {
+		return nil, fmt.Errorf("%w: no path from %v to %v", ErrNotFound, a, b)
+	}
+	sg := newSubgraph()
+	sg.Cost = dist[b]
+	var path []Edge
+	for n := b; n != a; {
+		e := prev[n]
+		path = append(path, e)
+		n = otherEnd(e, n)
+	}
+	err := s.addNode(sg, q, a)
+	if err != nil {
+		return nil, err
+	}
+	n := a
+	for i := len(path) - 1; i >= 0; i-- {
+		n = otherEnd(path[i], n)
+		err = s.addNode(sg, q, n)
+		if err != nil {
+			return nil, err
+		}
+		sg.addEdge(path[i])
+	}
+	return sg, nil
+}
+
+// TopEdges returns the heaviest edges of id and the nodes at their ends,
+// the default limit is 20 edges
+func (s *Store) TopEdges(q Query, id string) (*Subgraph, error) {
+	if q.Limit <= 0 {
+		q.Limit = 20
+	}
+	edges, err := s.edgesOf(q, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(edges) > q.Limit {
+		edges = edges[:q.Limit]
+	}
+	sg := newSubgraph()
+	err = s.addNode(sg, q, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range edges {
+		err = s.addNode(sg, q, otherEnd(e, id))
+		if err != nil {
+			return nil, err
+		}
+		sg.addEdge(e)
+	}
+	return sg, nil
+}
+
+// Induced returns the nodes in ids and every edge between them
+func (s *Store) Induced(q Query, ids []string) (*Subgraph, error) {
+	sg := newSubgraph()
+	for _, id := range ids {
+		err := s.addNode(sg, q, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range ids {
+		edges, err := s.edgesOf(q, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range edges {
+			if sg.has(otherEnd(e, id)) {
+				sg.addEdge(e)
+			}
+		}
+	}
+	return sg, nil
+}
+
+// edgesOf returns the edges of id with at least q.MinWeight, heaviest first
+func (s *Store) edgesOf(q Query, id string) ([]Edge, error) {
+	var out []Edge
+	err := s.Neighbors(q.Graph, id, q.Window, func(e Edge) error {
+		if e.Weight >= q.MinWeight && e.Weight > 0 {
+			out = append(out, e)
+		}
+		return nil
+	})
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Weight > out[j].Weight })
+	return out, err
+}
+
+func (s *Store) addNode(sg *Subgraph, q Query, id string) error {
+	if sg.has(id) {
+		return nil
+	}
+	n, _, err := s.Node(q.Graph, id, q.Window)
+	if err != nil {
+		return err
+	}
+	sg.mark(id)
+	sg.Nodes = append(sg.Nodes, Element{ElementData{
+		ID:     n.ID,
+		Label:  n.Label,
+		Type:   NodeType(n.ID),
+		Weight: n.Weight,
+	}})
+	return nil
+}
+
+func newSubgraph() *Subgraph {
+	return &Subgraph{Nodes: []Element{}, Edges: []Element{}}
+}
+
+func (sg *Subgraph) addEdge(e Edge) {
+	id := e.From + "|" + e.Type + "|" + e.To
+	if sg.has(id) {
+		return
+	}
+	sg.mark(id)
+	sg.Edges = append(sg.Edges, Element{ElementData{
+		ID:     id,
+		Type:   e.Type,
+		Weight: e.Weight,
+		Source: e.From,
+		Target: e.To,
+	}})
+}
+
+func (sg *Subgraph) has(id string) bool {
+	return sg.index[id]
+}
+
+func (sg *Subgraph) mark(id string) {
+	if sg.index == nil {
+		sg.index = make(map[string]bool)
+	}
+	sg.index[id] = true
+}
+
+// otherEnd returns the end of e which isn't n
+func otherEnd(e Edge, n string) string {
+	if e.From == n {
+		return e.To
+	}
+	return e.From
+}
+
+func (pq pathQueue) Len() int            { return len(pq) }
+func (pq pathQueue) Less(i, j int) bool  { return pq[i].cost < pq[j].cost }
+func (pq pathQueue) Swap(i, j int)       { pq[i], pq[j] = pq[j], pq[i] }
+func (pq *pathQueue) Push(x interface{}) { *pq = append(*pq, x.(pathItem)) }
+func (pq *pathQueue) Pop() interface{} {
+	old := *pq
+	it := old[len(old)-1]
+	*pq = old[:len(old)-1]
+	return it
+}
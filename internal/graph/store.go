@@ -0,0 +1,573 @@
+// Package graph builds graphs from tweets, nodes and edges are counted
+// per time bucket and kept in badger so they survive restarts
+package graph
+
+import (
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// Store keeps the nodes and edges of every graph
+	Store struct {
+		db     *badger.DB
+		bucket time.Duration
+	}
+
+	// StoreOptions control how a store is opened
+	StoreOptions struct {
+		// Bucket is the time span of each count, it is only used when
+		// the store is created, zero uses one hour
+		Bucket time.Duration
+		// ReadOnly opens the store without changing it
+		ReadOnly bool
+	}
+
+	// Node of a graph, the type of the node is the prefix of its ID
+	// (eg.: hashtag:golang), see NodeType
+	Node struct {
+		ID    string
+		Label string
+		// Weight is the number of tweets the node appears in
+		Weight int64
+	}
+
+	// Edge of a graph, edges of undirected graphs have From < To
+	Edge struct {
+		From   string
+		To     string
+		Type   string
+		Weight int64
+	}
+
+	// Window selects the buckets which overlap the time between
+	// From (inclusive) and To (exclusive), zero values are unbounded
+	Window struct {
+		From time.Time
+		To   time.Time
+	}
+
+	// counts are added to the store in a single call
+	counts struct {
+		nodes  map[nodeKey]int64
+		edges  map[edgeKey]int64
+		labels map[string]string
+	}
+
+	nodeKey struct {
+		graph  string
+		bucket time.Time
+		id     string
+	}
+
+	edgeKey struct {
+		graph    string
+		bucket   time.Time
+		from, to string
+		typ      string
+	}
+
+	// cursor is one of the iterators merged by mergeCounts
+	cursor struct {
+		it     *badger.Iterator
+		prefix []byte
+	}
+	cursors []*cursor
+
+	badgerLogger struct {
+		zerolog.Logger
+	}
+)
+
+var (
+	// ErrInvalidName is returned for graph names and node IDs
+	// containing a zero byte
+	ErrInvalidName = errors.New("names cannot contain zero bytes")
+	// ErrNotFound is returned when a query has no result
+	ErrNotFound = errors.New("not found")
+
+	metaBucket = []byte{prefixMeta, 'b', 'u', 'c', 'k', 'e', 't'}
+)
+
+// OpenStore opens (or creates) the store kept in dir
+func OpenStore(dir string, opts StoreOptions) (*Store, error) {
+	bopts := badger.DefaultOptions(dir)
+	bopts.ReadOnly = opts.ReadOnly
+	bopts.Logger = &badgerLogger{log.Logger.With().Str("module", "graph-store").Logger()}
+	db, err := badger.Open(bopts)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{db: db}
+	err = s.loadBucket(opts)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to use %v: %w", dir, err)
+	}
+	return s, nil
+}
+
+// loadBucket reads the bucket size, saving it if the store is new
+func (s *Store) loadBucket(opts StoreOptions) error {
+	found := false
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(metaBucket)
+		if err == badger.ErrKeyNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		found = true
+		return item.Value(func(v []byte) error {
+			c, err := decodeCount(v)
+			s.bucket = time.Duration(c)
+			return err
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if found {
+		if opts.Bucket > 0 && opts.Bucket != s.bucket {
+			log.Warn().Str("module", "graph-store").Dur("bucket", s.bucket).Msg("Ignoring the bucket size, the store was created with a different one")
+		}
+		return nil
+	}
+	if opts.ReadOnly {
+		s.bucket = time.Hour
+		return nil
+	}
+	s.bucket = opts.Bucket
+	if s.bucket <= 0 {
+		s.bucket = time.Hour
+	}
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(metaBucket, encodeCount(int64(s.bucket)))
+	})
+}
+
+// Close the store
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Bucket returns the time span of each count
+func (s *Store) Bucket() time.Duration {
+	return s.bucket
+}
+
+// NodeType returns the type of the node with the given ID
+func NodeType(id string) string {
+	if i := strings.IndexByte(id, ':'); i > 0 {
+		return id[:i]
+	}
+	return ""
+}
+
+// Overlaps returns true if the bucket starting at b, lasting size, is
+// at least partially inside w
+func (w Window) Overlaps(b time.Time, size time.Duration) bool {
+	if !w.From.IsZero() && !b.Add(size).After(w.From) {
+		return false
+	}
+	if !w.To.IsZero() && !b.Before(w.To) {
+		return false
+	}
+	return true
+}
+
+// Buckets returns the start of every bucket of graph inside w, oldest first
+func (s *Store) Buckets(graph string, w Window) ([]time.Time, error) {
+	var out []time.Time
+	err := s.db.View(func(txn *badger.Txn) error {
+		var err error
+		out, err = s.buckets(txn, graph, w)
+		return err
+	})
+	return out, err
+}
+
+func (s *Store) buckets(txn *badger.Txn, graph string, w Window) ([]time.Time, error) {
+	prefix := graphPrefix(prefixBucket, graph)
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	it := txn.NewIterator(opts)
+	defer it.Close()
+	var out []time.Time
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		b, err := decodeBucket(it.Item().Key(), len(prefix))
+		if err != nil {
+			return nil, err
+		}
+		if w.Overlaps(b, s.bucket) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+// Nodes calls fn with every node of graph in w, ordered by ID. The
+// weight of a node is the sum of its counts in each bucket
+func (s *Store) Nodes(graph string, w Window, fn func(Node) error) error {
+	return s.db.View(func(txn *badger.Txn) error {
+		prefixes, err := s.prefixes(txn, prefixNode, graph, w, "")
+		if err != nil {
+			return err
+		}
+		return mergeCounts(txn, prefixes, func(rest []byte, c int64) error {
+			n := Node{ID: string(rest), Weight: c}
+			n.Label, err = label(txn, n.ID)
+			if err != nil {
+				return err
+			}
+			return fn(n)
+		})
+	})
+}
+
+// Edges calls fn with every edge of graph in w, ordered by From, To
+// and Type. The weight of an edge is the sum of its counts in each bucket
+func (s *Store) Edges(graph string, w Window, fn func(Edge) error) error {
+	return s.db.View(func(txn *badger.Txn) error {
+		prefixes, err := s.prefixes(txn, prefixEdge, graph, w, "")
+		if err != nil {
+			return err
+		}
+		return mergeCounts(txn, prefixes, func(rest []byte, c int64) error {
+			parts, err := split(rest, 0, 3)
+			if err != nil {
+				return err
+			}
+			return fn(Edge{From: parts[0], To: parts[1], Type: parts[2], Weight: c})
+		})
+	})
+}
+
+// Neighbors calls fn with every edge of graph in w which starts at node,
+// followed by the ones which end at it
+func (s *Store) Neighbors(graph, node string, w Window, fn func(Edge) error) error {
+	if strings.IndexByte(node, sep) >= 0 {
+		return ErrInvalidName
+	}
+	return s.db.View(func(txn *badger.Txn) error {
+		out, err := s.prefixes(txn, prefixEdge, graph, w, node)
+		if err != nil {
+			return err
+		}
+		// keys are trimmed up to node, leaving the other end and the type
+		err = mergeCounts(txn, out, func(rest []byte, c int64) error {
+			parts, err := split(rest, 0, 2)
+			if err != nil {
+				return err
+			}
+			return fn(Edge{From: node, To: parts[0], Type: parts[1], Weight: c})
+		})
+		if err != nil {
+			return err
+		}
+		in, err := s.prefixes(txn, prefixReverse, graph, w, node)
+		if err != nil {
+			return err
+		}
+		return mergeCounts(txn, in, func(rest []byte, c int64) error {
+			parts, err := split(rest, 0, 2)
+			if err != nil {
+				return err
+			}
+			if parts[0] == node {
+				// self loops are already listed as outgoing
+				return nil
+			}
+			return fn(Edge{From: parts[0], To: node, Type: parts[1], Weight: c})
+		})
+	})
+}
+
+// Label returns the label of node, empty if it doesn't have one
+func (s *Store) Label(node string) (string, error) {
+	var out string
+	err := s.db.View(func(txn *badger.Txn) error {
+		var err error
+		out, err = label(txn, node)
+		return err
+	})
+	return out, err
+}
+
+func label(txn *badger.Txn, node string) (string, error) {
+	item, err := txn.Get(append([]byte{prefixLabel}, node...))
+	if err == badger.ErrKeyNotFound {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	v, err := item.ValueCopy(nil)
+	return string(v), err
+}
+
+// Find returns the IDs of the nodes which had the given label,
+// ignoring case
+func (s *Store) Find(l string) ([]string, error) {
+	prefix := labelIndex(l, "")
+	var out []string
+	err := s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			out = append(out, string(it.Item().Key()[len(prefix):]))
+		}
+		return nil
+	})
+	return out, err
+}
+
+func labelIndex(l, node string) []byte {
+	return join([]byte{prefixIndex}, strings.ToLower(l), node)
+}
+
+// prefixes returns the prefix of the keys of each bucket in w,
+// node limits them to the keys starting with it
+func (s *Store) prefixes(txn *badger.Txn, prefix byte, graph string, w Window, node string) ([][]byte, error) {
+	if strings.IndexByte(graph, sep) >= 0 {
+		return nil, ErrInvalidName
+	}
+	buckets, err := s.buckets(txn, graph, w)
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]byte, 0, len(buckets))
+	for _, b := range buckets {
+		p := bucketPrefix(prefix, graph, b)
+		if len(node) > 0 {
+			p = append(append(p, node...), sep)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// writer splits large updates in many transactions, if one of
+// them fails the ones already committed are kept
+type writer struct {
+	db  *badger.DB
+	txn *badger.Txn
+}
+
+func (w *writer) set(key, value []byte) error {
+	err := w.txn.Set(key, value)
+	if err == badger.ErrTxnTooBig {
+		err = w.next()
+		if err == nil {
+			err = w.txn.Set(key, value)
+		}
+	}
+	return err
+}
+
+func (w *writer) delete(key []byte) error {
+	err := w.txn.Delete(key)
+	if err == badger.ErrTxnTooBig {
+		err = w.next()
+		if err == nil {
+			err = w.txn.Delete(key)
+		}
+	}
+	return err
+}
+
+func (w *writer) next() error {
+	err := w.txn.Commit()
+	if err != nil {
+		return err
+	}
+	w.txn = w.db.NewTransaction(true)
+	return nil
+}
+
+// write calls fn with a writer and commits what it wrote
+func (s *Store) write(fn func(w *writer) error) error {
+	w := &writer{db: s.db, txn: s.db.NewTransaction(true)}
+	defer func() { w.txn.Discard() }()
+	err := fn(w)
+	if err != nil {
+		return err
+	}
+	return w.txn.Commit()
+}
+
+// apply adds c to the counts kept in the store. Large updates are split
+// in many transactions, if one of them fails the others are kept
+func (s *Store) apply(c *counts) error {
+	return s.write(func(w *writer) error {
+		return applyCounts(w, c)
+	})
+}
+
+func applyCounts(w *writer, c *counts) error {
+	set := w.set
+	add := func(key []byte, delta int64) error {
+		var old int64
+		item, err := w.txn.Get(key)
+		if err == nil {
+			err = item.Value(func(v []byte) error {
+				old, err = decodeCount(v)
+				return err
+			})
+		}
+		if err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		return set(key, encodeCount(old+delta))
+	}
+	buckets := make(map[string]struct{})
+	for k, v := range c.nodes {
+		if !validNames(k.graph, k.id) {
+			continue
+		}
+		buckets[string(bucketPrefix(prefixBucket, k.graph, k.bucket))] = struct{}{}
+		err := add(join(bucketPrefix(prefixNode, k.graph, k.bucket), k.id), v)
+		if err != nil {
+			return err
+		}
+	}
+	for k, v := range c.edges {
+		if !validNames(k.graph, k.from, k.to, k.typ) {
+			continue
+		}
+		buckets[string(bucketPrefix(prefixBucket, k.graph, k.bucket))] = struct{}{}
+		err := add(join(bucketPrefix(prefixEdge, k.graph, k.bucket), k.from, k.to, k.typ), v)
+		if err != nil {
+			return err
+		}
+		err = add(join(bucketPrefix(prefixReverse, k.graph, k.bucket), k.to, k.from, k.typ), v)
+		if err != nil {
+			return err
+		}
+	}
+	for b := range buckets {
+		err := set([]byte(b), nil)
+		if err != nil {
+			return err
+		}
+	}
+	for node, l := range c.labels {
+		if !validNames(node) {
+			continue
+		}
+		err := set(append([]byte{prefixLabel}, node...), []byte(l))
+		if err != nil {
+			return err
+		}
+		if strings.IndexByte(l, sep) >= 0 {
+			continue
+		}
+		err = set(labelIndex(l, node), nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validNames(names ...string) bool {
+	for _, n := range names {
+		if len(n) == 0 || strings.IndexByte(n, sep) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeCounts iterates the keys starting with each prefix at once, fn is
+// called in key order (without the prefix) with the sum of the counts of
+// every key which is equal after its prefix.
+func mergeCounts(txn *badger.Txn, prefixes [][]byte, fn func(rest []byte, count int64) error) error {
+	var cs cursors
+	defer func() {
+		for _, c := range cs {
+			c.it.Close()
+		}
+	}()
+	for _, p := range prefixes {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = p
+		c := &cursor{it: txn.NewIterator(opts), prefix: p}
+		c.it.Seek(p)
+		if !c.valid() {
+			c.it.Close()
+			continue
+		}
+		cs = append(cs, c)
+	}
+	heap.Init(&cs)
+	for len(cs) > 0 {
+		rest := append([]byte(nil), cs[0].rest()...)
+		var sum int64
+		for len(cs) > 0 && bytes.Equal(cs[0].rest(), rest) {
+			c := cs[0]
+			err := c.it.Item().Value(func(v []byte) error {
+				n, err := decodeCount(v)
+				sum += n
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			c.it.Next()
+			if c.valid() {
+				heap.Fix(&cs, 0)
+			} else {
+				c.it.Close()
+				heap.Pop(&cs)
+			}
+		}
+		err := fn(rest, sum)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cursor) valid() bool {
+	return c.it.ValidForPrefix(c.prefix)
+}
+
+func (c *cursor) rest() []byte {
+	return c.it.Item().Key()[len(c.prefix):]
+}
+
+func (cs cursors) Len() int            { return len(cs) }
+func (cs cursors) Less(i, j int) bool  { return bytes.Compare(cs[i].rest(), cs[j].rest()) < 0 }
+func (cs cursors) Swap(i, j int)       { cs[i], cs[j] = cs[j], cs[i] }
+func (cs *cursors) Push(x interface{}) { *cs = append(*cs, x.(*cursor)) }
+func (cs *cursors) Pop() interface{} {
+	old := *cs
+	c := old[len(old)-1]
+	*cs = old[:len(old)-1]
+	return c
+}
+
+func (bl *badgerLogger) Errorf(fmt string, args ...interface{}) {
+	bl.Logger.Error().Msgf(fmt, args...)
+}
+func (bl *badgerLogger) Warningf(fmt string, args ...interface{}) {
+	bl.Logger.Warn().Msgf(fmt, args...)
+}
+func (bl *badgerLogger) Infof(fmt string, args ...interface{}) {
+	bl.Logger.Info().Msgf(fmt, args...)
+}
+func (bl *badgerLogger) Debugf(fmt string, args ...interface{}) {
+	bl.Logger.Debug().Msgf(fmt, args...)
+}
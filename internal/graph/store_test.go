@@ -0,0 +1,224 @@
+package graph
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger"
+)
+
+// countsAt returns counts for graph with every node and edge in bucket
+func countsAt(graph string, bucket time.Time, nodes []Node, edges []Edge) *counts {
+	c := &counts{
+		nodes:  make(map[nodeKey]int64),
+		edges:  make(map[edgeKey]int64),
+		labels: make(map[string]string),
+	}
+	for _, n := range nodes {
+		c.nodes[nodeKey{graph: graph, bucket: bucket, id: n.ID}] = n.Weight
+		if len(n.Label) > 0 {
+			c.labels[n.ID] = n.Label
+		}
+	}
+	for _, e := range edges {
+		c.edges[edgeKey{graph: graph, bucket: bucket, from: e.From, to: e.To, typ: e.Type}] = e.Weight
+	}
+	return c
+}
+
+func apply(t *testing.T, s *Store, c *counts) {
+	t.Helper()
+	if err := s.apply(c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func allNodes(t *testing.T, s *Store, graph string, w Window) []Node {
+	t.Helper()
+	var out []Node
+	err := s.Nodes(graph, w, func(n Node) error {
+		out = append(out, n)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func allEdges(t *testing.T, s *Store, graph string, w Window) []Edge {
+	t.Helper()
+	var out []Edge
+	err := s.Edges(graph, w, func(e Edge) error {
+		out = append(out, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestStoreBucket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vogelnest-graph")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, tc := range []struct {
+		name string
+		opts StoreOptions
+	}{
+		{"created", StoreOptions{Bucket: 10 * time.Minute}},
+		{"reopened with another size", StoreOptions{Bucket: time.Hour}},
+		{"read only", StoreOptions{ReadOnly: true}},
+	} {
+		s, err := OpenStore(dir, tc.opts)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.name, err)
+		}
+		if s.Bucket() != 10*time.Minute {
+			t.Errorf("%v: expecting the bucket used on creation got %v", tc.name, s.Bucket())
+		}
+		s.Close()
+	}
+}
+
+func TestApplyCounts(t *testing.T) {
+	s := openStore(t)
+	nodes := []Node{{ID: "hashtag:go", Label: "Go", Weight: 2}, {ID: "hashtag:rust", Weight: 1}}
+	edges := []Edge{{From: "hashtag:go", To: "hashtag:rust", Type: EdgeCoOccurs, Weight: 1}}
+	apply(t, s, countsAt("hashtags", testBucket, nodes, edges))
+	// counts are added, invalid names are skipped
+	c := countsAt("hashtags", testBucket, nodes, edges)
+	c.nodes[nodeKey{graph: "hashtags", bucket: testBucket, id: "bad\x00id"}] = 1
+	c.edges[edgeKey{graph: "hashtags", bucket: testBucket, from: "hashtag:go", to: "", typ: EdgeCoOccurs}] = 1
+	c.labels["hashtag:go"] = "GO"
+	apply(t, s, c)
+
+	want := []Node{{ID: "hashtag:go", Label: "GO", Weight: 4}, {ID: "hashtag:rust", Weight: 2}}
+	if got := allNodes(t, s, "hashtags", Window{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting nodes %v got %v", want, got)
+	}
+	wantEdges := []Edge{{From: "hashtag:go", To: "hashtag:rust", Type: EdgeCoOccurs, Weight: 2}}
+	if got := allEdges(t, s, "hashtags", Window{}); !reflect.DeepEqual(got, wantEdges) {
+		t.Errorf("expecting edges %v got %v", wantEdges, got)
+	}
+	ids, err := s.Find("go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"hashtag:go"}) {
+		t.Errorf("expecting the label index to find hashtag:go, got %v", ids)
+	}
+	if got := allNodes(t, s, "users", Window{}); len(got) != 0 {
+		t.Errorf("graphs should not share nodes, got %v", got)
+	}
+}
+
+func TestBucketRollup(t *testing.T) {
+	s := openStore(t)
+	for i := 0; i < 3; i++ {
+		bucket := testBucket.Add(time.Duration(i) * time.Hour)
+		nodes := []Node{{ID: "hashtag:go", Weight: int64(i + 1)}}
+		if i > 0 {
+			nodes = append(nodes, Node{ID: "hashtag:rust", Weight: 10})
+		}
+		apply(t, s, countsAt("hashtags", bucket, nodes, nil))
+	}
+	for _, tc := range []struct {
+		name    string
+		w       Window
+		buckets int
+		want    []Node
+	}{
+		{"every bucket", Window{}, 3, []Node{{ID: "hashtag:go", Weight: 6}, {ID: "hashtag:rust", Weight: 20}}},
+		{"from", Window{From: testBucket.Add(90 * time.Minute)}, 2, []Node{{ID: "hashtag:go", Weight: 5}, {ID: "hashtag:rust", Weight: 20}}},
+		{"to is exclusive", Window{To: testBucket.Add(time.Hour)}, 1, []Node{{ID: "hashtag:go", Weight: 1}}},
+		{"inside a bucket", Window{From: testBucket.Add(70 * time.Minute), To: testBucket.Add(80 * time.Minute)}, 1, []Node{{ID: "hashtag:go", Weight: 2}, {ID: "hashtag:rust", Weight: 10}}},
+		{"empty", Window{From: testBucket.Add(5 * time.Hour)}, 0, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buckets, err := s.Buckets("hashtags", tc.w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(buckets) != tc.buckets {
+				t.Errorf("expecting %v buckets got %v", tc.buckets, buckets)
+			}
+			if got := allNodes(t, s, "hashtags", tc.w); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expecting %v got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMergeCounts(t *testing.T) {
+	s := openStore(t)
+	err := s.db.Update(func(txn *badger.Txn) error {
+		for key, c := range map[string]int64{"a/x": 1, "a/y": 2, "b/x": 3, "b/xx": 5, "c/z": 4, "d/x": 100} {
+			if err := txn.Set([]byte(key), encodeCount(c)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type merged struct {
+		rest  string
+		count int64
+	}
+	var got []merged
+	err = s.db.View(func(txn *badger.Txn) error {
+		prefixes := [][]byte{[]byte("a/"), []byte("b/"), []byte("c/"), []byte("empty/")}
+		return mergeCounts(txn, prefixes, func(rest []byte, c int64) error {
+			got = append(got, merged{string(rest), c})
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []merged{{"x", 4}, {"xx", 5}, {"y", 2}, {"z", 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %v got %v", want, got)
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	s := openStore(t)
+	edges := []Edge{
+		{From: "user:1", To: "user:2", Type: EdgeMentions, Weight: 3},
+		{From: "user:1", To: "user:1", Type: EdgeMentions, Weight: 1},
+		{From: "user:3", To: "user:1", Type: EdgeRetweets, Weight: 2},
+		{From: "user:2", To: "user:3", Type: EdgeQuotes, Weight: 1},
+	}
+	apply(t, s, countsAt("users", testBucket, nil, edges))
+	apply(t, s, countsAt("users", testBucket.Add(time.Hour), nil, edges[:1]))
+
+	var got []Edge
+	err := s.Neighbors("users", "user:1", Window{}, func(e Edge) error {
+		got = append(got, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// outgoing edges first, the self loop is listed once
+	want := []Edge{
+		{From: "user:1", To: "user:1", Type: EdgeMentions, Weight: 1},
+		{From: "user:1", To: "user:2", Type: EdgeMentions, Weight: 6},
+		{From: "user:3", To: "user:1", Type: EdgeRetweets, Weight: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %v got %v", want, got)
+	}
+	if err := s.Neighbors("users", "bad\x00id", Window{}, nil); err != ErrInvalidName {
+		t.Errorf("expecting ErrInvalidName got %v", err)
+	}
+}
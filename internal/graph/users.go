@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+// Edge types of the users graph, edges go from the author of
+// the tweet to the other user
+const (
+	EdgeMentions  = "MENTIONS"
+	EdgeRepliesTo = "REPLIES_TO"
+	EdgeRetweets  = "RETWEETS"
+	EdgeQuotes    = "QUOTES"
+)
+
+type (
+	// Interaction summarizes the edges of one type between
+	// a user and another one
+	Interaction struct {
+		User  string `json:"user"`
+		Label string `json:"label"`
+		Type  string `json:"type"`
+		// Direction is out when the user started the interaction
+		Direction string `json:"direction"`
+		Weight    int64  `json:"weight"`
+	}
+)
+
+func init() {
+	register(&Kind{Name: "users", Directed: true, build: buildUsers})
+}
+
+func userID(id int64) string {
+	return "user:" + strconv.FormatInt(id, 10)
+}
+
+// buildUsers links the author of t to the users it interacts with.
+// Retweets only link to the original author, the mentions of a retweet
+// belong to the original tweet
+func buildUsers(t *schema.Tweet, a *adder) {
+	if t.User == nil || t.User.Id == 0 {
+		return
+	}
+	author := userID(t.User.Id)
+	a.node(author, t.User.ScreenName)
+	link := func(id int64, screenName, typ string) {
+		if id == 0 || id == t.User.Id {
+			return
+		}
+		other := userID(id)
+		a.node(other, screenName)
+		a.edge(author, other, typ)
+	}
+	if t.Retweet != nil {
+		link(t.Retweet.GetUser().GetId(), t.Retweet.GetUser().GetScreenName(), EdgeRetweets)
+		return
+	}
+	if t.QuotedStatus != nil {
+		link(t.QuotedStatus.GetUser().GetId(), t.QuotedStatus.GetUser().GetScreenName(), EdgeQuotes)
+	}
+	link(t.InReplyToUserId, t.InReplyToScreenName, EdgeRepliesTo)
+	for _, m := range t.GetEntities().GetMentions() {
+		if m.Indices != nil && t.DisplayTextRange != nil && m.Indices.End <= t.DisplayTextRange.Start {
+			// added by twitter to the start of a reply
+			continue
+		}
+		link(m.Id, m.ScreenName, EdgeMentions)
+	}
+}
+
+// Interactions returns the interactions of user in w, heaviest first.
+// user is a node ID (user:<id>) or a screen name
+func (s *Store) Interactions(user string, w Window) ([]Interaction, error) {
+	ids, err := s.resolveUser(user)
+	if err != nil {
+		return nil, err
+	}
+	out := []Interaction{}
+	for _, id := range ids {
+		err = s.Neighbors("users", id, w, func(e Edge) error {
+			it := Interaction{User: e.To, Type: e.Type, Direction: "out", Weight: e.Weight}
+			if e.To == id {
+				it.User, it.Direction = e.From, "in"
+			}
+			out = append(out, it)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i := range out {
+		out[i].Label, err = s.Label(out[i].User)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Weight > out[j].Weight })
+	return out, nil
+}
+
+// resolveUser returns the node IDs of user, screen names
+// might have been used by more than one account
+func (s *Store) resolveUser(user string) ([]string, error) {
+	if NodeType(user) == "user" {
+		return []string{user}, nil
+	}
+	var out []string
+	ids, err := s.Find(strings.TrimPrefix(user, "@"))
+	for _, id := range ids {
+		if NodeType(id) == "user" {
+			out = append(out, id)
+		}
+	}
+	return out, err
+}
@@ -0,0 +1,167 @@
+package graph
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+func user(id int64, screenName string) *schema.User {
+	return &schema.User{Id: id, ScreenName: screenName}
+}
+
+// buildKind returns the nodes, labels and edges of t in graph
+func buildKind(graph string, t *schema.Tweet) (map[string]string, [][3]string) {
+	a := newAdder(KindOf(graph))
+	KindOf(graph).build(t, a)
+	var nodes map[string]string
+	for id := range a.nodes {
+		if nodes == nil {
+			nodes = make(map[string]string)
+		}
+		nodes[id] = a.labels[id]
+	}
+	var edges [][3]string
+	for e := range a.edges {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i][0]+edges[i][1]+edges[i][2] < edges[j][0]+edges[j][1]+edges[j][2]
+	})
+	return nodes, edges
+}
+
+func TestBuildUsers(t *testing.T) {
+	alice, bob, carol := user(1, "alice"), user(2, "bob"), user(3, "carol")
+	for _, tc := range []struct {
+		name  string
+		tweet *schema.Tweet
+		nodes map[string]string
+		edges [][3]string
+	}{
+		{
+			name:  "no author",
+			tweet: &schema.Tweet{Text: "hi"},
+		},
+		{
+			name:  "alone",
+			tweet: &schema.Tweet{User: alice},
+			nodes: map[string]string{"user:1": "alice"},
+		},
+		{
+			name: "reply",
+			// the mention added by twitter before the display range is skipped
+			tweet: &schema.Tweet{
+				User:                alice,
+				Text:                "@bob sure, ask @carol",
+				InReplyToUserId:     2,
+				InReplyToScreenName: "bob",
+				DisplayTextRange:    &schema.Indices{Start: 5, End: 21},
+				Entities: &schema.Entities{Mentions: []*schema.Mention{
+					{Id: 2, ScreenName: "bob", Indices: &schema.Indices{Start: 0, End: 4}},
+					{Id: 3, ScreenName: "carol", Indices: &schema.Indices{Start: 15, End: 21}},
+				}},
+			},
+			nodes: map[string]string{"user:1": "alice", "user:2": "bob", "user:3": "carol"},
+			edges: [][3]string{{"user:1", "user:2", EdgeRepliesTo}, {"user:1", "user:3", EdgeMentions}},
+		},
+		{
+			name: "mentions",
+			tweet: &schema.Tweet{User: alice, Entities: &schema.Entities{Mentions: []*schema.Mention{
+				{Id: 2, ScreenName: "bob"},
+				{Id: 1, ScreenName: "alice"},
+			}}},
+			nodes: map[string]string{"user:1": "alice", "user:2": "bob"},
+			edges: [][3]string{{"user:1", "user:2", EdgeMentions}},
+		},
+		{
+			name: "retweet",
+			// the mentions belong to the original tweet
+			tweet: &schema.Tweet{User: alice, Retweet: &schema.Tweet{
+				User:     bob,
+				Entities: &schema.Entities{Mentions: []*schema.Mention{{Id: 3, ScreenName: "carol"}}},
+			}},
+			nodes: map[string]string{"user:1": "alice", "user:2": "bob"},
+			edges: [][3]string{{"user:1", "user:2", EdgeRetweets}},
+		},
+		{
+			name: "quote",
+			tweet: &schema.Tweet{
+				User:         alice,
+				QuotedStatus: &schema.Tweet{User: bob},
+				Entities:     &schema.Entities{Mentions: []*schema.Mention{{Id: 3, ScreenName: "carol"}}},
+			},
+			nodes: map[string]string{"user:1": "alice", "user:2": "bob", "user:3": "carol"},
+			edges: [][3]string{{"user:1", "user:2", EdgeQuotes}, {"user:1", "user:3", EdgeMentions}},
+		},
+		{
+			name:  "edges keep their direction",
+			tweet: &schema.Tweet{User: carol, InReplyToUserId: 1, InReplyToScreenName: "alice"},
+			nodes: map[string]string{"user:1": "alice", "user:3": "carol"},
+			edges: [][3]string{{"user:3", "user:1", EdgeRepliesTo}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			nodes, edges := buildKind("users", tc.tweet)
+			if !reflect.DeepEqual(nodes, tc.nodes) {
+				t.Errorf("expecting nodes %v got %v", tc.nodes, nodes)
+			}
+			if !reflect.DeepEqual(edges, tc.edges) {
+				t.Errorf("expecting edges %v got %v", tc.edges, edges)
+			}
+		})
+	}
+}
+
+func TestInteractions(t *testing.T) {
+	s := openStore(t)
+	b, err := NewBuilder(s, nil, BuilderOptions{Graphs: []string{"users"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob, carol := user(1, "alice"), user(2, "bob"), user(3, "carol")
+	mention := &schema.Entities{Mentions: []*schema.Mention{{Id: 2, ScreenName: "bob"}}}
+	at := testBucket.Format(time.RFC3339)
+	for _, tw := range []*schema.Tweet{
+		{CreatedAt: at, User: alice, Entities: mention},
+		{CreatedAt: at, User: alice, Entities: mention},
+		{CreatedAt: at, User: bob, InReplyToUserId: 1, InReplyToScreenName: "alice"},
+		{CreatedAt: at, User: carol, Retweet: &schema.Tweet{User: alice}},
+		// a new account took the screen name
+		{CreatedAt: at, User: user(4, "Alice"), Entities: &schema.Entities{Mentions: []*schema.Mention{{Id: 3, ScreenName: "carol"}}}},
+	} {
+		b.Add(tw)
+	}
+	b.flush()
+
+	want := []Interaction{
+		{User: "user:2", Label: "bob", Type: EdgeMentions, Direction: "out", Weight: 2},
+		{User: "user:2", Label: "bob", Type: EdgeRepliesTo, Direction: "in", Weight: 1},
+		{User: "user:3", Label: "carol", Type: EdgeRetweets, Direction: "in", Weight: 1},
+	}
+	got, err := s.Interactions("user:1", Window{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %v got %v", want, got)
+	}
+
+	// screen names are resolved ignoring case, to every account using them
+	got, err = s.Interactions("@ALICE", Window{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, Interaction{User: "user:3", Label: "carol", Type: EdgeMentions, Direction: "out", Weight: 1})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %v got %v", want, got)
+	}
+
+	got, err = s.Interactions("nobody", Window{})
+	if err != nil || len(got) != 0 {
+		t.Errorf("expecting no interactions got %v (%v)", got, err)
+	}
+}
@@ -0,0 +1,73 @@
+package hooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// Event is emitted by a hook calling emit(kind, data)
+	Event struct {
+		Seq   int64       `json:"seq"`
+		Time  time.Time   `json:"time"`
+		Hook  string      `json:"hook"`
+		Kind  string      `json:"kind"`
+		Tweet int64       `json:"tweet,string"`
+		Data  interface{} `json:"data,omitempty"`
+	}
+
+	// eventLog keeps the most recent events
+	eventLog struct {
+		sync.Mutex
+		keep int
+		seq  int64
+		list []Event
+	}
+)
+
+func (l *eventLog) add(e Event) {
+	l.Lock()
+	defer l.Unlock()
+	l.seq++
+	e.Seq = l.seq
+	l.list = append(l.list, e)
+	if len(l.list) > l.keep {
+		l.list = append([]Event(nil), l.list[len(l.list)-l.keep:]...)
+	}
+}
+
+// Events returns the events kept in memory with a sequence
+// number greater than since, oldest first
+func (h *Hooks) Events(since int64) []Event {
+	h.events.Lock()
+	defer h.events.Unlock()
+	out := []Event{}
+	for _, e := range h.events.list {
+		if e.Seq > since {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// ServeHTTP returns the events after ?since as a JSON list
+func (h *Hooks) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var since int64
+	if s := req.URL.Query().Get("since"); len(s) > 0 {
+		var err error
+		since, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid since", http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(h.Events(since))
+}
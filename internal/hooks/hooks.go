@@ -0,0 +1,338 @@
+// Package hooks runs lua scripts over every tweet, scripts can drop
+// tweets, tag or annotate them and emit events. Scripts are reloaded
+// when the files in their directory change
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+type (
+	// Options configure the hooks
+	Options struct {
+		// Dir has the scripts (*.lua), they run in lexical order
+		Dir string
+		// Interval between checks for changed scripts
+		Interval time.Duration
+		// Timeout of each call to a hook
+		Timeout time.Duration
+		// Events is how many emitted events are kept in memory
+		Events int
+	}
+
+	// Hooks is a suture Service which reloads the scripts of a directory,
+	// Process runs them over a tweet
+	Hooks struct {
+		opts       Options
+		logCtx     zerolog.Logger
+		sampledLog zerolog.Logger
+		stop       chan struct{}
+
+		current struct {
+			sync.Mutex
+			scripts *scripts
+		}
+		vms    sync.Pool
+		events eventLog
+		// failed is the stamp of the last version which didn't load,
+		// it is reported once
+		failed string
+	}
+
+	// scripts is one version of the directory, compiled
+	scripts struct {
+		gen   int
+		stamp string
+		files []script
+	}
+
+	script struct {
+		name  string
+		proto *lua.FunctionProto
+	}
+
+	// vm is a lua state with every script loaded, states are
+	// not safe for concurrent use so each worker takes one
+	vm struct {
+		gen   int
+		L     *lua.LState
+		hooks []hook
+
+		// hook and tweet being processed, used by emit
+		hook  string
+		tweet int64
+		h     *Hooks
+	}
+
+	hook struct {
+		name string
+		fn   *lua.LFunction
+	}
+)
+
+const (
+	// hookFunc is the global function defined by each script
+	hookFunc = "on_tweet"
+)
+
+var (
+	hookErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "errors",
+		Namespace: "vogelnest",
+		Subsystem: "hooks",
+	}, []string{"hook"})
+	hookReloads = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "reloads",
+		Namespace: "vogelnest",
+		Subsystem: "hooks",
+	})
+	hookEvents = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "events",
+		Namespace: "vogelnest",
+		Subsystem: "hooks",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(hookErrors, hookReloads, hookEvents)
+}
+
+// NewHooks loads the scripts of opts.Dir
+func NewHooks(opts Options) (*Hooks, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = 2 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 100 * time.Millisecond
+	}
+	if opts.Events <= 0 {
+		opts.Events = 1000
+	}
+	h := &Hooks{opts: opts}
+	h.events.keep = opts.Events
+	h.logCtx = log.With().Str("service", "hooks").Logger()
+	h.sampledLog = h.logCtx.Sample(zerolog.Sometimes)
+	_, err := h.reload()
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Serve reloads the scripts when they change until Stop is called
+func (h *Hooks) Serve() {
+	h.stop = make(chan struct{})
+	ticker := time.NewTicker(h.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			changed, err := h.reload()
+			if err != nil {
+				h.logCtx.Error().Err(err).Str("action", "reload").Msg("Unable to reload scripts, keeping the previous version")
+			} else if changed {
+				h.logCtx.Info().Str("action", "reload").Msg("Scripts reloaded")
+			}
+		}
+	}
+}
+
+// Stop the service
+func (h *Hooks) Stop() {
+	close(h.stop)
+}
+
+func (h *Hooks) String() string {
+	return "hooks-service"
+}
+
+// Process runs every hook over t, it returns false when a hook drops it.
+// A hook which fails is skipped
+func (h *Hooks) Process(t *schema.Tweet) bool {
+	h.current.Lock()
+	sc := h.current.scripts
+	h.current.Unlock()
+	if sc == nil || len(sc.files) == 0 {
+		return true
+	}
+	v, err := h.take(sc)
+	if err != nil {
+		hookErrors.WithLabelValues("").Inc()
+		h.sampledLog.Error().Err(err).Str("action", "load").Msg("Unable to load scripts")
+		return true
+	}
+	keep, ok := v.process(t)
+	if ok {
+		h.vms.Put(v)
+	} else {
+		// a state interrupted by a timeout is not reused
+		v.L.Close()
+	}
+	return keep
+}
+
+// take returns a vm with the scripts of sc
+func (h *Hooks) take(sc *scripts) (*vm, error) {
+	for {
+		v, _ := h.vms.Get().(*vm)
+		if v == nil {
+			break
+		}
+		if v.gen == sc.gen {
+			return v, nil
+		}
+		v.L.Close()
+	}
+	return newVM(h, sc)
+}
+
+// reload compiles the scripts when the directory changed
+func (h *Hooks) reload() (bool, error) {
+	files, err := filepath.Glob(filepath.Join(h.opts.Dir, "*.lua"))
+	if err != nil {
+		return false, err
+	}
+	sort.Strings(files)
+	var stamp strings.Builder
+	for _, f := range files {
+		st, err := os.Stat(f)
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintf(&stamp, "%v:%v:%v;", f, st.Size(), st.ModTime().UnixNano())
+	}
+	h.current.Lock()
+	old := h.current.scripts
+	h.current.Unlock()
+	if (old != nil && old.stamp == stamp.String()) || h.failed == stamp.String() {
+		return false, nil
+	}
+	h.failed = stamp.String()
+
+	sc := &scripts{stamp: stamp.String()}
+	if old != nil {
+		sc.gen = old.gen + 1
+	}
+	for _, f := range files {
+		proto, err := compile(f)
+		if err != nil {
+			return false, err
+		}
+		sc.files = append(sc.files, script{name: filepath.Base(f), proto: proto})
+	}
+	// run the scripts once, errors at load time are reported now
+	// instead of once per tweet
+	v, err := newVM(h, sc)
+	if err != nil {
+		return false, err
+	}
+	h.current.Lock()
+	h.current.scripts = sc
+	h.current.Unlock()
+	h.vms.Put(v)
+	h.failed = ""
+	hookReloads.Inc()
+	return true, nil
+}
+
+func compile(file string) (*lua.FunctionProto, error) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	chunk, err := parse.Parse(strings.NewReader(string(buf)), file)
+	if err != nil {
+		return nil, err
+	}
+	return lua.Compile(chunk, file)
+}
+
+func newVM(h *Hooks, sc *scripts) (*vm, error) {
+	v := &vm{gen: sc.gen, L: lua.NewState(), h: h}
+	v.L.SetGlobal("emit", v.L.NewFunction(v.emit))
+	for _, s := range sc.files {
+		v.L.Push(v.L.NewFunctionFromProto(s.proto))
+		err := v.L.PCall(0, 0, nil)
+		if err != nil {
+			v.L.Close()
+			return nil, fmt.Errorf("unable to load %v: %w", s.name, err)
+		}
+		fn, ok := v.L.GetGlobal(hookFunc).(*lua.LFunction)
+		if !ok {
+			v.L.Close()
+			return nil, fmt.Errorf("%v doesn't define %v", s.name, hookFunc)
+		}
+		// scripts share the globals, each defines its own hook
+		v.L.SetGlobal(hookFunc, lua.LNil)
+		v.hooks = append(v.hooks, hook{name: s.name, fn: fn})
+	}
+	return v, nil
+}
+
+// process runs every hook over t, ok is false when the
+// state must be discarded
+func (v *vm) process(t *schema.Tweet) (keep bool, ok bool) {
+	tbl, err := toTable(v.L, t)
+	if err != nil {
+		hookErrors.WithLabelValues("").Inc()
+		v.h.sampledLog.Error().Err(err).Str("action", "convert").Int64("tweet", t.Id).Msg("Unable to convert tweet")
+		return true, true
+	}
+	keep, ok = true, true
+	v.tweet = t.Id
+	for _, hk := range v.hooks {
+		v.hook = hk.name
+		ctx, cancel := context.WithTimeout(context.Background(), v.h.opts.Timeout)
+		v.L.SetContext(ctx)
+		err := v.L.CallByParam(lua.P{Fn: hk.fn, NRet: 1, Protect: true}, tbl)
+		v.L.RemoveContext()
+		if ctx.Err() != nil {
+			ok = false
+		}
+		cancel()
+		if err != nil {
+			hookErrors.WithLabelValues(hk.name).Inc()
+			v.h.sampledLog.Error().Err(err).Str("action", "hook").Str("hook", hk.name).Int64("tweet", t.Id).Msg("Hook failed")
+			continue
+		}
+		ret := v.L.Get(-1)
+		v.L.Pop(1)
+		if ret == lua.LFalse {
+			keep = false
+			break
+		}
+	}
+	fromTable(tbl, t)
+	return keep, ok
+}
+
+// emit(kind, data) records an event, data is optional
+func (v *vm) emit(L *lua.LState) int {
+	kind := L.CheckString(1)
+	hookEvents.Inc()
+	v.h.events.add(Event{
+		Time:  time.Now(),
+		Hook:  v.hook,
+		Kind:  kind,
+		Tweet: v.tweet,
+		Data:  fromLua(L.Get(2), 0),
+	})
+	return 0
+}
@@ -0,0 +1,257 @@
+package hooks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+func scriptDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "vogelnest-hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// writeScript saves src to dir/name, the modification time moves
+// forward so the change is noticed even within the same tick
+func writeScript(t *testing.T, dir, name, src string) {
+	t.Helper()
+	file := filepath.Join(dir, name)
+	var next time.Time
+	if st, err := os.Stat(file); err == nil {
+		next = st.ModTime().Add(time.Second)
+	}
+	if err := ioutil.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !next.IsZero() {
+		if err := os.Chtimes(file, next, next); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newHooks(t *testing.T, opts Options) *Hooks {
+	t.Helper()
+	h, err := NewHooks(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestProcess(t *testing.T) {
+	dir := scriptDir(t)
+	writeScript(t, dir, "10-tag.lua", `
+function on_tweet(t)
+	table.insert(t.tags, "seen")
+	t.annotations.lang = t.lang
+	t.text = "changed"
+end`)
+	writeScript(t, dir, "20-drop.lua", `
+function on_tweet(t)
+	if t.id == "2" then return false end
+	if t.id == "3" then error("broken hook") end
+end`)
+	writeScript(t, dir, "30-after.lua", `
+function on_tweet(t)
+	emit("after", t.text)
+end`)
+	h := newHooks(t, Options{Dir: dir})
+
+	tw := &schema.Tweet{Id: 1, Text: "hello", Lang: "en", Tags: []string{"old"}}
+	if !h.Process(tw) {
+		t.Fatal("tweet 1 should be kept")
+	}
+	// only tags and annotations are copied back
+	if want := []string{"old", "seen"}; !reflect.DeepEqual(tw.Tags, want) {
+		t.Errorf("expecting tags %v got %v", want, tw.Tags)
+	}
+	if len(tw.Annotations) != 1 || tw.Annotations[0].Key != "lang" || tw.Annotations[0].Value != "en" {
+		t.Errorf("expecting the lang annotation got %v", tw.Annotations)
+	}
+	if tw.Text != "hello" {
+		t.Errorf("the text is read only, got %q", tw.Text)
+	}
+
+	if h.Process(&schema.Tweet{Id: 2}) {
+		t.Error("tweet 2 should be dropped")
+	}
+	// failing hooks are skipped
+	if !h.Process(&schema.Tweet{Id: 3}) {
+		t.Error("tweet 3 should be kept")
+	}
+
+	var tweets []int64
+	for _, e := range h.Events(0) {
+		tweets = append(tweets, e.Tweet)
+		if e.Hook != "30-after.lua" || e.Kind != "after" || e.Data != "changed" {
+			t.Errorf("unexpected event %+v", e)
+		}
+	}
+	// hooks after the one which dropped the tweet don't run
+	if want := []int64{1, 3}; !reflect.DeepEqual(tweets, want) {
+		t.Errorf("expecting events for %v got %v", want, tweets)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	dir := scriptDir(t)
+	writeScript(t, dir, "10-loop.lua", `
+function on_tweet(t)
+	if t.id == "1" then
+		while true do end
+	end
+	table.insert(t.tags, "fast")
+end`)
+	h := newHooks(t, Options{Dir: dir, Timeout: 20 * time.Millisecond})
+
+	done := make(chan bool)
+	go func() { done <- h.Process(&schema.Tweet{Id: 1}) }()
+	select {
+	case keep := <-done:
+		if !keep {
+			t.Error("tweets should be kept when a hook times out")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the hook wasn't cancelled")
+	}
+
+	tw := &schema.Tweet{Id: 2}
+	if !h.Process(tw) || !reflect.DeepEqual(tw.Tags, []string{"fast"}) {
+		t.Errorf("expecting hooks to keep working after a timeout, got tags %v", tw.Tags)
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := scriptDir(t)
+	tag := func(tag string) string {
+		return `function on_tweet(t) table.insert(t.tags, "` + tag + `") end`
+	}
+	tags := func(h *Hooks) []string {
+		tw := &schema.Tweet{Id: 1}
+		h.Process(tw)
+		return tw.Tags
+	}
+	writeScript(t, dir, "10-tag.lua", tag("a"))
+	h := newHooks(t, Options{Dir: dir})
+	if got := tags(h); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("expecting tag a got %v", got)
+	}
+	if changed, err := h.reload(); changed || err != nil {
+		t.Errorf("nothing changed, got %v (%v)", changed, err)
+	}
+
+	writeScript(t, dir, "10-tag.lua", tag("b"))
+	if changed, err := h.reload(); !changed || err != nil {
+		t.Fatalf("expecting a reload got %v (%v)", changed, err)
+	}
+	if got := tags(h); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("expecting tag b got %v", got)
+	}
+
+	// broken scripts keep the previous version, and are reported once
+	writeScript(t, dir, "20-broken.lua", "function on_tweet(t")
+	if _, err := h.reload(); err == nil {
+		t.Error("expecting a syntax error")
+	}
+	if changed, err := h.reload(); changed || err != nil {
+		t.Errorf("the same error should be reported once, got %v (%v)", changed, err)
+	}
+	if got := tags(h); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("expecting the previous version got %v", got)
+	}
+	writeScript(t, dir, "20-broken.lua", "x = 1")
+	if _, err := h.reload(); err == nil {
+		t.Errorf("expecting an error for scripts without %v", hookFunc)
+	}
+
+	for _, f := range []string{"10-tag.lua", "20-broken.lua"} {
+		os.Remove(filepath.Join(dir, f))
+	}
+	if changed, err := h.reload(); !changed || err != nil {
+		t.Fatalf("expecting a reload got %v (%v)", changed, err)
+	}
+	if got := tags(h); len(got) != 0 {
+		t.Errorf("expecting no hooks got %v", got)
+	}
+
+	if _, err := NewHooks(Options{Dir: dir}); err != nil {
+		t.Errorf("an empty directory is valid: %v", err)
+	}
+	writeScript(t, dir, "10-broken.lua", "function on_tweet(t")
+	if _, err := NewHooks(Options{Dir: dir}); err == nil {
+		t.Error("expecting an error for broken scripts")
+	}
+}
+
+func TestEvents(t *testing.T) {
+	dir := scriptDir(t)
+	writeScript(t, dir, "10-emit.lua", `
+function on_tweet(t)
+	emit("seen", {id = t.id, list = {1, 2}})
+end`)
+	h := newHooks(t, Options{Dir: dir, Events: 3})
+	for id := int64(1); id <= 5; id++ {
+		h.Process(&schema.Tweet{Id: id})
+	}
+
+	seqs := func(events []Event) []int64 {
+		out := []int64{}
+		for _, e := range events {
+			out = append(out, e.Seq)
+		}
+		return out
+	}
+	// only the last 3 are kept
+	events := h.Events(0)
+	if got := seqs(events); !reflect.DeepEqual(got, []int64{3, 4, 5}) {
+		t.Fatalf("expecting events 3 to 5 got %v", got)
+	}
+	want := map[string]interface{}{"id": "3", "list": []interface{}{1.0, 2.0}}
+	if events[0].Tweet != 3 || !reflect.DeepEqual(events[0].Data, want) {
+		t.Errorf("expecting %v for tweet 3 got %+v", want, events[0])
+	}
+	if got := seqs(h.Events(4)); !reflect.DeepEqual(got, []int64{5}) {
+		t.Errorf("expecting event 5 got %v", got)
+	}
+
+	for _, tc := range []struct {
+		method, url string
+		code        int
+		seqs        []int64
+	}{
+		{"GET", "/hooks/events?since=3", http.StatusOK, []int64{4, 5}},
+		{"GET", "/hooks/events?since=5", http.StatusOK, []int64{}},
+		{"GET", "/hooks/events?since=x", http.StatusBadRequest, nil},
+		{"POST", "/hooks/events", http.StatusMethodNotAllowed, nil},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tc.method, tc.url, nil))
+		if w.Code != tc.code {
+			t.Errorf("%v %v: expecting %v got %v", tc.method, tc.url, tc.code, w.Code)
+			continue
+		}
+		if tc.code != http.StatusOK {
+			continue
+		}
+		var events []Event
+		if err := json.Unmarshal(w.Body.Bytes(), &events); err != nil {
+			t.Fatal(err)
+		}
+		if got := seqs(events); !reflect.DeepEqual(got, tc.seqs) {
+			t.Errorf("%v: expecting %v got %v", tc.url, tc.seqs, got)
+		}
+	}
+}
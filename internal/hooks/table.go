@@ -0,0 +1,115 @@
+package hooks
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	lua "github.com/yuin/gopher-lua"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	// maxDepth limits the conversion of nested lua tables
+	maxDepth = 32
+)
+
+// toTable returns t as a lua table, using the protojson field names.
+// tags and annotations are always present
+func toTable(L *lua.LState, t *schema.Tweet) (*lua.LTable, error) {
+	buf, err := protojson.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	err = json.Unmarshal(buf, &fields)
+	if err != nil {
+		return nil, err
+	}
+	delete(fields, "annotations")
+	tbl := toLua(L, fields).(*lua.LTable)
+	if tbl.RawGetString("tags") == lua.LNil {
+		tbl.RawSetString("tags", L.NewTable())
+	}
+	annotations := L.NewTable()
+	for _, a := range t.Annotations {
+		annotations.RawSetString(a.Key, lua.LString(a.Value))
+	}
+	tbl.RawSetString("annotations", annotations)
+	return tbl, nil
+}
+
+// fromTable copies the tags and annotations of tbl to t,
+// other fields are read-only
+func fromTable(tbl *lua.LTable, t *schema.Tweet) {
+	t.Tags = nil
+	if tags, ok := tbl.RawGetString("tags").(*lua.LTable); ok {
+		for i := 1; i <= tags.MaxN(); i++ {
+			if tag := tags.RawGetInt(i); tag != lua.LNil {
+				t.Tags = append(t.Tags, tag.String())
+			}
+		}
+	}
+	t.Annotations = nil
+	if annotations, ok := tbl.RawGetString("annotations").(*lua.LTable); ok {
+		annotations.ForEach(func(k, v lua.LValue) {
+			t.Annotations = append(t.Annotations, &schema.Annotation{Key: k.String(), Value: v.String()})
+		})
+		sort.Slice(t.Annotations, func(i, j int) bool { return t.Annotations[i].Key < t.Annotations[j].Key })
+	}
+}
+
+// toLua converts values decoded from JSON
+func toLua(L *lua.LState, v interface{}) lua.LValue {
+	switch v := v.(type) {
+	case bool:
+		return lua.LBool(v)
+	case float64:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case []interface{}:
+		tbl := L.CreateTable(len(v), 0)
+		for _, item := range v {
+			tbl.Append(toLua(L, item))
+		}
+		return tbl
+	case map[string]interface{}:
+		tbl := L.CreateTable(0, len(v))
+		for k, item := range v {
+			tbl.RawSetString(k, toLua(L, item))
+		}
+		return tbl
+	}
+	return lua.LNil
+}
+
+// fromLua converts v to values which can be encoded as JSON,
+// tables with a sequence are converted to lists
+func fromLua(v lua.LValue, depth int) interface{} {
+	switch v := v.(type) {
+	case lua.LBool:
+		return bool(v)
+	case lua.LNumber:
+		return float64(v)
+	case lua.LString:
+		return string(v)
+	case *lua.LTable:
+		if depth >= maxDepth {
+			return nil
+		}
+		if n := v.MaxN(); n > 0 {
+			out := make([]interface{}, 0, n)
+			for i := 1; i <= n; i++ {
+				out = append(out, fromLua(v.RawGetInt(i), depth+1))
+			}
+			return out
+		}
+		out := make(map[string]interface{})
+		v.ForEach(func(k, item lua.LValue) {
+			out[k.String()] = fromLua(item, depth+1)
+		})
+		return out
+	}
+	return nil
+}
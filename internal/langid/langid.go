@@ -0,0 +1,97 @@
+// Package langid identifies the language of tweets using the
+// trigram profiles embedded in whatlanggo, without any external service
+package langid
+
+import (
+	"html"
+	"strings"
+
+	"github.com/abadojack/whatlanggo"
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/andrebq/vogelnest/internal/text"
+)
+
+type (
+	// Options control when the detector runs
+	Options struct {
+		// Always runs the detector on every tweet, as a second opinion,
+		// otherwise only tweets without a language are checked
+		Always bool
+		// MinConfidence is required to replace an unknown language,
+		// zero uses the reliability computed by whatlanggo
+		MinConfidence float64
+	}
+
+	// Detector identifies the language of tweets
+	Detector struct {
+		opts Options
+	}
+)
+
+// NewDetector returns a detector using opts
+func NewDetector(opts Options) *Detector {
+	return &Detector{opts: opts}
+}
+
+// Detect sets the LangDetection of t and the tweets it retweets
+// or quotes. When twitter doesn't know the language of a tweet
+// (empty or und) and the detection is reliable, Lang is replaced.
+func (d *Detector) Detect(t *schema.Tweet) {
+	if t == nil {
+		return
+	}
+	d.Detect(t.Retweet)
+	d.Detect(t.QuotedStatus)
+	unknown := isUnknown(t.Lang)
+	if !unknown && !d.opts.Always {
+		return
+	}
+	if t.Retweet != nil {
+		// the text of a retweet is a truncated copy of the original
+		t.LangDetection = t.Retweet.LangDetection
+	} else {
+		t.LangDetection = d.detect(plainText(t))
+	}
+	if unknown && d.accept(t.LangDetection) {
+		t.Lang = t.LangDetection.Lang
+	}
+}
+
+// plainText returns the text of t without entities, twitter counts
+// their indices over the escaped text so they are removed first
+func plainText(t *schema.Tweet) string {
+	return html.UnescapeString(text.StripEntities(t.Text, t.Entities))
+}
+
+func (d *Detector) detect(s string) *schema.LangDetection {
+	if len(strings.TrimSpace(s)) == 0 {
+		return nil
+	}
+	info := whatlanggo.Detect(s)
+	lang := info.Lang.Iso6391()
+	if len(lang) == 0 {
+		lang = info.Lang.Iso6393()
+	}
+	if len(lang) == 0 {
+		return nil
+	}
+	return &schema.LangDetection{
+		Lang:       lang,
+		Confidence: info.Confidence,
+		Reliable:   info.IsReliable(),
+	}
+}
+
+func (d *Detector) accept(ld *schema.LangDetection) bool {
+	if ld == nil {
+		return false
+	}
+	if d.opts.MinConfidence > 0 {
+		return ld.Confidence >= d.opts.MinConfidence
+	}
+	return ld.Reliable
+}
+
+func isUnknown(lang string) bool {
+	return len(lang) == 0 || lang == "und"
+}
@@ -0,0 +1,111 @@
+package langid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+// urlTweet has an escaped character before the URL, twitter
+// counts the indices of the URL over the escaped text
+func urlTweet(lang string) *schema.Tweet {
+	s := "Ontem fomos ao cinema &amp; depois jantamos com os nossos amigos na cidade https://t.co/abcdefghij"
+	start := int32(len([]rune(s[:strings.Index(s, "https")])))
+	return &schema.Tweet{
+		Text: s,
+		Lang: lang,
+		Entities: &schema.Entities{
+			Urls: []*schema.URLInfo{{Indices: &schema.Indices{Start: start, End: start + 23}}},
+		},
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	got := plainText(urlTweet(""))
+	if strings.Contains(got, "https") || strings.Contains(got, "t.co") {
+		t.Errorf("the URL should be removed, got %q", got)
+	}
+	if !strings.Contains(got, "cinema & depois") {
+		t.Errorf("the text should be unescaped, got %q", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		tweet    *schema.Tweet
+		opts     Options
+		lang     string
+		detected string
+	}{
+		{
+			name:     "unknown language",
+			tweet:    urlTweet(""),
+			lang:     "pt",
+			detected: "pt",
+		},
+		{
+			name:     "und is unknown",
+			tweet:    urlTweet("und"),
+			lang:     "pt",
+			detected: "pt",
+		},
+		{
+			name:  "existing language is kept",
+			tweet: urlTweet("es"),
+			lang:  "es",
+		},
+		{
+			name:     "second opinion",
+			tweet:    urlTweet("es"),
+			opts:     Options{Always: true},
+			lang:     "es",
+			detected: "pt",
+		},
+		{
+			// whatlanggo guesses something, but not reliably
+			name:     "short text stays und",
+			tweet:    &schema.Tweet{Text: "ok", Lang: "und"},
+			lang:     "und",
+			detected: "*",
+		},
+		{
+			name:  "only punctuation",
+			tweet: &schema.Tweet{Text: "&amp;", Lang: "und"},
+			lang:  "und",
+		},
+		{
+			name:     "confidence below the minimum",
+			tweet:    urlTweet("und"),
+			opts:     Options{MinConfidence: 1.1},
+			lang:     "und",
+			detected: "pt",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			NewDetector(tc.opts).Detect(tc.tweet)
+			if tc.tweet.Lang != tc.lang {
+				t.Errorf("expecting lang %q got %q", tc.lang, tc.tweet.Lang)
+			}
+			var detected string
+			if ld := tc.tweet.LangDetection; ld != nil {
+				detected = ld.Lang
+				if tc.detected == "*" && !ld.Reliable {
+					detected = "*"
+				}
+			}
+			if detected != tc.detected {
+				t.Errorf("expecting detection %q got %q", tc.detected, detected)
+			}
+		})
+	}
+}
+
+func TestDetectRetweet(t *testing.T) {
+	tw := &schema.Tweet{Text: "RT @someone: Ontem fomos…", Retweet: urlTweet("")}
+	NewDetector(Options{}).Detect(tw)
+	if tw.Lang != "pt" || tw.Retweet.Lang != "pt" {
+		t.Errorf("expecting pt for the retweet and the original, got %q and %q", tw.Lang, tw.Retweet.Lang)
+	}
+}
@@ -17,6 +17,13 @@ func (t *Tweet) Populate(o *twitter.Tweet) error {
 		t.Coordinates = &Coordinates{}
 		t.Coordinates.Populate(o.Coordinates)
 	}
+	if o.User != nil {
+		t.User = &User{}
+		err = t.User.Populate(o.User)
+		if err != nil {
+			return err
+		}
+	}
 	t.Lang = o.Lang
 	t.PossibleSensitive = o.PossiblySensitive
 	t.Stats = &TweetStats{}
@@ -29,10 +36,22 @@ func (t *Tweet) Populate(o *twitter.Tweet) error {
 		t.Retweet.Populate(o.RetweetedStatus)
 	}
 
-	if o.Truncated {
+	if o.Truncated && o.ExtendedTweet != nil {
 		t.Text = o.ExtendedTweet.FullText
+		t.DisplayTextRange = toSchemaIndices(o.ExtendedTweet.DisplayTextRange)
 	} else {
 		t.Text = o.Text
+		t.DisplayTextRange = toSchemaIndices(o.DisplayTextRange)
+	}
+
+	t.InReplyToStatusId = o.InReplyToStatusID
+	t.InReplyToUserId = o.InReplyToUserID
+	t.InReplyToScreenName = o.InReplyToScreenName
+	t.Source = o.Source
+	t.QuotedStatusId = o.QuotedStatusID
+	if o.Place != nil {
+		t.Place = &Place{}
+		t.Place.Populate(o.Place)
 	}
 
 	if o.QuotedStatus != nil {
@@ -40,7 +59,7 @@ func (t *Tweet) Populate(o *twitter.Tweet) error {
 		t.QuotedStatus.Populate(o.QuotedStatus)
 	}
 
-	if o.Entities != nil || o.ExtendedEntities != nil {
+	if o.Entities != nil || o.ExtendedEntities != nil || o.ExtendedTweet != nil {
 		t.Entities = &Entities{}
 		t.Entities.Populate(o)
 	}
@@ -48,9 +67,30 @@ func (t *Tweet) Populate(o *twitter.Tweet) error {
 	return nil
 }
 
+func (u *User) Populate(o *twitter.User) error {
+	u.Id = o.ID
+	u.ScreenName = o.ScreenName
+	u.Name = o.Name
+	if len(o.CreatedAt) > 0 {
+		createdAt, err := time.ParseInLocation(time.RubyDate, o.CreatedAt, time.UTC)
+		if err != nil {
+			return err
+		}
+		u.CreatedAt = createdAt.Format(time.RFC3339)
+	}
+	u.FollowersCount = int32(o.FollowersCount)
+	u.FriendsCount = int32(o.FriendsCount)
+	u.StatusesCount = int32(o.StatusesCount)
+	u.Verified = o.Verified
+	u.Location = o.Location
+	u.Description = o.Description
+	return nil
+}
+
 func (c *Coordinates) Populate(o *twitter.Coordinates) {
-	c.Lat = o.Coordinates[0]
-	c.Long = o.Coordinates[1]
+	// GeoJSON uses [longitude, latitude]
+	c.Lat = o.Coordinates[1]
+	c.Long = o.Coordinates[0]
 	c.Type = o.Type
 }
 
@@ -59,6 +99,26 @@ func (t *TweetStats) Populate(o *twitter.Tweet) {
 	t.ReplyCount = int32(o.ReplyCount)
 	t.RetweetCount = int32(o.RetweetCount)
 	t.Retweeted = o.Retweeted
+	t.FavoriteCount = int32(o.FavoriteCount)
+}
+
+func (p *Place) Populate(o *twitter.Place) {
+	p.Id = o.ID
+	p.Name = o.Name
+	p.FullName = o.FullName
+	p.PlaceType = o.PlaceType
+	p.Country = o.Country
+	p.CountryCode = o.CountryCode
+	p.Url = o.URL
+	if o.BoundingBox != nil && len(o.BoundingBox.Coordinates) > 0 {
+		// GeoJSON uses [longitude, latitude]
+		for _, c := range o.BoundingBox.Coordinates[0] {
+			p.BoundingBox = append(p.BoundingBox, &Coordinates{
+				Lat:  c[1],
+				Long: c[0],
+			})
+		}
+	}
 }
 
 func (w *WitheldInfo) Populate(t *twitter.Tweet) {
@@ -67,28 +127,32 @@ func (w *WitheldInfo) Populate(t *twitter.Tweet) {
 	w.WithheldScope = t.WithheldScope
 }
 
+// Populate uses the entities of the extended tweet when o is truncated,
+// as the ones in o only cover the truncated text
 func (e *Entities) Populate(o *twitter.Tweet) {
-	if o.Entities.Hashtags != nil {
-		for _, h := range o.Entities.Hashtags {
+	entities, extended := o.Entities, o.ExtendedEntities
+	if o.Truncated && o.ExtendedTweet != nil {
+		entities, extended = o.ExtendedTweet.Entities, o.ExtendedTweet.ExtendedEntities
+	}
+
+	if entities != nil {
+		for _, h := range entities.Hashtags {
 			e.Hashtags = append(e.Hashtags, &Hashtag{
 				Text:    h.Text,
 				Indices: toSchemaIndices(h.Indices),
 			})
 		}
-	}
 
-	if o.Entities.UserMentions != nil {
-		for _, u := range o.Entities.UserMentions {
+		for _, u := range entities.UserMentions {
 			e.Mentions = append(e.Mentions, &Mention{
 				Id:         u.ID,
 				Name:       u.Name,
 				ScreenName: u.ScreenName,
+				Indices:    toSchemaIndices(u.Indices),
 			})
 		}
-	}
 
-	if o.Entities.Urls != nil {
-		for _, u := range o.Entities.Urls {
+		for _, u := range entities.Urls {
 			e.Urls = append(e.Urls, &URLInfo{
 				Indices:     toSchemaIndices(u.Indices),
 				DisplayUrl:  u.DisplayURL,
@@ -98,16 +162,28 @@ func (e *Entities) Populate(o *twitter.Tweet) {
 		}
 	}
 
-	if o.ExtendedEntities != nil {
-		for _, m := range o.ExtendedEntities.Media {
-			md := &Media{}
-			md.Populate(m)
-			e.Media = append(e.Media, md)
-		}
+	// extended entities list every photo of the tweet,
+	// entities only the first one
+	media := []twitter.MediaEntity(nil)
+	if extended != nil {
+		media = extended.Media
+	} else if entities != nil {
+		media = entities.Media
+	}
+	for _, m := range media {
+		md := &Media{}
+		md.Populate(m)
+		e.Media = append(e.Media, md)
 	}
 }
 
 func (m *Media) Populate(o twitter.MediaEntity) {
+	m.Url = &URLInfo{
+		Indices:     toSchemaIndices(o.Indices),
+		DisplayUrl:  o.DisplayURL,
+		ExpandedUrl: o.ExpandedURL,
+		Url:         o.URL,
+	}
 	m.MediaUrl = o.MediaURL
 	m.MediaUrlHttps = o.MediaURLHttps
 	m.Id = o.ID
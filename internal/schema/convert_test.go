@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/dghubble/go-twitter/twitter"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files in testdata")
+
+func TestPopulate(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func(t *testing.T, tw *Tweet)
+	}{
+		{
+			name: "truncated",
+			check: func(t *testing.T, tw *Tweet) {
+				if tw.Entities == nil || len(tw.Entities.Hashtags) != 2 {
+					t.Fatalf("expecting the 2 hashtags of extended_tweet, got %v", tw.Entities)
+				}
+				if tw.Entities.Hashtags[0].Text != "golang" {
+					t.Errorf("unexpected hashtag %v", tw.Entities.Hashtags[0].Text)
+				}
+				if len(tw.Entities.Urls) != 1 || tw.Entities.Urls[0].ExpandedUrl != "https://github.com/andrebq/vogelnest/releases" {
+					t.Errorf("expecting the url of extended_tweet, got %v", tw.Entities.Urls)
+				}
+				if len(tw.Entities.Media) != 2 {
+					t.Errorf("expecting both photos of extended_entities, got %v", len(tw.Entities.Media))
+				}
+				if tw.DisplayTextRange.End != 181 {
+					t.Errorf("expecting the display range of extended_tweet, got %v", tw.DisplayTextRange)
+				}
+			},
+		},
+		{
+			name: "extended_media_only",
+			check: func(t *testing.T, tw *Tweet) {
+				if tw.Entities == nil || len(tw.Entities.Media) != 1 {
+					t.Fatalf("expecting the media of extended_entities, got %v", tw.Entities)
+				}
+				m := tw.Entities.Media[0]
+				if m.Type != "video" || m.VideoInfo == nil || len(m.VideoInfo.Variants) != 2 {
+					t.Errorf("unexpected media %v", m)
+				}
+				if len(tw.Entities.Hashtags) != 0 || len(tw.Entities.Urls) != 0 {
+					t.Errorf("unexpected entities %v", tw.Entities)
+				}
+			},
+		},
+		{
+			name: "coordinates",
+			check: func(t *testing.T, tw *Tweet) {
+				if tw.Coordinates == nil || tw.Coordinates.Lat != -23.5874 || tw.Coordinates.Long != -46.6576 {
+					t.Errorf("coordinates should be read as [long, lat], got %v", tw.Coordinates)
+				}
+				if tw.Place == nil || len(tw.Place.BoundingBox) != 4 {
+					t.Fatalf("expecting the bounding box of the place, got %v", tw.Place)
+				}
+				if c := tw.Place.BoundingBox[0]; c.Lat != -24.008814 || c.Long != -46.826039 {
+					t.Errorf("bounding box should be read as [long, lat], got %v", c)
+				}
+			},
+		},
+		{
+			name: "author",
+			check: func(t *testing.T, tw *Tweet) {
+				u := tw.User
+				if u == nil || u.Id != 90210 || u.ScreenName != "bia_dev" || u.Name != "Bia 🐿" {
+					t.Fatalf("expecting the author of the retweet, got %v", u)
+				}
+				// users without created_at or description are still valid
+				if u.CreatedAt != "" || u.Description != "" || u.FollowersCount != 12 || u.FriendsCount != 80 || u.StatusesCount != 301 {
+					t.Errorf("unexpected author %v", u)
+				}
+				o := tw.Retweet.GetUser()
+				if o == nil || o.Id != 113419064 || o.ScreenName != "golang" || !o.Verified {
+					t.Fatalf("expecting the author of the original tweet, got %v", o)
+				}
+				if o.CreatedAt != "2010-02-11T18:04:38Z" || o.Location != "Mountain View, CA" || o.FollowersCount != 165301 {
+					t.Errorf("unexpected original author %v", o)
+				}
+			},
+		},
+		{
+			name: "reply",
+			check: func(t *testing.T, tw *Tweet) {
+				if tw.InReplyToStatusId != 1316439227215376384 || tw.InReplyToUserId != 113419064 || tw.InReplyToScreenName != "golang" {
+					t.Errorf("unexpected reply metadata %v", tw)
+				}
+				// the mentions added by twitter are outside the display range
+				if r := tw.DisplayTextRange; r == nil || r.Start != 17 || r.End != 44 {
+					t.Errorf("expecting the display range [17, 44], got %v", r)
+				}
+				if tw.Source != `<a href="https://mobile.twitter.com" rel="nofollow">Twitter Web App</a>` {
+					t.Errorf("unexpected source %v", tw.Source)
+				}
+				p := tw.Place
+				if p == nil || p.Id != "1b107df3ccc0aaa1" || p.PlaceType != "admin" || p.FullName != "Minas Gerais, Brasil" || p.CountryCode != "BR" {
+					t.Fatalf("unexpected place %v", p)
+				}
+				if len(p.BoundingBox) != 0 || p.Url != "https://api.twitter.com/1.1/geo/id/1b107df3ccc0aaa1.json" {
+					t.Errorf("expecting a place without bounding box, got %v", p)
+				}
+				if tw.Coordinates != nil {
+					t.Errorf("expecting no coordinates, got %v", tw.Coordinates)
+				}
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := ioutil.ReadFile(filepath.Join("testdata", tc.name+".json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var o twitter.Tweet
+			if err := json.Unmarshal(raw, &o); err != nil {
+				t.Fatal(err)
+			}
+			tw := &Tweet{}
+			if err := tw.Populate(&o); err != nil {
+				t.Fatal(err)
+			}
+			tc.check(t, tw)
+
+			got := goldenJSON(t, tw)
+			golden := filepath.Join("testdata", tc.name+".golden")
+			if *update {
+				if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output differs from %v (run with -update to accept it):\n%s", golden, got)
+			}
+		})
+	}
+}
+
+// goldenJSON returns tw as indented protojson, protojson randomizes
+// its whitespace so the output is reformatted
+func goldenJSON(t *testing.T, tw *Tweet) []byte {
+	buf, err := protojson.Marshal(tw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compact, out bytes.Buffer
+	if err := json.Compact(&compact, buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	out.WriteByte('\n')
+	return out.Bytes()
+}
+
+func TestPopulateUser(t *testing.T) {
+	u := &User{}
+	if err := u.Populate(&twitter.User{ID: 1, CreatedAt: "yesterday"}); err == nil {
+		t.Error("expecting an error for an invalid created_at")
+	}
+	tw := &Tweet{}
+	err := tw.Populate(&twitter.Tweet{CreatedAt: "Sat Oct 17 09:12:03 +0000 2020", User: &twitter.User{CreatedAt: "yesterday"}})
+	if err == nil {
+		t.Error("expecting the error of the author")
+	}
+}
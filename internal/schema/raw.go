@@ -0,0 +1,117 @@
+package schema
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dghubble/go-twitter/twitter"
+)
+
+type (
+	// RawEntities has the entities which go-twitter doesn't decode,
+	// they must be read from the JSON sent by twitter
+	RawEntities struct {
+		Symbols []twitter.HashtagEntity `json:"symbols"`
+		Polls   []RawPoll               `json:"polls"`
+	}
+
+	// RawPoll is a poll as sent by twitter
+	RawPoll struct {
+		Options []struct {
+			Position int    `json:"position"`
+			Text     string `json:"text"`
+		} `json:"options"`
+		EndDatetime     string `json:"end_datetime"`
+		DurationMinutes int    `json:"duration_minutes"`
+	}
+
+	rawTweet struct {
+		ID            int64        `json:"id"`
+		Truncated     bool         `json:"truncated"`
+		Entities      *RawEntities `json:"entities"`
+		ExtendedTweet *struct {
+			Entities *RawEntities `json:"entities"`
+		} `json:"extended_tweet"`
+		RetweetedStatus *rawTweet `json:"retweeted_status"`
+		QuotedStatus    *rawTweet `json:"quoted_status"`
+	}
+)
+
+// ParseRawEntities returns the RawEntities of the tweet encoded in buf
+// and of the tweets it retweets or quotes, indexed by tweet id.
+//
+// Tweets without symbols or polls are not included
+func ParseRawEntities(buf []byte) (map[int64]*RawEntities, error) {
+	var rt rawTweet
+	err := json.Unmarshal(buf, &rt)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64]*RawEntities)
+	rt.collect(out)
+	return out, nil
+}
+
+func (rt *rawTweet) collect(out map[int64]*RawEntities) {
+	if rt == nil || rt.ID == 0 {
+		return
+	}
+	e := rt.Entities
+	if rt.Truncated && rt.ExtendedTweet != nil {
+		e = rt.ExtendedTweet.Entities
+	}
+	if e != nil && (len(e.Symbols) > 0 || len(e.Polls) > 0) {
+		out[rt.ID] = e
+	}
+	rt.RetweetedStatus.collect(out)
+	rt.QuotedStatus.collect(out)
+}
+
+// PopulateRaw adds the entities from raw to t and to the
+// tweets it retweets or quotes
+func (t *Tweet) PopulateRaw(raw map[int64]*RawEntities) error {
+	if t == nil || len(raw) == 0 {
+		return nil
+	}
+	if e, ok := raw[t.Id]; ok {
+		if t.Entities == nil {
+			t.Entities = &Entities{}
+		}
+		err := t.Entities.PopulateRaw(e)
+		if err != nil {
+			return err
+		}
+	}
+	err := t.Retweet.PopulateRaw(raw)
+	if err != nil {
+		return err
+	}
+	return t.QuotedStatus.PopulateRaw(raw)
+}
+
+func (e *Entities) PopulateRaw(o *RawEntities) error {
+	for _, s := range o.Symbols {
+		e.Symbols = append(e.Symbols, &Symbol{
+			Text:    s.Text,
+			Indices: toSchemaIndices(s.Indices),
+		})
+	}
+	for _, p := range o.Polls {
+		poll := &Poll{DurationMinutes: int32(p.DurationMinutes)}
+		if len(p.EndDatetime) > 0 {
+			end, err := time.ParseInLocation(time.RubyDate, p.EndDatetime, time.UTC)
+			if err != nil {
+				return err
+			}
+			poll.EndDatetime = end.Format(time.RFC3339)
+		}
+		for _, o := range p.Options {
+			poll.Options = append(poll.Options, &PollOption{
+				Position: int32(o.Position),
+				Text:     o.Text,
+			})
+		}
+		e.Polls = append(e.Polls, poll)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+const rawRetweet = `{
+	"id": 3,
+	"entities": {"symbols": []},
+	"retweeted_status": {
+		"id": 2,
+		"truncated": true,
+		"entities": {"symbols": [{"text": "short", "indices": [0, 6]}]},
+		"extended_tweet": {"entities": {
+			"symbols": [{"text": "TWTR", "indices": [10, 15]}],
+			"polls": [{
+				"options": [{"position": 1, "text": "yes"}, {"position": 2, "text": "no"}],
+				"end_datetime": "Sun Oct 18 12:00:00 +0000 2020",
+				"duration_minutes": 60
+			}]
+		}},
+		"quoted_status": {"id": 1, "entities": {"symbols": [{"text": "GOOG", "indices": [1, 6]}]}}
+	}
+}`
+
+func TestParseRawEntities(t *testing.T) {
+	raw, err := ParseRawEntities([]byte(rawRetweet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the retweet has no symbols or polls, truncated tweets use extended_tweet
+	if len(raw) != 2 || raw[3] != nil {
+		t.Fatalf("expecting tweets 1 and 2 got %v", raw)
+	}
+	if s := raw[2].Symbols; len(s) != 1 || s[0].Text != "TWTR" || len(raw[2].Polls) != 1 {
+		t.Errorf("expecting the entities of extended_tweet got %+v", raw[2])
+	}
+	if s := raw[1].Symbols; len(s) != 1 || s[0].Text != "GOOG" {
+		t.Errorf("expecting the symbols of the quoted tweet got %+v", raw[1])
+	}
+
+	if _, err := ParseRawEntities([]byte(`{"id": "nope"`)); err == nil {
+		t.Error("expecting an error for invalid json")
+	}
+}
+
+func TestPopulateRaw(t *testing.T) {
+	raw, err := ParseRawEntities([]byte(rawRetweet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := &Tweet{Id: 3, Retweet: &Tweet{
+		Id:           2,
+		Entities:     &Entities{Symbols: []*Symbol{{Text: "OLD"}}},
+		QuotedStatus: &Tweet{Id: 1},
+	}}
+	if err := tw.PopulateRaw(raw); err != nil {
+		t.Fatal(err)
+	}
+	if tw.Entities != nil {
+		t.Errorf("the retweet has no raw entities, got %v", tw.Entities)
+	}
+	e := tw.Retweet.Entities
+	if len(e.Symbols) != 2 || e.Symbols[1].Text != "TWTR" || e.Symbols[1].Indices.Start != 10 || e.Symbols[1].Indices.End != 15 {
+		t.Errorf("expecting TWTR after the existing symbols got %v", e.Symbols)
+	}
+	if len(e.Polls) != 1 {
+		t.Fatalf("expecting a poll got %v", e.Polls)
+	}
+	p := e.Polls[0]
+	var options []string
+	for _, o := range p.Options {
+		options = append(options, o.Text)
+	}
+	if p.DurationMinutes != 60 || p.EndDatetime != "2020-10-18T12:00:00Z" || !reflect.DeepEqual(options, []string{"yes", "no"}) {
+		t.Errorf("unexpected poll %v", p)
+	}
+	if q := tw.Retweet.QuotedStatus.Entities; q == nil || len(q.Symbols) != 1 || q.Symbols[0].Text != "GOOG" {
+		t.Errorf("expecting the symbols of the quoted tweet got %v", q)
+	}
+
+	bad := map[int64]*RawEntities{1: {Polls: []RawPoll{{EndDatetime: "tomorrow"}}}}
+	if err := (&Tweet{Id: 1}).PopulateRaw(bad); err == nil {
+		t.Error("expecting an error for an invalid end_datetime")
+	}
+	if err := (*Tweet)(nil).PopulateRaw(raw); err != nil {
+		t.Errorf("nil tweets should be skipped, got %v", err)
+	}
+}
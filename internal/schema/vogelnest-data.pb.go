@@ -30,17 +30,43 @@ type Tweet struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Coordinates       *Coordinates `protobuf:"bytes,1,opt,name=coordinates,proto3" json:"coordinates,omitempty"`
-	Id                int64        `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
-	Lang              string       `protobuf:"bytes,3,opt,name=lang,proto3" json:"lang,omitempty"`
-	PossibleSensitive bool         `protobuf:"varint,4,opt,name=possibleSensitive,proto3" json:"possibleSensitive,omitempty"`
-	Stats             *TweetStats  `protobuf:"bytes,5,opt,name=stats,proto3" json:"stats,omitempty"`
-	Witheld           *WitheldInfo `protobuf:"bytes,6,opt,name=witheld,proto3" json:"witheld,omitempty"`
-	Retweet           *Tweet       `protobuf:"bytes,7,opt,name=retweet,proto3" json:"retweet,omitempty"`
-	Text              string       `protobuf:"bytes,8,opt,name=text,proto3" json:"text,omitempty"`
-	QuotedStatus      *Tweet       `protobuf:"bytes,9,opt,name=quotedStatus,proto3" json:"quotedStatus,omitempty"`
-	Entities          *Entities    `protobuf:"bytes,10,opt,name=entities,proto3" json:"entities,omitempty"`
-	CreatedAt         string       `protobuf:"bytes,11,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	Coordinates         *Coordinates `protobuf:"bytes,1,opt,name=coordinates,proto3" json:"coordinates,omitempty"`
+	Id                  int64        `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Lang                string       `protobuf:"bytes,3,opt,name=lang,proto3" json:"lang,omitempty"`
+	PossibleSensitive   bool         `protobuf:"varint,4,opt,name=possibleSensitive,proto3" json:"possibleSensitive,omitempty"`
+	Stats               *TweetStats  `protobuf:"bytes,5,opt,name=stats,proto3" json:"stats,omitempty"`
+	Witheld             *WitheldInfo `protobuf:"bytes,6,opt,name=witheld,proto3" json:"witheld,omitempty"`
+	Retweet             *Tweet       `protobuf:"bytes,7,opt,name=retweet,proto3" json:"retweet,omitempty"`
+	Text                string       `protobuf:"bytes,8,opt,name=text,proto3" json:"text,omitempty"`
+	QuotedStatus        *Tweet       `protobuf:"bytes,9,opt,name=quotedStatus,proto3" json:"quotedStatus,omitempty"`
+	Entities            *Entities    `protobuf:"bytes,10,opt,name=entities,proto3" json:"entities,omitempty"`
+	CreatedAt           string       `protobuf:"bytes,11,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	User                *User        `protobuf:"bytes,12,opt,name=user,proto3" json:"user,omitempty"`
+	InReplyToStatusId   int64        `protobuf:"varint,13,opt,name=inReplyToStatusId,proto3" json:"inReplyToStatusId,omitempty"`
+	InReplyToUserId     int64        `protobuf:"varint,14,opt,name=inReplyToUserId,proto3" json:"inReplyToUserId,omitempty"`
+	InReplyToScreenName string       `protobuf:"bytes,15,opt,name=inReplyToScreenName,proto3" json:"inReplyToScreenName,omitempty"`
+	Place               *Place       `protobuf:"bytes,16,opt,name=place,proto3" json:"place,omitempty"`
+	// source is the HTML link to the client used to post the tweet
+	Source         string `protobuf:"bytes,17,opt,name=source,proto3" json:"source,omitempty"`
+	QuotedStatusId int64  `protobuf:"varint,18,opt,name=quotedStatusId,proto3" json:"quotedStatusId,omitempty"`
+	// displayTextRange is the part of text which is shown to users,
+	// without leading mentions and trailing media links
+	DisplayTextRange *Indices `protobuf:"bytes,19,opt,name=displayTextRange,proto3" json:"displayTextRange,omitempty"`
+	// tokens are the normalized words and emoji of text,
+	// see internal/text
+	Tokens        []string       `protobuf:"bytes,20,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	Sentiment     *Sentiment     `protobuf:"bytes,21,opt,name=sentiment,proto3" json:"sentiment,omitempty"`
+	LangDetection *LangDetection `protobuf:"bytes,22,opt,name=langDetection,proto3" json:"langDetection,omitempty"`
+	// domains are the hosts of the links in the tweet,
+	// see internal/enrich
+	Domains []string `protobuf:"bytes,23,rep,name=domains,proto3" json:"domains,omitempty"`
+	// location is coordinates, or the center of place
+	// when the tweet isn't geotagged
+	Location *Coordinates `protobuf:"bytes,24,opt,name=location,proto3" json:"location,omitempty"`
+	// tags and annotations are added by lua hooks,
+	// see internal/hooks
+	Tags        []string      `protobuf:"bytes,25,rep,name=tags,proto3" json:"tags,omitempty"`
+	Annotations []*Annotation `protobuf:"bytes,26,rep,name=annotations,proto3" json:"annotations,omitempty"`
 }
 
 func (x *Tweet) Reset() {
@@ -152,34 +178,773 @@ func (x *Tweet) GetCreatedAt() string {
 	return ""
 }
 
+func (x *Tweet) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *Tweet) GetInReplyToStatusId() int64 {
+	if x != nil {
+		return x.InReplyToStatusId
+	}
+	return 0
+}
+
+func (x *Tweet) GetInReplyToUserId() int64 {
+	if x != nil {
+		return x.InReplyToUserId
+	}
+	return 0
+}
+
+func (x *Tweet) GetInReplyToScreenName() string {
+	if x != nil {
+		return x.InReplyToScreenName
+	}
+	return ""
+}
+
+func (x *Tweet) GetPlace() *Place {
+	if x != nil {
+		return x.Place
+	}
+	return nil
+}
+
+func (x *Tweet) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Tweet) GetQuotedStatusId() int64 {
+	if x != nil {
+		return x.QuotedStatusId
+	}
+	return 0
+}
+
+func (x *Tweet) GetDisplayTextRange() *Indices {
+	if x != nil {
+		return x.DisplayTextRange
+	}
+	return nil
+}
+
+func (x *Tweet) GetTokens() []string {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *Tweet) GetSentiment() *Sentiment {
+	if x != nil {
+		return x.Sentiment
+	}
+	return nil
+}
+
+func (x *Tweet) GetLangDetection() *LangDetection {
+	if x != nil {
+		return x.LangDetection
+	}
+	return nil
+}
+
+func (x *Tweet) GetDomains() []string {
+	if x != nil {
+		return x.Domains
+	}
+	return nil
+}
+
+func (x *Tweet) GetLocation() *Coordinates {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *Tweet) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Tweet) GetAnnotations() []*Annotation {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+type Annotation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key   string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *Annotation) Reset() {
+	*x = Annotation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vogelnest_data_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Annotation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Annotation) ProtoMessage() {}
+
+func (x *Annotation) ProtoReflect() protoreflect.Message {
+	mi := &file_vogelnest_data_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Annotation.ProtoReflect.Descriptor instead.
+func (*Annotation) Descriptor() ([]byte, []int) {
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Annotation) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Annotation) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// LangDetection is the language identified from the text,
+// see internal/langid
+type LangDetection struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// lang is an ISO 639-1 code, or ISO 639-3 when there isn't one
+	Lang       string  `protobuf:"bytes,1,opt,name=lang,proto3" json:"lang,omitempty"`
+	Confidence float64 `protobuf:"fixed64,2,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Reliable   bool    `protobuf:"varint,3,opt,name=reliable,proto3" json:"reliable,omitempty"`
+}
+
+func (x *LangDetection) Reset() {
+	*x = LangDetection{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vogelnest_data_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LangDetection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LangDetection) ProtoMessage() {}
+
+func (x *LangDetection) ProtoReflect() protoreflect.Message {
+	mi := &file_vogelnest_data_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LangDetection.ProtoReflect.Descriptor instead.
+func (*LangDetection) Descriptor() ([]byte, []int) {
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LangDetection) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *LangDetection) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *LangDetection) GetReliable() bool {
+	if x != nil {
+		return x.Reliable
+	}
+	return false
+}
+
+// Sentiment is computed from a lexicon, see internal/sentiment
+type Sentiment struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// lang of the lexicon used
+	Lang string `protobuf:"bytes,1,opt,name=lang,proto3" json:"lang,omitempty"`
+	// compound is the overall score, from -1 (negative) to 1 (positive)
+	Compound float64 `protobuf:"fixed64,2,opt,name=compound,proto3" json:"compound,omitempty"`
+	// positive, negative and neutral are the proportion of
+	// the text in each category
+	Positive float64    `protobuf:"fixed64,3,opt,name=positive,proto3" json:"positive,omitempty"`
+	Negative float64    `protobuf:"fixed64,4,opt,name=negative,proto3" json:"negative,omitempty"`
+	Neutral  float64    `protobuf:"fixed64,5,opt,name=neutral,proto3" json:"neutral,omitempty"`
+	Emotions []*Emotion `protobuf:"bytes,6,rep,name=emotions,proto3" json:"emotions,omitempty"`
+}
+
+func (x *Sentiment) Reset() {
+	*x = Sentiment{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vogelnest_data_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Sentiment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sentiment) ProtoMessage() {}
+
+func (x *Sentiment) ProtoReflect() protoreflect.Message {
+	mi := &file_vogelnest_data_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sentiment.ProtoReflect.Descriptor instead.
+func (*Sentiment) Descriptor() ([]byte, []int) {
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Sentiment) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *Sentiment) GetCompound() float64 {
+	if x != nil {
+		return x.Compound
+	}
+	return 0
+}
+
+func (x *Sentiment) GetPositive() float64 {
+	if x != nil {
+		return x.Positive
+	}
+	return 0
+}
+
+func (x *Sentiment) GetNegative() float64 {
+	if x != nil {
+		return x.Negative
+	}
+	return 0
+}
+
+func (x *Sentiment) GetNeutral() float64 {
+	if x != nil {
+		return x.Neutral
+	}
+	return 0
+}
+
+func (x *Sentiment) GetEmotions() []*Emotion {
+	if x != nil {
+		return x.Emotions
+	}
+	return nil
+}
+
+type Emotion struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// score is the proportion of words associated with the emotion
+	Score float64 `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+}
+
+func (x *Emotion) Reset() {
+	*x = Emotion{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vogelnest_data_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Emotion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Emotion) ProtoMessage() {}
+
+func (x *Emotion) ProtoReflect() protoreflect.Message {
+	mi := &file_vogelnest_data_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Emotion.ProtoReflect.Descriptor instead.
+func (*Emotion) Descriptor() ([]byte, []int) {
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Emotion) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Emotion) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type Place struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	FullName    string `protobuf:"bytes,3,opt,name=fullName,proto3" json:"fullName,omitempty"`
+	PlaceType   string `protobuf:"bytes,4,opt,name=placeType,proto3" json:"placeType,omitempty"`
+	Country     string `protobuf:"bytes,5,opt,name=country,proto3" json:"country,omitempty"`
+	CountryCode string `protobuf:"bytes,6,opt,name=countryCode,proto3" json:"countryCode,omitempty"`
+	Url         string `protobuf:"bytes,7,opt,name=url,proto3" json:"url,omitempty"`
+	// boundingBox is the outer ring of the polygon around the place
+	BoundingBox []*Coordinates `protobuf:"bytes,8,rep,name=boundingBox,proto3" json:"boundingBox,omitempty"`
+}
+
+func (x *Place) Reset() {
+	*x = Place{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vogelnest_data_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Place) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Place) ProtoMessage() {}
+
+func (x *Place) ProtoReflect() protoreflect.Message {
+	mi := &file_vogelnest_data_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Place.ProtoReflect.Descriptor instead.
+func (*Place) Descriptor() ([]byte, []int) {
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Place) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Place) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Place) GetFullName() string {
+	if x != nil {
+		return x.FullName
+	}
+	return ""
+}
+
+func (x *Place) GetPlaceType() string {
+	if x != nil {
+		return x.PlaceType
+	}
+	return ""
+}
+
+func (x *Place) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Place) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+func (x *Place) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Place) GetBoundingBox() []*Coordinates {
+	if x != nil {
+		return x.BoundingBox
+	}
+	return nil
+}
+
+type User struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id             int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ScreenName     string `protobuf:"bytes,2,opt,name=screenName,proto3" json:"screenName,omitempty"`
+	Name           string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	CreatedAt      string `protobuf:"bytes,4,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	FollowersCount int32  `protobuf:"varint,5,opt,name=followersCount,proto3" json:"followersCount,omitempty"`
+	FriendsCount   int32  `protobuf:"varint,6,opt,name=friendsCount,proto3" json:"friendsCount,omitempty"`
+	StatusesCount  int32  `protobuf:"varint,7,opt,name=statusesCount,proto3" json:"statusesCount,omitempty"`
+	Verified       bool   `protobuf:"varint,8,opt,name=verified,proto3" json:"verified,omitempty"`
+	Location       string `protobuf:"bytes,9,opt,name=location,proto3" json:"location,omitempty"`
+	Description    string `protobuf:"bytes,10,opt,name=description,proto3" json:"description,omitempty"`
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vogelnest_data_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_vogelnest_data_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *User) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *User) GetScreenName() string {
+	if x != nil {
+		return x.ScreenName
+	}
+	return ""
+}
+
+func (x *User) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *User) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *User) GetFollowersCount() int32 {
+	if x != nil {
+		return x.FollowersCount
+	}
+	return 0
+}
+
+func (x *User) GetFriendsCount() int32 {
+	if x != nil {
+		return x.FriendsCount
+	}
+	return 0
+}
+
+func (x *User) GetStatusesCount() int32 {
+	if x != nil {
+		return x.StatusesCount
+	}
+	return 0
+}
+
+func (x *User) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *User) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *User) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
 type Entities struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Hashtags []*Hashtag `protobuf:"bytes,1,rep,name=hashtags,proto3" json:"hashtags,omitempty"`
-	Urls     []*URLInfo `protobuf:"bytes,2,rep,name=urls,proto3" json:"urls,omitempty"`
-	Media    []*Media   `protobuf:"bytes,3,rep,name=media,proto3" json:"media,omitempty"`
-	Mentions []*Mention `protobuf:"bytes,4,rep,name=mentions,proto3" json:"mentions,omitempty"`
+	Hashtags []*Hashtag `protobuf:"bytes,1,rep,name=hashtags,proto3" json:"hashtags,omitempty"`
+	Urls     []*URLInfo `protobuf:"bytes,2,rep,name=urls,proto3" json:"urls,omitempty"`
+	Media    []*Media   `protobuf:"bytes,3,rep,name=media,proto3" json:"media,omitempty"`
+	Mentions []*Mention `protobuf:"bytes,4,rep,name=mentions,proto3" json:"mentions,omitempty"`
+	// symbols are cashtags, eg.: $TWTR
+	Symbols []*Symbol `protobuf:"bytes,5,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	Polls   []*Poll   `protobuf:"bytes,6,rep,name=polls,proto3" json:"polls,omitempty"`
+}
+
+func (x *Entities) Reset() {
+	*x = Entities{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vogelnest_data_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Entities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entities) ProtoMessage() {}
+
+func (x *Entities) ProtoReflect() protoreflect.Message {
+	mi := &file_vogelnest_data_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entities.ProtoReflect.Descriptor instead.
+func (*Entities) Descriptor() ([]byte, []int) {
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Entities) GetHashtags() []*Hashtag {
+	if x != nil {
+		return x.Hashtags
+	}
+	return nil
+}
+
+func (x *Entities) GetUrls() []*URLInfo {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+func (x *Entities) GetMedia() []*Media {
+	if x != nil {
+		return x.Media
+	}
+	return nil
+}
+
+func (x *Entities) GetMentions() []*Mention {
+	if x != nil {
+		return x.Mentions
+	}
+	return nil
+}
+
+func (x *Entities) GetSymbols() []*Symbol {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *Entities) GetPolls() []*Poll {
+	if x != nil {
+		return x.Polls
+	}
+	return nil
+}
+
+type Symbol struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Indices *Indices `protobuf:"bytes,1,opt,name=indices,proto3" json:"indices,omitempty"`
+	Text    string   `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+}
+
+func (x *Symbol) Reset() {
+	*x = Symbol{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vogelnest_data_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Symbol) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Symbol) ProtoMessage() {}
+
+func (x *Symbol) ProtoReflect() protoreflect.Message {
+	mi := &file_vogelnest_data_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Symbol.ProtoReflect.Descriptor instead.
+func (*Symbol) Descriptor() ([]byte, []int) {
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Symbol) GetIndices() *Indices {
+	if x != nil {
+		return x.Indices
+	}
+	return nil
+}
+
+func (x *Symbol) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type Poll struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Options         []*PollOption `protobuf:"bytes,1,rep,name=options,proto3" json:"options,omitempty"`
+	EndDatetime     string        `protobuf:"bytes,2,opt,name=endDatetime,proto3" json:"endDatetime,omitempty"`
+	DurationMinutes int32         `protobuf:"varint,3,opt,name=durationMinutes,proto3" json:"durationMinutes,omitempty"`
 }
 
-func (x *Entities) Reset() {
-	*x = Entities{}
+func (x *Poll) Reset() {
+	*x = Poll{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[1]
+		mi := &file_vogelnest_data_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Entities) String() string {
+func (x *Poll) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Entities) ProtoMessage() {}
+func (*Poll) ProtoMessage() {}
 
-func (x *Entities) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[1]
+func (x *Poll) ProtoReflect() protoreflect.Message {
+	mi := &file_vogelnest_data_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -190,37 +955,85 @@ func (x *Entities) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Entities.ProtoReflect.Descriptor instead.
-func (*Entities) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use Poll.ProtoReflect.Descriptor instead.
+func (*Poll) Descriptor() ([]byte, []int) {
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *Entities) GetHashtags() []*Hashtag {
+func (x *Poll) GetOptions() []*PollOption {
 	if x != nil {
-		return x.Hashtags
+		return x.Options
 	}
 	return nil
 }
 
-func (x *Entities) GetUrls() []*URLInfo {
+func (x *Poll) GetEndDatetime() string {
 	if x != nil {
-		return x.Urls
+		return x.EndDatetime
 	}
-	return nil
+	return ""
 }
 
-func (x *Entities) GetMedia() []*Media {
+func (x *Poll) GetDurationMinutes() int32 {
 	if x != nil {
-		return x.Media
+		return x.DurationMinutes
 	}
-	return nil
+	return 0
 }
 
-func (x *Entities) GetMentions() []*Mention {
+type PollOption struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Position int32  `protobuf:"varint,1,opt,name=position,proto3" json:"position,omitempty"`
+	Text     string `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+}
+
+func (x *PollOption) Reset() {
+	*x = PollOption{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_vogelnest_data_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PollOption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PollOption) ProtoMessage() {}
+
+func (x *PollOption) ProtoReflect() protoreflect.Message {
+	mi := &file_vogelnest_data_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PollOption.ProtoReflect.Descriptor instead.
+func (*PollOption) Descriptor() ([]byte, []int) {
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PollOption) GetPosition() int32 {
 	if x != nil {
-		return x.Mentions
+		return x.Position
 	}
-	return nil
+	return 0
+}
+
+func (x *PollOption) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
 }
 
 type Hashtag struct {
@@ -235,7 +1048,7 @@ type Hashtag struct {
 func (x *Hashtag) Reset() {
 	*x = Hashtag{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[2]
+		mi := &file_vogelnest_data_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -248,7 +1061,7 @@ func (x *Hashtag) String() string {
 func (*Hashtag) ProtoMessage() {}
 
 func (x *Hashtag) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[2]
+	mi := &file_vogelnest_data_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -261,7 +1074,7 @@ func (x *Hashtag) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Hashtag.ProtoReflect.Descriptor instead.
 func (*Hashtag) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{2}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *Hashtag) GetIndices() *Indices {
@@ -290,7 +1103,7 @@ type Indices struct {
 func (x *Indices) Reset() {
 	*x = Indices{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[3]
+		mi := &file_vogelnest_data_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -303,7 +1116,7 @@ func (x *Indices) String() string {
 func (*Indices) ProtoMessage() {}
 
 func (x *Indices) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[3]
+	mi := &file_vogelnest_data_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -316,7 +1129,7 @@ func (x *Indices) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Indices.ProtoReflect.Descriptor instead.
 func (*Indices) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{3}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *Indices) GetStart() int32 {
@@ -351,7 +1164,7 @@ type Media struct {
 func (x *Media) Reset() {
 	*x = Media{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[4]
+		mi := &file_vogelnest_data_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -364,7 +1177,7 @@ func (x *Media) String() string {
 func (*Media) ProtoMessage() {}
 
 func (x *Media) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[4]
+	mi := &file_vogelnest_data_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -377,7 +1190,7 @@ func (x *Media) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Media.ProtoReflect.Descriptor instead.
 func (*Media) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{4}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *Media) GetUrl() *URLInfo {
@@ -450,7 +1263,7 @@ type MediaSizes struct {
 func (x *MediaSizes) Reset() {
 	*x = MediaSizes{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[5]
+		mi := &file_vogelnest_data_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -463,7 +1276,7 @@ func (x *MediaSizes) String() string {
 func (*MediaSizes) ProtoMessage() {}
 
 func (x *MediaSizes) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[5]
+	mi := &file_vogelnest_data_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -476,7 +1289,7 @@ func (x *MediaSizes) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MediaSizes.ProtoReflect.Descriptor instead.
 func (*MediaSizes) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{5}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *MediaSizes) GetThumb() *MediaSize {
@@ -520,7 +1333,7 @@ type MediaSize struct {
 func (x *MediaSize) Reset() {
 	*x = MediaSize{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[6]
+		mi := &file_vogelnest_data_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -533,7 +1346,7 @@ func (x *MediaSize) String() string {
 func (*MediaSize) ProtoMessage() {}
 
 func (x *MediaSize) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[6]
+	mi := &file_vogelnest_data_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -546,7 +1359,7 @@ func (x *MediaSize) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MediaSize.ProtoReflect.Descriptor instead.
 func (*MediaSize) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{6}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *MediaSize) GetWidth() int32 {
@@ -584,7 +1397,7 @@ type URLInfo struct {
 func (x *URLInfo) Reset() {
 	*x = URLInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[7]
+		mi := &file_vogelnest_data_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -597,7 +1410,7 @@ func (x *URLInfo) String() string {
 func (*URLInfo) ProtoMessage() {}
 
 func (x *URLInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[7]
+	mi := &file_vogelnest_data_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -610,7 +1423,7 @@ func (x *URLInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use URLInfo.ProtoReflect.Descriptor instead.
 func (*URLInfo) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{7}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *URLInfo) GetIndices() *Indices {
@@ -646,15 +1459,16 @@ type Mention struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id         int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name       string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	ScreenName string `protobuf:"bytes,3,opt,name=screenName,proto3" json:"screenName,omitempty"`
+	Id         int64    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name       string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ScreenName string   `protobuf:"bytes,3,opt,name=screenName,proto3" json:"screenName,omitempty"`
+	Indices    *Indices `protobuf:"bytes,4,opt,name=indices,proto3" json:"indices,omitempty"`
 }
 
 func (x *Mention) Reset() {
 	*x = Mention{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[8]
+		mi := &file_vogelnest_data_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -667,7 +1481,7 @@ func (x *Mention) String() string {
 func (*Mention) ProtoMessage() {}
 
 func (x *Mention) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[8]
+	mi := &file_vogelnest_data_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -680,7 +1494,7 @@ func (x *Mention) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Mention.ProtoReflect.Descriptor instead.
 func (*Mention) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{8}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *Mention) GetId() int64 {
@@ -704,6 +1518,13 @@ func (x *Mention) GetScreenName() string {
 	return ""
 }
 
+func (x *Mention) GetIndices() *Indices {
+	if x != nil {
+		return x.Indices
+	}
+	return nil
+}
+
 type VideoInfo struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -717,7 +1538,7 @@ type VideoInfo struct {
 func (x *VideoInfo) Reset() {
 	*x = VideoInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[9]
+		mi := &file_vogelnest_data_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -730,7 +1551,7 @@ func (x *VideoInfo) String() string {
 func (*VideoInfo) ProtoMessage() {}
 
 func (x *VideoInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[9]
+	mi := &file_vogelnest_data_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -743,7 +1564,7 @@ func (x *VideoInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VideoInfo.ProtoReflect.Descriptor instead.
 func (*VideoInfo) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{9}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *VideoInfo) GetAspectRatio() *AspectRatio {
@@ -780,7 +1601,7 @@ type VideoVariant struct {
 func (x *VideoVariant) Reset() {
 	*x = VideoVariant{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[10]
+		mi := &file_vogelnest_data_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -793,7 +1614,7 @@ func (x *VideoVariant) String() string {
 func (*VideoVariant) ProtoMessage() {}
 
 func (x *VideoVariant) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[10]
+	mi := &file_vogelnest_data_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -806,7 +1627,7 @@ func (x *VideoVariant) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VideoVariant.ProtoReflect.Descriptor instead.
 func (*VideoVariant) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{10}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *VideoVariant) GetContentType() string {
@@ -842,7 +1663,7 @@ type AspectRatio struct {
 func (x *AspectRatio) Reset() {
 	*x = AspectRatio{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[11]
+		mi := &file_vogelnest_data_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -855,7 +1676,7 @@ func (x *AspectRatio) String() string {
 func (*AspectRatio) ProtoMessage() {}
 
 func (x *AspectRatio) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[11]
+	mi := &file_vogelnest_data_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -868,7 +1689,7 @@ func (x *AspectRatio) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AspectRatio.ProtoReflect.Descriptor instead.
 func (*AspectRatio) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{11}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *AspectRatio) GetWidth() int32 {
@@ -898,7 +1719,7 @@ type WitheldInfo struct {
 func (x *WitheldInfo) Reset() {
 	*x = WitheldInfo{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[12]
+		mi := &file_vogelnest_data_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -911,7 +1732,7 @@ func (x *WitheldInfo) String() string {
 func (*WitheldInfo) ProtoMessage() {}
 
 func (x *WitheldInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[12]
+	mi := &file_vogelnest_data_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -924,7 +1745,7 @@ func (x *WitheldInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WitheldInfo.ProtoReflect.Descriptor instead.
 func (*WitheldInfo) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{12}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *WitheldInfo) GetWithheldCopyright() bool {
@@ -953,16 +1774,17 @@ type TweetStats struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	QuoteCount   int32 `protobuf:"varint,1,opt,name=quoteCount,proto3" json:"quoteCount,omitempty"`
-	ReplyCount   int32 `protobuf:"varint,2,opt,name=replyCount,proto3" json:"replyCount,omitempty"`
-	RetweetCount int32 `protobuf:"varint,3,opt,name=retweetCount,proto3" json:"retweetCount,omitempty"`
-	Retweeted    bool  `protobuf:"varint,4,opt,name=retweeted,proto3" json:"retweeted,omitempty"`
+	QuoteCount    int32 `protobuf:"varint,1,opt,name=quoteCount,proto3" json:"quoteCount,omitempty"`
+	ReplyCount    int32 `protobuf:"varint,2,opt,name=replyCount,proto3" json:"replyCount,omitempty"`
+	RetweetCount  int32 `protobuf:"varint,3,opt,name=retweetCount,proto3" json:"retweetCount,omitempty"`
+	Retweeted     bool  `protobuf:"varint,4,opt,name=retweeted,proto3" json:"retweeted,omitempty"`
+	FavoriteCount int32 `protobuf:"varint,5,opt,name=favoriteCount,proto3" json:"favoriteCount,omitempty"`
 }
 
 func (x *TweetStats) Reset() {
 	*x = TweetStats{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[13]
+		mi := &file_vogelnest_data_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -975,7 +1797,7 @@ func (x *TweetStats) String() string {
 func (*TweetStats) ProtoMessage() {}
 
 func (x *TweetStats) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[13]
+	mi := &file_vogelnest_data_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -988,7 +1810,7 @@ func (x *TweetStats) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TweetStats.ProtoReflect.Descriptor instead.
 func (*TweetStats) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{13}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *TweetStats) GetQuoteCount() int32 {
@@ -1019,6 +1841,13 @@ func (x *TweetStats) GetRetweeted() bool {
 	return false
 }
 
+func (x *TweetStats) GetFavoriteCount() int32 {
+	if x != nil {
+		return x.FavoriteCount
+	}
+	return 0
+}
+
 type Coordinates struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1032,7 +1861,7 @@ type Coordinates struct {
 func (x *Coordinates) Reset() {
 	*x = Coordinates{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_vogelnest_data_proto_msgTypes[14]
+		mi := &file_vogelnest_data_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1045,7 +1874,7 @@ func (x *Coordinates) String() string {
 func (*Coordinates) ProtoMessage() {}
 
 func (x *Coordinates) ProtoReflect() protoreflect.Message {
-	mi := &file_vogelnest_data_proto_msgTypes[14]
+	mi := &file_vogelnest_data_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1058,7 +1887,7 @@ func (x *Coordinates) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Coordinates.ProtoReflect.Descriptor instead.
 func (*Coordinates) Descriptor() ([]byte, []int) {
-	return file_vogelnest_data_proto_rawDescGZIP(), []int{14}
+	return file_vogelnest_data_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *Coordinates) GetLat() float64 {
@@ -1086,7 +1915,7 @@ var File_vogelnest_data_proto protoreflect.FileDescriptor
 
 var file_vogelnest_data_proto_rawDesc = []byte{
 	0x0a, 0x14, 0x76, 0x6f, 0x67, 0x65, 0x6c, 0x6e, 0x65, 0x73, 0x74, 0x2d, 0x64, 0x61, 0x74, 0x61,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xfb, 0x02, 0x0a, 0x05, 0x54, 0x77, 0x65, 0x65, 0x74,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb3, 0x07, 0x0a, 0x05, 0x54, 0x77, 0x65, 0x65, 0x74,
 	0x12, 0x2e, 0x0a, 0x0b, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x73, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61,
 	0x74, 0x65, 0x73, 0x52, 0x0b, 0x63, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x73,
@@ -1110,113 +1939,230 @@ var file_vogelnest_data_proto_rawDesc = []byte{
 	0x0b, 0x32, 0x09, 0x2e, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x52, 0x08, 0x65, 0x6e,
 	0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
 	0x64, 0x41, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x64, 0x41, 0x74, 0x22, 0x92, 0x01, 0x0a, 0x08, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65,
-	0x73, 0x12, 0x24, 0x0a, 0x08, 0x68, 0x61, 0x73, 0x68, 0x74, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x74, 0x61, 0x67, 0x52, 0x08, 0x68,
-	0x61, 0x73, 0x68, 0x74, 0x61, 0x67, 0x73, 0x12, 0x1c, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18,
-	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x55, 0x52, 0x4c, 0x49, 0x6e, 0x66, 0x6f, 0x52,
-	0x04, 0x75, 0x72, 0x6c, 0x73, 0x12, 0x1c, 0x0a, 0x05, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x18, 0x03,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x52, 0x05, 0x6d, 0x65,
-	0x64, 0x69, 0x61, 0x12, 0x24, 0x0a, 0x08, 0x6d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
-	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x4d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x52,
-	0x08, 0x6d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x41, 0x0a, 0x07, 0x48, 0x61, 0x73,
-	0x68, 0x74, 0x61, 0x67, 0x12, 0x22, 0x0a, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x52,
-	0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x22, 0x31, 0x0a, 0x07,
-	0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x10, 0x0a,
-	0x03, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x65, 0x6e, 0x64, 0x22,
-	0xfc, 0x01, 0x0a, 0x05, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x12, 0x1a, 0x0a, 0x03, 0x75, 0x72, 0x6c,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x55, 0x52, 0x4c, 0x49, 0x6e, 0x66, 0x6f,
-	0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x55, 0x72,
-	0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x55, 0x72,
-	0x6c, 0x12, 0x24, 0x0a, 0x0d, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x55, 0x72, 0x6c, 0x48, 0x74, 0x74,
-	0x70, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x55,
-	0x72, 0x6c, 0x48, 0x74, 0x74, 0x70, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x73, 0x6f, 0x75, 0x72, 0x63,
-	0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x49, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x0e, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x49, 0x64, 0x12,
-	0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
-	0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0b, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x52, 0x04,
-	0x73, 0x69, 0x7a, 0x65, 0x12, 0x28, 0x0a, 0x09, 0x76, 0x69, 0x64, 0x65, 0x6f, 0x49, 0x6e, 0x66,
-	0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x56, 0x69, 0x64, 0x65, 0x6f, 0x49,
-	0x6e, 0x66, 0x6f, 0x52, 0x09, 0x76, 0x69, 0x64, 0x65, 0x6f, 0x49, 0x6e, 0x66, 0x6f, 0x22, 0x96,
-	0x01, 0x0a, 0x0a, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x12, 0x20, 0x0a,
-	0x05, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x4d,
-	0x65, 0x64, 0x69, 0x61, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x05, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x12,
-	0x20, 0x0a, 0x05, 0x73, 0x6d, 0x61, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a,
-	0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x05, 0x73, 0x6d, 0x61, 0x6c,
-	0x6c, 0x12, 0x22, 0x0a, 0x06, 0x6d, 0x65, 0x64, 0x69, 0x75, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0a, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x06, 0x6d,
-	0x65, 0x64, 0x69, 0x75, 0x6d, 0x12, 0x20, 0x0a, 0x05, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x69, 0x7a, 0x65,
-	0x52, 0x05, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x22, 0x51, 0x0a, 0x09, 0x4d, 0x65, 0x64, 0x69, 0x61,
-	0x53, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65,
-	0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67,
-	0x68, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x81, 0x01, 0x0a, 0x07, 0x55,
-	0x52, 0x4c, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x22, 0x0a, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65,
+	0x65, 0x64, 0x41, 0x74, 0x12, 0x19, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x05, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12,
+	0x2c, 0x0a, 0x11, 0x69, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x54, 0x6f, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x49, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x69, 0x6e, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x54, 0x6f, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x49, 0x64, 0x12, 0x28, 0x0a,
+	0x0f, 0x69, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x54, 0x6f, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x69, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x54,
+	0x6f, 0x55, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x30, 0x0a, 0x13, 0x69, 0x6e, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x54, 0x6f, 0x53, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x69, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x54, 0x6f, 0x53,
+	0x63, 0x72, 0x65, 0x65, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x05, 0x70, 0x6c, 0x61,
+	0x63, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x50, 0x6c, 0x61, 0x63, 0x65,
+	0x52, 0x05, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12,
+	0x26, 0x0a, 0x0e, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x49,
+	0x64, 0x18, 0x12, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x64, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x49, 0x64, 0x12, 0x34, 0x0a, 0x10, 0x64, 0x69, 0x73, 0x70, 0x6c,
+	0x61, 0x79, 0x54, 0x65, 0x78, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x13, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x08, 0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x52, 0x10, 0x64, 0x69, 0x73,
+	0x70, 0x6c, 0x61, 0x79, 0x54, 0x65, 0x78, 0x74, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x14, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x28, 0x0a, 0x09, 0x73, 0x65, 0x6e, 0x74, 0x69, 0x6d, 0x65,
+	0x6e, 0x74, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x53, 0x65, 0x6e, 0x74, 0x69,
+	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x73, 0x65, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x34, 0x0a, 0x0d, 0x6c, 0x61, 0x6e, 0x67, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x4c, 0x61, 0x6e, 0x67, 0x44, 0x65, 0x74,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x6c, 0x61, 0x6e, 0x67, 0x44, 0x65, 0x74, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73,
+	0x18, 0x17, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73, 0x12,
+	0x28, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x18, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x73, 0x52,
+	0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67,
+	0x73, 0x18, 0x19, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x2d, 0x0a,
+	0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x1a, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x0b, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x34, 0x0a, 0x0a,
+	0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x22, 0x5f, 0x0a, 0x0d, 0x4c, 0x61, 0x6e, 0x67, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x61, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6c, 0x61, 0x6e, 0x67, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6c, 0x69, 0x61,
+	0x62, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x6c, 0x69, 0x61,
+	0x62, 0x6c, 0x65, 0x22, 0xb3, 0x01, 0x0a, 0x09, 0x53, 0x65, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x6e,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x61, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6c, 0x61, 0x6e, 0x67, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x75, 0x6e,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x75, 0x6e,
+	0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x76, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x6e, 0x65, 0x67, 0x61, 0x74, 0x69, 0x76, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x08, 0x6e, 0x65, 0x67, 0x61, 0x74, 0x69, 0x76, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x75,
+	0x74, 0x72, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x6e, 0x65, 0x75, 0x74,
+	0x72, 0x61, 0x6c, 0x12, 0x24, 0x0a, 0x08, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x45, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x08, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x33, 0x0a, 0x07, 0x45, 0x6d, 0x6f,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x22, 0xe3,
+	0x01, 0x0a, 0x05, 0x50, 0x6c, 0x61, 0x63, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08,
+	0x66, 0x75, 0x6c, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x66, 0x75, 0x6c, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x63,
+	0x65, 0x54, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x6c, 0x61,
+	0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72,
+	0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x43, 0x6f, 0x64, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x79, 0x43, 0x6f,
+	0x64, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x75, 0x72, 0x6c, 0x12, 0x2e, 0x0a, 0x0b, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67,
+	0x42, 0x6f, 0x78, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x43, 0x6f, 0x6f, 0x72,
+	0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x73, 0x52, 0x0b, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x42, 0x6f, 0x78, 0x22, 0xb4, 0x02, 0x0a, 0x04, 0x55, 0x73, 0x65, 0x72, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1e, 0x0a,
+	0x0a, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12,
+	0x26, 0x0a, 0x0e, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x72, 0x73, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x65,
+	0x72, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x66, 0x72, 0x69, 0x65, 0x6e,
+	0x64, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x66,
+	0x72, 0x69, 0x65, 0x6e, 0x64, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0d, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x08, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x12, 0x1a, 0x0a,
+	0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xd2, 0x01, 0x0a, 0x08,
+	0x45, 0x6e, 0x74, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x08, 0x68, 0x61, 0x73, 0x68,
+	0x74, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x48, 0x61, 0x73,
+	0x68, 0x74, 0x61, 0x67, 0x52, 0x08, 0x68, 0x61, 0x73, 0x68, 0x74, 0x61, 0x67, 0x73, 0x12, 0x1c,
+	0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x55,
+	0x52, 0x4c, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x12, 0x1c, 0x0a, 0x05,
+	0x6d, 0x65, 0x64, 0x69, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x4d, 0x65,
+	0x64, 0x69, 0x61, 0x52, 0x05, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x12, 0x24, 0x0a, 0x08, 0x6d, 0x65,
+	0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x4d,
+	0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x6d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x21, 0x0a, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x07, 0x2e, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x52, 0x07, 0x73, 0x79, 0x6d, 0x62,
+	0x6f, 0x6c, 0x73, 0x12, 0x1b, 0x0a, 0x05, 0x70, 0x6f, 0x6c, 0x6c, 0x73, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x05, 0x2e, 0x50, 0x6f, 0x6c, 0x6c, 0x52, 0x05, 0x70, 0x6f, 0x6c, 0x6c, 0x73,
+	0x22, 0x40, 0x0a, 0x06, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x22, 0x0a, 0x07, 0x69, 0x6e,
+	0x64, 0x69, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x49, 0x6e,
+	0x64, 0x69, 0x63, 0x65, 0x73, 0x52, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65,
+	0x78, 0x74, 0x22, 0x79, 0x0a, 0x04, 0x50, 0x6f, 0x6c, 0x6c, 0x12, 0x25, 0x0a, 0x07, 0x6f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x50, 0x6f,
+	0x6c, 0x6c, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x20, 0x0a, 0x0b, 0x65, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x65, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x65, 0x74,
+	0x69, 0x6d, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
+	0x69, 0x6e, 0x75, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x69, 0x6e, 0x75, 0x74, 0x65, 0x73, 0x22, 0x3c, 0x0a,
+	0x0a, 0x50, 0x6f, 0x6c, 0x6c, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x22, 0x41, 0x0a, 0x07, 0x48,
+	0x61, 0x73, 0x68, 0x74, 0x61, 0x67, 0x12, 0x22, 0x0a, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65,
 	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65,
-	0x73, 0x52, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x69,
-	0x73, 0x70, 0x6c, 0x61, 0x79, 0x55, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
-	0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x55, 0x72, 0x6c, 0x12, 0x20, 0x0a, 0x0b, 0x65, 0x78,
-	0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x55, 0x72, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0b, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x55, 0x72, 0x6c, 0x12, 0x10, 0x0a, 0x03,
-	0x75, 0x72, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x22, 0x4d,
-	0x0a, 0x07, 0x4d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a,
-	0x0a, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0a, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x8e, 0x01,
-	0x0a, 0x09, 0x56, 0x69, 0x64, 0x65, 0x6f, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x2e, 0x0a, 0x0b, 0x61,
-	0x73, 0x70, 0x65, 0x63, 0x74, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x0c, 0x2e, 0x41, 0x73, 0x70, 0x65, 0x63, 0x74, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x52, 0x0b,
-	0x61, 0x73, 0x70, 0x65, 0x63, 0x74, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x26, 0x0a, 0x0e, 0x64,
-	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x0e, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x69, 0x6c,
-	0x6c, 0x69, 0x73, 0x12, 0x29, 0x0a, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73, 0x18,
-	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x56, 0x69, 0x64, 0x65, 0x6f, 0x56, 0x61, 0x72,
-	0x69, 0x61, 0x6e, 0x74, 0x52, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73, 0x22, 0x5c,
-	0x0a, 0x0c, 0x56, 0x69, 0x64, 0x65, 0x6f, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x12, 0x20,
-	0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65,
-	0x12, 0x18, 0x0a, 0x07, 0x62, 0x69, 0x74, 0x72, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x07, 0x62, 0x69, 0x74, 0x72, 0x61, 0x74, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72,
-	0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x22, 0x3b, 0x0a, 0x0b,
-	0x41, 0x73, 0x70, 0x65, 0x63, 0x74, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x77,
-	0x69, 0x64, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74,
-	0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x93, 0x01, 0x0a, 0x0b, 0x57, 0x69,
-	0x74, 0x68, 0x65, 0x6c, 0x64, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x2c, 0x0a, 0x11, 0x77, 0x69, 0x74,
-	0x68, 0x68, 0x65, 0x6c, 0x64, 0x43, 0x6f, 0x70, 0x79, 0x72, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x77, 0x69, 0x74, 0x68, 0x68, 0x65, 0x6c, 0x64, 0x43, 0x6f,
-	0x70, 0x79, 0x72, 0x69, 0x67, 0x68, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x77, 0x69, 0x74, 0x68, 0x68,
-	0x65, 0x6c, 0x64, 0x53, 0x63, 0x6f, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
-	0x77, 0x69, 0x74, 0x68, 0x68, 0x65, 0x6c, 0x64, 0x53, 0x63, 0x6f, 0x70, 0x65, 0x12, 0x30, 0x0a,
-	0x13, 0x77, 0x69, 0x74, 0x68, 0x68, 0x65, 0x6c, 0x64, 0x49, 0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74,
-	0x72, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x13, 0x77, 0x69, 0x74, 0x68,
-	0x68, 0x65, 0x6c, 0x64, 0x49, 0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22,
-	0x8e, 0x01, 0x0a, 0x0a, 0x54, 0x77, 0x65, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1e,
-	0x0a, 0x0a, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x0a, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1e,
-	0x0a, 0x0a, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x0a, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x22,
-	0x0a, 0x0c, 0x72, 0x65, 0x74, 0x77, 0x65, 0x65, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x72, 0x65, 0x74, 0x77, 0x65, 0x65, 0x74, 0x43, 0x6f, 0x75,
-	0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x74, 0x77, 0x65, 0x65, 0x74, 0x65, 0x64, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x72, 0x65, 0x74, 0x77, 0x65, 0x65, 0x74, 0x65, 0x64,
-	0x22, 0x47, 0x0a, 0x0b, 0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x73, 0x12,
-	0x10, 0x0a, 0x03, 0x6c, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61,
-	0x74, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x6f, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
-	0x04, 0x6c, 0x6f, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x42, 0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74,
-	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x6e, 0x64, 0x72, 0x65, 0x62, 0x71, 0x2f,
-	0x76, 0x6f, 0x67, 0x65, 0x6c, 0x6e, 0x65, 0x73, 0x74, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e,
-	0x61, 0x6c, 0x2f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x73, 0x52, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65,
+	0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x22, 0x31,
+	0x0a, 0x07, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12,
+	0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x65, 0x6e,
+	0x64, 0x22, 0xfc, 0x01, 0x0a, 0x05, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x12, 0x1a, 0x0a, 0x03, 0x75,
+	0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x55, 0x52, 0x4c, 0x49, 0x6e,
+	0x66, 0x6f, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61,
+	0x55, 0x72, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x65, 0x64, 0x69, 0x61,
+	0x55, 0x72, 0x6c, 0x12, 0x24, 0x0a, 0x0d, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x55, 0x72, 0x6c, 0x48,
+	0x74, 0x74, 0x70, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6d, 0x65, 0x64, 0x69,
+	0x61, 0x55, 0x72, 0x6c, 0x48, 0x74, 0x74, 0x70, 0x73, 0x12, 0x26, 0x0a, 0x0e, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x49, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0e, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x49,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x69, 0x7a, 0x65, 0x73,
+	0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x28, 0x0a, 0x09, 0x76, 0x69, 0x64, 0x65, 0x6f, 0x49,
+	0x6e, 0x66, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x56, 0x69, 0x64, 0x65,
+	0x6f, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x76, 0x69, 0x64, 0x65, 0x6f, 0x49, 0x6e, 0x66, 0x6f,
+	0x22, 0x96, 0x01, 0x0a, 0x0a, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x12,
+	0x20, 0x0a, 0x05, 0x74, 0x68, 0x75, 0x6d, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a,
+	0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x05, 0x74, 0x68, 0x75, 0x6d,
+	0x62, 0x12, 0x20, 0x0a, 0x05, 0x73, 0x6d, 0x61, 0x6c, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0a, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x05, 0x73, 0x6d,
+	0x61, 0x6c, 0x6c, 0x12, 0x22, 0x0a, 0x06, 0x6d, 0x65, 0x64, 0x69, 0x75, 0x6d, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x69, 0x7a, 0x65, 0x52,
+	0x06, 0x6d, 0x65, 0x64, 0x69, 0x75, 0x6d, 0x12, 0x20, 0x0a, 0x05, 0x6c, 0x61, 0x72, 0x67, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x69,
+	0x7a, 0x65, 0x52, 0x05, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x22, 0x51, 0x0a, 0x09, 0x4d, 0x65, 0x64,
+	0x69, 0x61, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06,
+	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x68, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x81, 0x01, 0x0a,
+	0x07, 0x55, 0x52, 0x4c, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x22, 0x0a, 0x07, 0x69, 0x6e, 0x64, 0x69,
+	0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x49, 0x6e, 0x64, 0x69,
+	0x63, 0x65, 0x73, 0x52, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x12, 0x1e, 0x0a, 0x0a,
+	0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x55, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x64, 0x69, 0x73, 0x70, 0x6c, 0x61, 0x79, 0x55, 0x72, 0x6c, 0x12, 0x20, 0x0a, 0x0b,
+	0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x55, 0x72, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x64, 0x55, 0x72, 0x6c, 0x12, 0x10,
+	0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c,
+	0x22, 0x71, 0x0a, 0x07, 0x4d, 0x65, 0x6e, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x1e, 0x0a, 0x0a, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x63, 0x72, 0x65, 0x65, 0x6e, 0x4e, 0x61, 0x6d, 0x65, 0x12,
+	0x22, 0x0a, 0x07, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x08, 0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x65, 0x73, 0x52, 0x07, 0x69, 0x6e, 0x64, 0x69,
+	0x63, 0x65, 0x73, 0x22, 0x8e, 0x01, 0x0a, 0x09, 0x56, 0x69, 0x64, 0x65, 0x6f, 0x49, 0x6e, 0x66,
+	0x6f, 0x12, 0x2e, 0x0a, 0x0b, 0x61, 0x73, 0x70, 0x65, 0x63, 0x74, 0x52, 0x61, 0x74, 0x69, 0x6f,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x41, 0x73, 0x70, 0x65, 0x63, 0x74, 0x52,
+	0x61, 0x74, 0x69, 0x6f, 0x52, 0x0b, 0x61, 0x73, 0x70, 0x65, 0x63, 0x74, 0x52, 0x61, 0x74, 0x69,
+	0x6f, 0x12, 0x26, 0x0a, 0x0e, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x69, 0x6c,
+	0x6c, 0x69, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x64, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x29, 0x0a, 0x08, 0x76, 0x61, 0x72,
+	0x69, 0x61, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x56, 0x69,
+	0x64, 0x65, 0x6f, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x52, 0x08, 0x76, 0x61, 0x72, 0x69,
+	0x61, 0x6e, 0x74, 0x73, 0x22, 0x5c, 0x0a, 0x0c, 0x56, 0x69, 0x64, 0x65, 0x6f, 0x56, 0x61, 0x72,
+	0x69, 0x61, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54,
+	0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x69, 0x74, 0x72, 0x61, 0x74,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x62, 0x69, 0x74, 0x72, 0x61, 0x74, 0x65,
+	0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75,
+	0x72, 0x6c, 0x22, 0x3b, 0x0a, 0x0b, 0x41, 0x73, 0x70, 0x65, 0x63, 0x74, 0x52, 0x61, 0x74, 0x69,
+	0x6f, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22,
+	0x93, 0x01, 0x0a, 0x0b, 0x57, 0x69, 0x74, 0x68, 0x65, 0x6c, 0x64, 0x49, 0x6e, 0x66, 0x6f, 0x12,
+	0x2c, 0x0a, 0x11, 0x77, 0x69, 0x74, 0x68, 0x68, 0x65, 0x6c, 0x64, 0x43, 0x6f, 0x70, 0x79, 0x72,
+	0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x77, 0x69, 0x74, 0x68,
+	0x68, 0x65, 0x6c, 0x64, 0x43, 0x6f, 0x70, 0x79, 0x72, 0x69, 0x67, 0x68, 0x74, 0x12, 0x24, 0x0a,
+	0x0d, 0x77, 0x69, 0x74, 0x68, 0x68, 0x65, 0x6c, 0x64, 0x53, 0x63, 0x6f, 0x70, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x77, 0x69, 0x74, 0x68, 0x68, 0x65, 0x6c, 0x64, 0x53, 0x63,
+	0x6f, 0x70, 0x65, 0x12, 0x30, 0x0a, 0x13, 0x77, 0x69, 0x74, 0x68, 0x68, 0x65, 0x6c, 0x64, 0x49,
+	0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x13, 0x77, 0x69, 0x74, 0x68, 0x68, 0x65, 0x6c, 0x64, 0x49, 0x6e, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0xb4, 0x01, 0x0a, 0x0a, 0x54, 0x77, 0x65, 0x65, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x43, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x72, 0x65, 0x74, 0x77, 0x65, 0x65, 0x74, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x72, 0x65, 0x74, 0x77,
+	0x65, 0x65, 0x74, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x74, 0x77,
+	0x65, 0x65, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x72, 0x65, 0x74,
+	0x77, 0x65, 0x65, 0x74, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0d, 0x66, 0x61, 0x76, 0x6f, 0x72, 0x69,
+	0x74, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x66,
+	0x61, 0x76, 0x6f, 0x72, 0x69, 0x74, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x47, 0x0a, 0x0b,
+	0x43, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x6c,
+	0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6c, 0x61, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x6c, 0x6f, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x6c, 0x6f, 0x6e,
+	0x67, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x42, 0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x6e, 0x64, 0x72, 0x65, 0x62, 0x71, 0x2f, 0x76, 0x6f, 0x67, 0x65,
+	0x6c, 0x6e, 0x65, 0x73, 0x74, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x73,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1231,51 +2177,74 @@ func file_vogelnest_data_proto_rawDescGZIP() []byte {
 	return file_vogelnest_data_proto_rawDescData
 }
 
-var file_vogelnest_data_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_vogelnest_data_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_vogelnest_data_proto_goTypes = []interface{}{
-	(*Tweet)(nil),        // 0: Tweet
-	(*Entities)(nil),     // 1: Entities
-	(*Hashtag)(nil),      // 2: Hashtag
-	(*Indices)(nil),      // 3: Indices
-	(*Media)(nil),        // 4: Media
-	(*MediaSizes)(nil),   // 5: MediaSizes
-	(*MediaSize)(nil),    // 6: MediaSize
-	(*URLInfo)(nil),      // 7: URLInfo
-	(*Mention)(nil),      // 8: Mention
-	(*VideoInfo)(nil),    // 9: VideoInfo
-	(*VideoVariant)(nil), // 10: VideoVariant
-	(*AspectRatio)(nil),  // 11: AspectRatio
-	(*WitheldInfo)(nil),  // 12: WitheldInfo
-	(*TweetStats)(nil),   // 13: TweetStats
-	(*Coordinates)(nil),  // 14: Coordinates
+	(*Tweet)(nil),         // 0: Tweet
+	(*Annotation)(nil),    // 1: Annotation
+	(*LangDetection)(nil), // 2: LangDetection
+	(*Sentiment)(nil),     // 3: Sentiment
+	(*Emotion)(nil),       // 4: Emotion
+	(*Place)(nil),         // 5: Place
+	(*User)(nil),          // 6: User
+	(*Entities)(nil),      // 7: Entities
+	(*Symbol)(nil),        // 8: Symbol
+	(*Poll)(nil),          // 9: Poll
+	(*PollOption)(nil),    // 10: PollOption
+	(*Hashtag)(nil),       // 11: Hashtag
+	(*Indices)(nil),       // 12: Indices
+	(*Media)(nil),         // 13: Media
+	(*MediaSizes)(nil),    // 14: MediaSizes
+	(*MediaSize)(nil),     // 15: MediaSize
+	(*URLInfo)(nil),       // 16: URLInfo
+	(*Mention)(nil),       // 17: Mention
+	(*VideoInfo)(nil),     // 18: VideoInfo
+	(*VideoVariant)(nil),  // 19: VideoVariant
+	(*AspectRatio)(nil),   // 20: AspectRatio
+	(*WitheldInfo)(nil),   // 21: WitheldInfo
+	(*TweetStats)(nil),    // 22: TweetStats
+	(*Coordinates)(nil),   // 23: Coordinates
 }
 var file_vogelnest_data_proto_depIdxs = []int32{
-	14, // 0: Tweet.coordinates:type_name -> Coordinates
-	13, // 1: Tweet.stats:type_name -> TweetStats
-	12, // 2: Tweet.witheld:type_name -> WitheldInfo
+	23, // 0: Tweet.coordinates:type_name -> Coordinates
+	22, // 1: Tweet.stats:type_name -> TweetStats
+	21, // 2: Tweet.witheld:type_name -> WitheldInfo
 	0,  // 3: Tweet.retweet:type_name -> Tweet
 	0,  // 4: Tweet.quotedStatus:type_name -> Tweet
-	1,  // 5: Tweet.entities:type_name -> Entities
-	2,  // 6: Entities.hashtags:type_name -> Hashtag
-	7,  // 7: Entities.urls:type_name -> URLInfo
-	4,  // 8: Entities.media:type_name -> Media
-	8,  // 9: Entities.mentions:type_name -> Mention
-	3,  // 10: Hashtag.indices:type_name -> Indices
-	7,  // 11: Media.url:type_name -> URLInfo
-	5,  // 12: Media.size:type_name -> MediaSizes
-	9,  // 13: Media.videoInfo:type_name -> VideoInfo
-	6,  // 14: MediaSizes.thumb:type_name -> MediaSize
-	6,  // 15: MediaSizes.small:type_name -> MediaSize
-	6,  // 16: MediaSizes.medium:type_name -> MediaSize
-	6,  // 17: MediaSizes.large:type_name -> MediaSize
-	3,  // 18: URLInfo.indices:type_name -> Indices
-	11, // 19: VideoInfo.aspectRatio:type_name -> AspectRatio
-	10, // 20: VideoInfo.variants:type_name -> VideoVariant
-	21, // [21:21] is the sub-list for method output_type
-	21, // [21:21] is the sub-list for method input_type
-	21, // [21:21] is the sub-list for extension type_name
-	21, // [21:21] is the sub-list for extension extendee
-	0,  // [0:21] is the sub-list for field type_name
+	7,  // 5: Tweet.entities:type_name -> Entities
+	6,  // 6: Tweet.user:type_name -> User
+	5,  // 7: Tweet.place:type_name -> Place
+	12, // 8: Tweet.displayTextRange:type_name -> Indices
+	3,  // 9: Tweet.sentiment:type_name -> Sentiment
+	2,  // 10: Tweet.langDetection:type_name -> LangDetection
+	23, // 11: Tweet.location:type_name -> Coordinates
+	1,  // 12: Tweet.annotations:type_name -> Annotation
+	4,  // 13: Sentiment.emotions:type_name -> Emotion
+	23, // 14: Place.boundingBox:type_name -> Coordinates
+	11, // 15: Entities.hashtags:type_name -> Hashtag
+	16, // 16: Entities.urls:type_name -> URLInfo
+	13, // 17: Entities.media:type_name -> Media
+	17, // 18: Entities.mentions:type_name -> Mention
+	8,  // 19: Entities.symbols:type_name -> Symbol
+	9,  // 20: Entities.polls:type_name -> Poll
+	12, // 21: Symbol.indices:type_name -> Indices
+	10, // 22: Poll.options:type_name -> PollOption
+	12, // 23: Hashtag.indices:type_name -> Indices
+	16, // 24: Media.url:type_name -> URLInfo
+	14, // 25: Media.size:type_name -> MediaSizes
+	18, // 26: Media.videoInfo:type_name -> VideoInfo
+	15, // 27: MediaSizes.thumb:type_name -> MediaSize
+	15, // 28: MediaSizes.small:type_name -> MediaSize
+	15, // 29: MediaSizes.medium:type_name -> MediaSize
+	15, // 30: MediaSizes.large:type_name -> MediaSize
+	12, // 31: URLInfo.indices:type_name -> Indices
+	12, // 32: Mention.indices:type_name -> Indices
+	20, // 33: VideoInfo.aspectRatio:type_name -> AspectRatio
+	19, // 34: VideoInfo.variants:type_name -> VideoVariant
+	35, // [35:35] is the sub-list for method output_type
+	35, // [35:35] is the sub-list for method input_type
+	35, // [35:35] is the sub-list for extension type_name
+	35, // [35:35] is the sub-list for extension extendee
+	0,  // [0:35] is the sub-list for field type_name
 }
 
 func init() { file_vogelnest_data_proto_init() }
@@ -1297,7 +2266,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Entities); i {
+			switch v := v.(*Annotation); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1309,7 +2278,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Hashtag); i {
+			switch v := v.(*LangDetection); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1321,7 +2290,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Indices); i {
+			switch v := v.(*Sentiment); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1333,7 +2302,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Media); i {
+			switch v := v.(*Emotion); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1345,7 +2314,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MediaSizes); i {
+			switch v := v.(*Place); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1357,7 +2326,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MediaSize); i {
+			switch v := v.(*User); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1369,7 +2338,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*URLInfo); i {
+			switch v := v.(*Entities); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1381,7 +2350,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Mention); i {
+			switch v := v.(*Symbol); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1393,7 +2362,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*VideoInfo); i {
+			switch v := v.(*Poll); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1405,7 +2374,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*VideoVariant); i {
+			switch v := v.(*PollOption); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1417,7 +2386,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AspectRatio); i {
+			switch v := v.(*Hashtag); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1429,7 +2398,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WitheldInfo); i {
+			switch v := v.(*Indices); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1441,7 +2410,7 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TweetStats); i {
+			switch v := v.(*Media); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1453,6 +2422,114 @@ func file_vogelnest_data_proto_init() {
 			}
 		}
 		file_vogelnest_data_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MediaSizes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vogelnest_data_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MediaSize); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vogelnest_data_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*URLInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vogelnest_data_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Mention); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vogelnest_data_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VideoInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vogelnest_data_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VideoVariant); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vogelnest_data_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AspectRatio); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vogelnest_data_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WitheldInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vogelnest_data_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TweetStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_vogelnest_data_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Coordinates); i {
 			case 0:
 				return &v.state
@@ -1471,7 +2548,7 @@ func file_vogelnest_data_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_vogelnest_data_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   15,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
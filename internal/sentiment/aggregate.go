@@ -0,0 +1,176 @@
+package sentiment
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/andrebq/vogelnest/internal/text"
+)
+
+type (
+	// Aggregator keeps the average sentiment of tweets per hashtag,
+	// symbol and time bucket, only the most recent buckets are kept
+	Aggregator struct {
+		sync.Mutex
+		size    time.Duration
+		keep    int
+		buckets []*bucket
+	}
+
+	// Summary is the average sentiment of the tweets in one bucket
+	Summary struct {
+		Start    time.Time          `json:"start"`
+		Tweets   int                `json:"tweets"`
+		Compound float64            `json:"compound"`
+		Positive float64            `json:"positive"`
+		Negative float64            `json:"negative"`
+		Neutral  float64            `json:"neutral"`
+		Emotions map[string]float64 `json:"emotions,omitempty"`
+	}
+
+	bucket struct {
+		start time.Time
+		// tags has the sums for each hashtag and symbol ($ followed
+		// by the symbol), the empty tag has the sums of every tweet
+		tags map[string]*Summary
+	}
+)
+
+// NewAggregator returns an aggregator which keeps the last keep buckets
+// of the given size
+func NewAggregator(size time.Duration, keep int) *Aggregator {
+	if size <= 0 {
+		size = time.Hour
+	}
+	if keep <= 0 {
+		keep = 1
+	}
+	return &Aggregator{size: size, keep: keep}
+}
+
+// Add the sentiment of t to the bucket of its creation time,
+// tweets without sentiment are ignored
+func (a *Aggregator) Add(t *schema.Tweet) {
+	if t.Sentiment == nil {
+		return
+	}
+	moment, err := time.Parse(time.RFC3339, t.CreatedAt)
+	if err != nil {
+		moment = time.Now()
+	}
+	content := t
+	if t.Retweet != nil {
+		content = t.Retweet
+	}
+	tags := []string{""}
+	for _, h := range content.GetEntities().GetHashtags() {
+		tags = append(tags, text.Normalize(h.Text))
+	}
+	for _, s := range content.GetEntities().GetSymbols() {
+		tags = append(tags, "$"+text.Normalize(s.Text))
+	}
+
+	a.Lock()
+	defer a.Unlock()
+	b := a.bucket(moment.Truncate(a.size))
+	if b == nil {
+		return
+	}
+	for _, tag := range tags {
+		s, ok := b.tags[tag]
+		if !ok {
+			s = &Summary{Start: b.start}
+			b.tags[tag] = s
+		}
+		s.add(t.Sentiment)
+	}
+}
+
+// bucket returns the bucket starting at start, nil if it is
+// older than every bucket kept. Callers must hold the lock
+func (a *Aggregator) bucket(start time.Time) *bucket {
+	i := sort.Search(len(a.buckets), func(i int) bool { return !a.buckets[i].start.Before(start) })
+	if i < len(a.buckets) && a.buckets[i].start.Equal(start) {
+		return a.buckets[i]
+	}
+	if i == 0 && len(a.buckets) >= a.keep {
+		return nil
+	}
+	b := &bucket{start: start, tags: make(map[string]*Summary)}
+	a.buckets = append(a.buckets, nil)
+	copy(a.buckets[i+1:], a.buckets[i:])
+	a.buckets[i] = b
+	if len(a.buckets) > a.keep {
+		a.buckets = a.buckets[len(a.buckets)-a.keep:]
+	}
+	return b
+}
+
+// Summaries returns the averages of tag in every bucket, oldest first,
+// symbols start with $ and an empty tag returns the averages of every tweet
+func (a *Aggregator) Summaries(tag string) []Summary {
+	tag = text.Normalize(tag)
+	a.Lock()
+	defer a.Unlock()
+	var out []Summary
+	for _, b := range a.buckets {
+		if s, ok := b.tags[tag]; ok {
+			out = append(out, s.average())
+		}
+	}
+	return out
+}
+
+// ServeHTTP returns the summaries of the hashtag or symbol given by ?tag
+// as a JSON list
+func (a *Aggregator) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	out := a.Summaries(req.URL.Query().Get("tag"))
+	if out == nil {
+		out = []Summary{}
+	}
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// add s to the sums kept in sum
+func (sum *Summary) add(s *schema.Sentiment) {
+	sum.Tweets++
+	sum.Compound += s.Compound
+	sum.Positive += s.Positive
+	sum.Negative += s.Negative
+	sum.Neutral += s.Neutral
+	for _, e := range s.Emotions {
+		if sum.Emotions == nil {
+			sum.Emotions = make(map[string]float64)
+		}
+		sum.Emotions[e.Name] += e.Score
+	}
+}
+
+// average returns a copy of sum divided by the number of tweets
+func (sum *Summary) average() Summary {
+	n := float64(sum.Tweets)
+	avg := Summary{
+		Start:    sum.Start,
+		Tweets:   sum.Tweets,
+		Compound: sum.Compound / n,
+		Positive: sum.Positive / n,
+		Negative: sum.Negative / n,
+		Neutral:  sum.Neutral / n,
+	}
+	for e, v := range sum.Emotions {
+		if avg.Emotions == nil {
+			avg.Emotions = make(map[string]float64)
+		}
+		avg.Emotions[e] = v / n
+	}
+	return avg
+}
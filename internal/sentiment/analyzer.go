@@ -0,0 +1,125 @@
+package sentiment
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/andrebq/vogelnest/internal/text"
+)
+
+type (
+	// Analyzer scores tweets with the lexicon of their language
+	Analyzer struct {
+		lexicons map[string]*Lexicon
+		tokens   *text.Tokenizer
+	}
+)
+
+const (
+	// alpha approximates the maximum expected sum of valences,
+	// it is used to normalize the compound score
+	alpha = 15
+	// negationFactor is applied to words after a negation
+	negationFactor = -0.74
+	// negationScope is how many words are affected by a negation
+	negationScope = 3
+)
+
+var (
+	// negations for every language with a built-in stop-word list
+	negations = map[string]struct{}{
+		"not": {}, "no": {}, "never": {}, "nor": {}, "none": {}, "nobody": {}, "nothing": {},
+		"neither": {}, "without": {}, "cannot": {},
+		"não": {}, "nunca": {}, "nem": {}, "nada": {}, "ninguém": {}, "jamais": {},
+		"ni": {}, "nadie": {},
+		"ne": {}, "pas": {}, "rien": {},
+		"nicht": {}, "kein": {}, "keine": {}, "nie": {},
+		"non": {}, "mai": {},
+	}
+)
+
+// NewAnalyzer returns an analyzer using lexicons, indexed by language
+func NewAnalyzer(lexicons map[string]*Lexicon) *Analyzer {
+	return &Analyzer{
+		lexicons: lexicons,
+		tokens:   text.NewTokenizer(text.Options{KeepStopWords: true}),
+	}
+}
+
+// Score sets the sentiment of t, tweets in a language
+// without lexicon are left unchanged
+func (a *Analyzer) Score(t *schema.Tweet) {
+	lang := t.Lang
+	if t.Retweet != nil {
+		lang = t.Retweet.Lang
+	}
+	if i := strings.IndexByte(lang, '-'); i > 0 {
+		lang = lang[:i]
+	}
+	lex, ok := a.lexicons[lang]
+	if !ok {
+		return
+	}
+	t.Sentiment = lex.Score(a.tokens.Tokens(t))
+	t.Sentiment.Lang = lang
+}
+
+// Score computes the sentiment of a list of normalized words,
+// following the approach used by VADER
+func (l *Lexicon) Score(words []string) *schema.Sentiment {
+	var sum, posSum, negSum, neutral float64
+	emotions := make(map[string]float64)
+	negatedUntil := -1
+	for i, w := range words {
+		if isNegation(w) {
+			negatedUntil = i + negationScope
+			neutral++
+			continue
+		}
+		for _, e := range l.emotions[w] {
+			emotions[e]++
+		}
+		v := l.valence[w]
+		if i <= negatedUntil {
+			v *= negationFactor
+		}
+		sum += v
+		switch {
+		case v > 0:
+			posSum += v + 1
+		case v < 0:
+			negSum += v - 1
+		default:
+			neutral++
+		}
+	}
+
+	s := &schema.Sentiment{}
+	if sum != 0 {
+		s.Compound = sum / math.Sqrt(sum*sum+alpha)
+	}
+	if total := posSum - negSum + neutral; total > 0 {
+		s.Positive = posSum / total
+		s.Negative = math.Abs(negSum) / total
+		s.Neutral = neutral / total
+	}
+	names := make([]string, 0, len(emotions))
+	for e := range emotions {
+		names = append(names, e)
+	}
+	sort.Strings(names)
+	for _, e := range names {
+		s.Emotions = append(s.Emotions, &schema.Emotion{
+			Name:  e,
+			Score: emotions[e] / float64(len(words)),
+		})
+	}
+	return s
+}
+
+func isNegation(w string) bool {
+	_, ok := negations[w]
+	return ok || strings.HasSuffix(w, "n't")
+}
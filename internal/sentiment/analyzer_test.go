@@ -0,0 +1,116 @@
+package sentiment
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+func testLexicons(t *testing.T) map[string]*Lexicon {
+	dir, err := ioutil.TempDir("", "vogelnest-sentiment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, content := range map[string]string{
+		"en.txt":          "# word\tvalence\tstddev\ngood\t2\t0.5\nGreat\t3\nbad\t-2\n😀\t2\n😢\t-2\n",
+		"en.emotions.txt": "good\tjoy\t1\ngood\ttrust\t0\nbad\tanger\t1\n",
+		"pt.txt":          "bom\t2\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lexicons, err := LoadLexicons(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lexicons) != 2 {
+		t.Fatalf("expecting en and pt got %v", lexicons)
+	}
+	return lexicons
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-4
+}
+
+func TestScore(t *testing.T) {
+	a := NewAnalyzer(testLexicons(t))
+	for _, tc := range []struct {
+		text              string
+		compound          float64
+		pos, neg, neutral float64
+		emotions          map[string]float64
+	}{
+		// 2 / sqrt(2*2 + 15)
+		{text: "good", compound: 0.458831, pos: 1, emotions: map[string]float64{"joy": 1}},
+		{text: "Good, GREAT!", compound: 0.790569, pos: 1, emotions: map[string]float64{"joy": 0.5}},
+		{text: "the cat", neutral: 1},
+		{text: "good bad", compound: 0, pos: 0.5, neg: 0.5, emotions: map[string]float64{"joy": 0.5, "anger": 0.5}},
+		// negated words are scaled by -0.74
+		{text: "not good", compound: -0.356959, neg: 2.48 / 3.48, neutral: 1 / 3.48, emotions: map[string]float64{"joy": 0.5}},
+		{text: "not bad at all", compound: 0.356959, pos: 2.48 / 5.48, neutral: 3 / 5.48, emotions: map[string]float64{"anger": 0.25}},
+		{text: "isn't great", compound: -0.497298, neg: 3.22 / 4.22, neutral: 1 / 4.22},
+		// only the next 3 words are negated
+		{text: "never good good good good", compound: -0.533041, pos: 3 / 11.44, neg: 7.44 / 11.44, neutral: 1 / 11.44, emotions: map[string]float64{"joy": 0.8}},
+		{text: "😀😀", compound: 0.718421, pos: 1},
+		{text: "so bad 😢", compound: -0.718421, neg: 6.0 / 7, neutral: 1.0 / 7, emotions: map[string]float64{"anger": 1.0 / 3}},
+	} {
+		tw := &schema.Tweet{Text: tc.text, Lang: "en"}
+		a.Score(tw)
+		s := tw.Sentiment
+		if s == nil {
+			t.Fatalf("%q: expecting a sentiment", tc.text)
+		}
+		if !near(s.Compound, tc.compound) || !near(s.Positive, tc.pos) || !near(s.Negative, tc.neg) || !near(s.Neutral, tc.neutral) {
+			t.Errorf("%q: expecting %v (pos %v, neg %v, neutral %v) got %v", tc.text, tc.compound, tc.pos, tc.neg, tc.neutral, s)
+		}
+		if len(s.Emotions) != len(tc.emotions) {
+			t.Errorf("%q: expecting emotions %v got %v", tc.text, tc.emotions, s.Emotions)
+			continue
+		}
+		for _, e := range s.Emotions {
+			if score, ok := tc.emotions[e.Name]; !ok || !near(e.Score, score) {
+				t.Errorf("%q: expecting emotions %v got %v", tc.text, tc.emotions, s.Emotions)
+			}
+		}
+	}
+}
+
+func TestScoreLanguage(t *testing.T) {
+	a := NewAnalyzer(testLexicons(t))
+	for _, tc := range []struct {
+		name     string
+		tweet    *schema.Tweet
+		lang     string
+		compound float64
+	}{
+		{name: "region", tweet: &schema.Tweet{Text: "good", Lang: "en-GB"}, lang: "en", compound: 0.458831},
+		{
+			// retweets use the language and text of the original
+			name:     "retweet",
+			tweet:    &schema.Tweet{Text: "RT good", Lang: "und", Retweet: &schema.Tweet{Text: "não é bom", Lang: "pt"}},
+			lang:     "pt",
+			compound: -0.356959,
+		},
+		{name: "without lexicon", tweet: &schema.Tweet{Text: "good", Lang: "fr"}},
+		{name: "undefined", tweet: &schema.Tweet{Text: "good"}},
+	} {
+		a.Score(tc.tweet)
+		s := tc.tweet.Sentiment
+		if tc.lang == "" {
+			if s != nil {
+				t.Errorf("%v: expecting no sentiment got %v", tc.name, s)
+			}
+			continue
+		}
+		if s == nil || s.Lang != tc.lang || !near(s.Compound, tc.compound) {
+			t.Errorf("%v: expecting %v in %v got %v", tc.name, tc.compound, tc.lang, s)
+		}
+	}
+}
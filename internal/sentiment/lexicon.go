@@ -0,0 +1,123 @@
+// Package sentiment scores tweets using word lists kept on disk,
+// nothing is sent to external services
+package sentiment
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/andrebq/vogelnest/internal/text"
+)
+
+type (
+	// Lexicon has the valence and emotions of the words of one language
+	Lexicon struct {
+		// valence goes from -4 (negative) to 4 (positive)
+		valence  map[string]float64
+		emotions map[string][]string
+	}
+)
+
+// LoadLexicons reads every lexicon in dir, indexed by language.
+//
+// Valences are read from <lang>.txt, using the VADER format: one word per
+// line followed by a tab and its mean valence, further columns are ignored.
+// Emotions are read from the optional <lang>.emotions.txt, using the NRC
+// format: word, emotion and 0 or 1 separated by tabs.
+func LoadLexicons(dir string) (map[string]*Lexicon, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]*Lexicon)
+	for _, f := range files {
+		name := filepath.Base(f)
+		if strings.HasSuffix(name, ".emotions.txt") {
+			continue
+		}
+		lang := strings.TrimSuffix(name, ".txt")
+		lex := &Lexicon{}
+		err = lex.load(f, lex.readValence)
+		if err != nil {
+			return nil, err
+		}
+		err = lex.load(filepath.Join(dir, lang+".emotions.txt"), lex.readEmotion)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		out[lang] = lex
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no lexicon found in %v", dir)
+	}
+	return out, nil
+}
+
+func (l *Lexicon) load(file string, fn func(fields []string) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return readLines(f, func(line int, fields []string) error {
+		err := fn(fields)
+		if err != nil {
+			return fmt.Errorf("%v:%v: %w", file, line, err)
+		}
+		return nil
+	})
+}
+
+func (l *Lexicon) readValence(fields []string) error {
+	if len(fields) < 2 {
+		return fmt.Errorf("expecting word and valence")
+	}
+	v, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return err
+	}
+	if l.valence == nil {
+		l.valence = make(map[string]float64)
+	}
+	l.valence[text.Normalize(fields[0])] = v
+	return nil
+}
+
+func (l *Lexicon) readEmotion(fields []string) error {
+	if len(fields) < 3 {
+		return fmt.Errorf("expecting word, emotion and association")
+	}
+	if fields[2] != "1" {
+		return nil
+	}
+	if l.emotions == nil {
+		l.emotions = make(map[string][]string)
+	}
+	w := text.Normalize(fields[0])
+	l.emotions[w] = append(l.emotions[w], fields[1])
+	return nil
+}
+
+// readLines calls fn with the tab separated fields of every line which
+// isn't empty or a comment (starting with #)
+func readLines(in io.Reader, fn func(line int, fields []string) error) error {
+	sc := bufio.NewScanner(in)
+	line := 0
+	for sc.Scan() {
+		line++
+		l := strings.TrimSpace(sc.Text())
+		if len(l) == 0 || strings.HasPrefix(l, "#") {
+			continue
+		}
+		err := fn(line, strings.Split(l, "\t"))
+		if err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
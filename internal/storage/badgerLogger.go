@@ -10,5 +10,5 @@ func (bl *badgerLogger) Infof(fmt string, args ...interface{}) {
 	bl.Logger.Info().Msgf(fmt, args...)
 }
 func (bl *badgerLogger) Debugf(fmt string, args ...interface{}) {
-	bl.Logger.Debug().Msgf(fmt, args)
+	bl.Logger.Debug().Msgf(fmt, args...)
 }
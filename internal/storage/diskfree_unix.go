@@ -0,0 +1,17 @@
+//go:build !windows
+// +build !windows
+
+package storage
+
+import "syscall"
+
+// diskFree returns how many bytes are available to unprivileged
+// users on the filesystem holding dir
+func diskFree(dir string) (int64, error) {
+	var st syscall.Statfs_t
+	err := syscall.Statfs(dir, &st)
+	if err != nil {
+		return 0, err
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/dgraph-io/badger"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// FsckOptions controls how Fsck deals with damaged entries
+	FsckOptions struct {
+		// Quarantine moves damaged entries to basedir/quarantine/<db>
+		// and removes them from the original database.
+		//
+		// It also allows badger to truncate a value log left
+		// inconsistent by a crash.
+		Quarantine bool
+
+		// Keys decrypts entries, entries encrypted with a key which
+		// isn't part of it are counted as unchecked, never as damaged
+		Keys *Keyring
+	}
+
+	// FsckReport contains the result of checking one hourly database
+	// or segment
+	FsckReport struct {
+		DB          string
+		Entries     int
+		Damaged     []FsckProblem
+		Quarantined int
+		// Unchecked entries are encrypted with keys which weren't given
+		Unchecked int
+		// Err is set when the database could not be checked at all
+		Err error
+	}
+
+	// FsckProblem describes one damaged entry
+	FsckProblem struct {
+		Key    []byte
+		Reason string
+	}
+)
+
+// Fsck walks every hourly database and segment under basedir and checks
+// if keys follow the LogEntryKey layout and values can be decoded
+// as a schema.Tweet with the same id found in the key.
+//
+// Databases which are in use by a running server cannot be opened
+// and are reported with Err set.
+func Fsck(basedir string, opts FsckOptions) ([]FsckReport, error) {
+	var reports []FsckReport
+	logdir := filepath.Join(basedir, "tweetlog")
+	entries, err := ioutil.ReadDir(logdir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		report := FsckReport{DB: e.Name()}
+		report.Err = fsckDB(&report, basedir, filepath.Join(logdir, e.Name()), opts)
+		reports = append(reports, report)
+	}
+
+	segdir := filepath.Join(basedir, "segments")
+	entries, err = ioutil.ReadDir(segdir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".seg" {
+			continue
+		}
+		report := FsckReport{DB: e.Name()}
+		report.Err = fsckSegment(&report, filepath.Join(segdir, e.Name()), opts)
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// fsckSegment reports damaged entries from segment files,
+// quarantine is not supported since a segment is rewritten
+// when opened again by the writer
+func fsckSegment(report *FsckReport, file string, opts FsckOptions) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var end int64
+	err = scanSegment(f, func(lek LogEntryKey, offset int64, value []byte) error {
+		report.Entries++
+		err := checkEntry(lek.buf[:], value, opts.Keys)
+		if errors.Is(err, ErrUnknownKey) {
+			report.Unchecked++
+		} else if err != nil {
+			report.Damaged = append(report.Damaged, FsckProblem{Key: lek.buf[:], Reason: err.Error()})
+		}
+		return nil
+	}, &end)
+	if errors.Is(err, ErrCorruptSegment) {
+		report.Damaged = append(report.Damaged, FsckProblem{Reason: err.Error()})
+		return nil
+	}
+	return err
+}
+
+func fsckDB(report *FsckReport, basedir, dbdir string, opts FsckOptions) error {
+	db, err := openFsckDB(dbdir, opts.Quarantine)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	err = checkLayout(db)
+	if err != nil {
+		return err
+	}
+
+	type damaged struct {
+		key, value []byte
+	}
+	var toQuarantine []damaged
+	err = db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if bytes.Equal(item.Key(), layoutKey) {
+				continue
+			}
+			report.Entries++
+			key := item.KeyCopy(nil)
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				// the key is still moved, so the entry stops failing reads
+				report.Damaged = append(report.Damaged, FsckProblem{Key: key, Reason: err.Error()})
+				toQuarantine = append(toQuarantine, damaged{key: key})
+				continue
+			}
+			err = checkEntry(key, value, opts.Keys)
+			if errors.Is(err, ErrUnknownKey) {
+				// it might be fine, there is no way to tell
+				report.Unchecked++
+			} else if err != nil {
+				report.Damaged = append(report.Damaged, FsckProblem{Key: key, Reason: err.Error()})
+				toQuarantine = append(toQuarantine, damaged{key: key, value: value})
+			}
+		}
+		return nil
+	})
+	if err != nil || !opts.Quarantine || len(toQuarantine) == 0 {
+		return err
+	}
+
+	qdir := filepath.Join(basedir, "quarantine", filepath.Base(dbdir))
+	err = os.MkdirAll(qdir, 0755)
+	if err != nil {
+		return err
+	}
+	qdb, err := openFsckDB(qdir, false)
+	if err != nil {
+		return err
+	}
+	defer qdb.Close()
+
+	qbatch := qdb.NewWriteBatch()
+	defer qbatch.Cancel()
+	for _, d := range toQuarantine {
+		err = qbatch.Set(d.key, d.value)
+		if err != nil {
+			return fmt.Errorf("unable to copy entry to quarantine: %w", err)
+		}
+	}
+	err = qbatch.Flush()
+	if err != nil {
+		return fmt.Errorf("unable to save quarantine: %w", err)
+	}
+
+	// only remove from the original db after the quarantine
+	// is safe on disk
+	rmbatch := db.NewWriteBatch()
+	defer rmbatch.Cancel()
+	for _, d := range toQuarantine {
+		err = rmbatch.Delete(d.key)
+		if err != nil {
+			return fmt.Errorf("unable to remove damaged entry: %w", err)
+		}
+	}
+	err = rmbatch.Flush()
+	if err != nil {
+		return fmt.Errorf("unable to remove damaged entries: %w", err)
+	}
+	report.Quarantined = len(toQuarantine)
+	return nil
+}
+
+// checkEntry returns why the entry is damaged, or ErrUnknownKey
+// when it is encrypted with a key which isn't part of keys
+func checkEntry(key, value []byte, keys *Keyring) error {
+	var lek LogEntryKey
+	err := lek.Parse(key)
+	if err != nil {
+		return err
+	}
+	t, err := decodeEntry(value, keys)
+	if err != nil {
+		return err
+	}
+	if t.Id != lek.ID() {
+		return fmt.Errorf("key has id %v but payload has id %v", lek.ID(), t.Id)
+	}
+	return nil
+}
+
+func openFsckDB(dir string, truncate bool) (*badger.DB, error) {
+	opts := badger.DefaultOptions(dir)
+	opts.Truncate = truncate
+	opts.Logger = &badgerLogger{log.Logger.With().Str("module", "fsck").Str("db", filepath.Base(dir)).Logger()}
+	return badger.Open(opts)
+}
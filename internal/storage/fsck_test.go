@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/dgraph-io/badger"
+)
+
+func TestFsckEncryptedWithoutKeys(t *testing.T) {
+	keys, err := ParseKeyring(testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := tempDir(t)
+	for _, backend := range []string{"badger", "segment"} {
+		store, err := OpenStore(backend, dir, StoreOptions{Keys: keys})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = store.Append(&schema.Tweet{Id: 1, Text: "one"}, &schema.Tweet{Id: 2, Text: "two"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reports, err := Fsck(dir, FsckOptions{Quarantine: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("expecting a database and a segment, got %v", reports)
+	}
+	for _, r := range reports {
+		if r.Err != nil {
+			t.Fatalf("%v: %v", r.DB, r.Err)
+		}
+		if r.Entries != 2 || r.Unchecked != 2 || len(r.Damaged) != 0 || r.Quarantined != 0 {
+			t.Errorf("%v: encrypted entries should be unchecked, got %+v", r.DB, r)
+		}
+	}
+
+	// nothing was moved, every entry is still there
+	reports, err = Fsck(dir, FsckOptions{Keys: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range reports {
+		if r.Err != nil || r.Entries != 2 || r.Unchecked != 0 || len(r.Damaged) != 0 {
+			t.Errorf("%v: expecting 2 valid entries, got %+v", r.DB, r)
+		}
+	}
+	if !strings.HasSuffix(reports[1].DB, ".seg") {
+		t.Errorf("expecting the segment after the database, got %v", reports[1].DB)
+	}
+}
+
+// setRaw writes value under key without any encoding
+func setRaw(t *testing.T, dbdir string, entries map[string][]byte) {
+	db, err := openFsckDB(dbdir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = db.Update(func(txn *badger.Txn) error {
+		for k, v := range entries {
+			if err := txn.Set([]byte(k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fsckOne(t *testing.T, dir string, opts FsckOptions) FsckReport {
+	t.Helper()
+	reports, err := Fsck(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("expecting one report got %v", reports)
+	}
+	if reports[0].Err != nil {
+		t.Fatalf("%v: %v", reports[0].DB, reports[0].Err)
+	}
+	return reports[0]
+}
+
+func TestFsckQuarantine(t *testing.T) {
+	dir := tempDir(t)
+	dbdir := writeDB(t, dir, &CurrentLayout, currentKey, 1, 2)
+	other, err := encodeEntry(&schema.Tweet{Id: 9}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	damaged := map[string][]byte{
+		string(currentKey(segmentHour.Unix(), 3)): []byte("not a tweet"),
+		string(currentKey(segmentHour.Unix(), 4)): other,
+		"short": other,
+	}
+	setRaw(t, dbdir, damaged)
+
+	// without quarantine damaged entries are only reported
+	r := fsckOne(t, dir, FsckOptions{})
+	if r.Entries != 5 || len(r.Damaged) != 3 || r.Quarantined != 0 {
+		t.Fatalf("expecting 3 damaged entries out of 5 got %+v", r)
+	}
+	r = fsckOne(t, dir, FsckOptions{Quarantine: true})
+	if r.Entries != 5 || len(r.Damaged) != 3 || r.Quarantined != 3 {
+		t.Fatalf("expecting 3 quarantined entries got %+v", r)
+	}
+	for _, p := range r.Damaged {
+		if _, ok := damaged[string(p.Key)]; !ok || p.Reason == "" {
+			t.Errorf("unexpected problem %+v", p)
+		}
+	}
+
+	// the original keeps only the valid entries
+	r = fsckOne(t, dir, FsckOptions{})
+	if r.Entries != 2 || len(r.Damaged) != 0 {
+		t.Errorf("expecting 2 valid entries after the quarantine got %+v", r)
+	}
+	ids, err := readRange(t, NewLogReader(dir, StoreOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids, 1, 2)
+
+	// and the quarantine has the damaged ones, unchanged
+	qdb, err := openFsckDB(filepath.Join(dir, "quarantine", filepath.Base(dbdir)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer qdb.Close()
+	found := 0
+	err = qdb.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			value, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if want, ok := damaged[string(it.Item().Key())]; !ok || !bytes.Equal(value, want) {
+				t.Errorf("unexpected entry %q in quarantine", it.Item().Key())
+			}
+			found++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != len(damaged) {
+		t.Errorf("expecting %v entries in quarantine got %v", len(damaged), found)
+	}
+}
+
+func TestFsckTornSegment(t *testing.T) {
+	dir := tempDir(t)
+	file := segmentFile(dir, segmentHour)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeSegment(t, file, false, 1, 2, 3)
+	st, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(file, st.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+
+	// the records before the torn one are still checked
+	r := fsckOne(t, dir, FsckOptions{Quarantine: true})
+	if r.Entries != 2 || len(r.Damaged) != 1 || r.Quarantined != 0 {
+		t.Fatalf("expecting 2 entries and a torn record got %+v", r)
+	}
+	if p := r.Damaged[0]; p.Key != nil || !strings.Contains(p.Reason, "torn record") {
+		t.Errorf("expecting a torn record got %+v", p)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "quarantine")); !os.IsNotExist(err) {
+		t.Errorf("segments are never quarantined")
+	}
+}
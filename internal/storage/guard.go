@@ -0,0 +1,293 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// GuardOptions sets the limits enforced by Guard, zero disables a limit
+	GuardOptions struct {
+		// Dir is the storage directory
+		Dir string
+		// Quota is the maximum number of bytes used by the partitions
+		// in Dir, the spill queue and the tier cache are not included
+		Quota int64
+		// MinFree removes old partitions early when the free space
+		// drops below it
+		MinFree int64
+		// CriticalFree enters degraded mode when the free space
+		// drops below it and there is nothing left to remove
+		CriticalFree int64
+		// Retention removes partitions older than it
+		Retention time.Duration
+		// SampleRate keeps one out of SampleRate tweets while degraded,
+		// zero drops every tweet
+		SampleRate int
+		// Interval between checks
+		Interval time.Duration
+		// Tier, when set, keeps partitions which were not uploaded yet
+		Tier *Tier
+	}
+
+	// Guard is a suture service which keeps the storage directory
+	// within its quota and free space watermarks.
+	//
+	// It applies retention early when the limits are crossed and
+	// switches storage to a degraded mode, which drops or samples
+	// tweets, when that isn't enough
+	Guard struct {
+		// first to keep 64-bit alignment for atomic operations
+		sampled uint64
+
+		opts     GuardOptions
+		degraded int32
+
+		health struct {
+			sync.Mutex
+			err error
+		}
+
+		// replaced by tests
+		dirSize  func(string) (int64, error)
+		diskFree func(string) (int64, error)
+
+		logCtx zerolog.Logger
+		stop   chan struct{}
+		done   chan struct{}
+	}
+)
+
+var (
+	diskUsed = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "diskUsed",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Bytes used by the partitions in the storage directory",
+	})
+	diskFreeBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "diskFree",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Bytes available on the filesystem holding the storage directory",
+	})
+	degradedMode = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "degraded",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "1 while storage drops or samples tweets to protect the disk",
+	})
+	degradedDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "degradedDropped",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Tweets dropped while in degraded mode",
+	})
+	degradedKept = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "degradedKept",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Tweets sampled and kept while in degraded mode",
+	})
+	partitionsRemoved = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "partitionsRemoved",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+	}, []string{"reason"})
+)
+
+func init() {
+	prometheus.MustRegister(diskUsed, diskFreeBytes, degradedMode, degradedDropped, degradedKept, partitionsRemoved)
+}
+
+// NewGuard returns a guard for the given options
+func NewGuard(opts GuardOptions) *Guard {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second * 10
+	}
+	g := &Guard{
+		opts:     opts,
+		dirSize:  partitionsSize,
+		diskFree: diskFree,
+		logCtx:   log.With().Str("service", "storage-guard").Logger(),
+	}
+	return g
+}
+
+// Serve checks the limits until Stop is called
+func (g *Guard) Serve() {
+	g.stop = make(chan struct{})
+	g.done = make(chan struct{})
+	defer close(g.done)
+
+	ticker := time.NewTicker(g.opts.Interval)
+	defer ticker.Stop()
+	for {
+		g.check(time.Now())
+		select {
+		case <-ticker.C:
+		case <-g.stop:
+			return
+		}
+	}
+}
+
+// Stop the service
+func (g *Guard) Stop() {
+	close(g.stop)
+	<-g.done
+}
+
+func (g *Guard) String() string { return "storage-guard" }
+
+// Healthy returns nil unless storage is degraded or the limits
+// could not be checked
+func (g *Guard) Healthy() error {
+	if g == nil {
+		return nil
+	}
+	g.health.Lock()
+	defer g.health.Unlock()
+	return g.health.err
+}
+
+// Degraded returns true while tweets are being dropped or sampled
+func (g *Guard) Degraded() bool {
+	return g != nil && atomic.LoadInt32(&g.degraded) == 1
+}
+
+// Admit returns false if the tweet should be dropped
+func (g *Guard) Admit() bool {
+	if !g.Degraded() {
+		return true
+	}
+	if g.opts.SampleRate > 0 && atomic.AddUint64(&g.sampled, 1)%uint64(g.opts.SampleRate) == 0 {
+		degradedKept.Inc()
+		return true
+	}
+	degradedDropped.Inc()
+	return false
+}
+
+func (g *Guard) check(now time.Time) {
+	err := g.enforce(now)
+	if err != nil {
+		g.logCtx.Error().Err(err).Str("action", "check").Send()
+	}
+	g.health.Lock()
+	g.health.err = err
+	g.health.Unlock()
+}
+
+// enforce applies retention and decides if storage is degraded,
+// it returns an error describing why storage is unhealthy
+func (g *Guard) enforce(now time.Time) error {
+	parts, err := listPartitions(g.opts.Dir)
+	if err != nil {
+		return err
+	}
+	if g.opts.Retention > 0 {
+		kept := parts[:0]
+		for _, p := range parts {
+			if p.hour.Add(time.Hour).Before(now.Add(-g.opts.Retention)) && g.removable(p, now) {
+				g.remove(p, "retention")
+				continue
+			}
+			kept = append(kept, p)
+		}
+		parts = kept
+	}
+
+	for {
+		used, free, err := g.usage()
+		if err != nil {
+			return err
+		}
+		// free is negative when the platform cannot report it
+		overQuota := g.opts.Quota > 0 && used > g.opts.Quota
+		critical := g.opts.CriticalFree > 0 && free >= 0 && free < g.opts.CriticalFree
+		lowSpace := critical || (g.opts.MinFree > 0 && free >= 0 && free < g.opts.MinFree)
+		if !overQuota && !lowSpace {
+			break
+		}
+		next := -1
+		for i, p := range parts {
+			if !p.sealed(now) {
+				break
+			}
+			if g.removable(p, now) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			// nothing else can be removed, only degrade if the
+			// situation is critical
+			if overQuota || critical {
+				g.setDegraded(true)
+				return fmt.Errorf("degraded: %v bytes used (quota %v), %v bytes free", used, g.opts.Quota, free)
+			}
+			break
+		}
+		reason := "quota"
+		if lowSpace {
+			reason = "low-space"
+		}
+		g.remove(parts[next], reason)
+		parts = append(parts[:next], parts[next+1:]...)
+	}
+	g.setDegraded(false)
+	return nil
+}
+
+func (g *Guard) usage() (used int64, free int64, err error) {
+	used, err = g.dirSize(g.opts.Dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	free, err = g.diskFree(g.opts.Dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	diskUsed.Set(float64(used))
+	diskFreeBytes.Set(float64(free))
+	return used, free, nil
+}
+
+// removable returns true if p can be removed, partitions still
+// waiting for the tier to upload them are kept
+func (g *Guard) removable(p partition, now time.Time) bool {
+	if !p.sealed(now) {
+		return false
+	}
+	return g.opts.Tier == nil || g.opts.Tier.uploaded(p)
+}
+
+func (g *Guard) remove(p partition, reason string) {
+	err := os.RemoveAll(p.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		g.logCtx.Error().Err(err).Str("action", "remove-partition").Str("path", p.path).Send()
+		return
+	}
+	partitionsRemoved.WithLabelValues(reason).Inc()
+	g.logCtx.Warn().Str("action", "remove-partition").Str("path", p.path).Str("reason", reason).Send()
+}
+
+func (g *Guard) setDegraded(on bool) {
+	v := int32(0)
+	if on {
+		v = 1
+	}
+	if atomic.SwapInt32(&g.degraded, v) != v {
+		g.logCtx.Warn().Bool("degraded", on).Msg("Storage mode changed")
+	}
+	degradedMode.Set(float64(v))
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// guardHour is the first partition created by writePartitions, guardNow
+// leaves the last two of them unsealed
+var (
+	guardHour = time.Date(2020, 10, 18, 0, 0, 0, 0, time.Local)
+	guardNow  = guardHour.Add(5*time.Hour + time.Minute)
+)
+
+// writePartitions creates one segment with size bytes for each of the
+// hours after guardHour
+func writePartitions(t *testing.T, dir string, hours int, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "segments"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < hours; i++ {
+		file := segmentFile(dir, guardHour.Add(time.Duration(i)*time.Hour))
+		if err := ioutil.WriteFile(file, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func partitionHours(t *testing.T, dir string) []int {
+	t.Helper()
+	parts, err := listPartitions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []int
+	for _, p := range parts {
+		out = append(out, int(p.hour.Sub(guardHour)/time.Hour))
+	}
+	return out
+}
+
+func expectHours(t *testing.T, dir string, want ...int) {
+	t.Helper()
+	got := partitionHours(t, dir)
+	if len(got) != len(want) {
+		t.Fatalf("expecting partitions %v got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expecting partitions %v got %v", want, got)
+		}
+	}
+}
+
+// diskGuard returns a guard for a disk with capacity bytes, the free
+// space is whatever the partitions don't use
+func diskGuard(opts GuardOptions, capacity int64) *Guard {
+	g := NewGuard(opts)
+	g.diskFree = func(dir string) (int64, error) {
+		used, err := partitionsSize(dir)
+		return capacity - used, err
+	}
+	return g
+}
+
+func expectDegraded(t *testing.T, g *Guard, degraded bool) {
+	t.Helper()
+	if g.Degraded() != degraded {
+		t.Fatalf("expecting degraded %v", degraded)
+	}
+	if err := g.Healthy(); (err != nil) != degraded {
+		t.Fatalf("expecting degraded %v got health %v", degraded, err)
+	}
+}
+
+func TestGuardRetention(t *testing.T) {
+	dir := tempDir(t)
+	writePartitions(t, dir, 6, 10)
+	g := diskGuard(GuardOptions{Dir: dir, Retention: 3 * time.Hour}, 1000)
+	g.check(guardNow)
+	// hours 0 and 1 ended more than 3 hours ago
+	expectHours(t, dir, 2, 3, 4, 5)
+	expectDegraded(t, g, false)
+
+	// the previous and current hours are always kept
+	g.opts.Retention = time.Minute
+	g.check(guardNow)
+	expectHours(t, dir, 4, 5)
+}
+
+func TestGuardQuota(t *testing.T) {
+	dir := tempDir(t)
+	writePartitions(t, dir, 6, 10)
+	g := diskGuard(GuardOptions{Dir: dir, Quota: 35}, 1000)
+	g.check(guardNow)
+	expectHours(t, dir, 3, 4, 5)
+	expectDegraded(t, g, false)
+
+	// only the unsealed partitions are left
+	g.opts.Quota = 15
+	g.check(guardNow)
+	expectHours(t, dir, 4, 5)
+	expectDegraded(t, g, true)
+
+	g.opts.Quota = 20
+	g.check(guardNow)
+	expectHours(t, dir, 4, 5)
+	expectDegraded(t, g, false)
+}
+
+func TestGuardQuotaIgnoresOtherDirs(t *testing.T) {
+	dir := tempDir(t)
+	writePartitions(t, dir, 6, 10)
+	for _, sub := range []string{"spill", "cache/segments", "quarantine"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, sub, "data"), make([]byte, 100), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g := diskGuard(GuardOptions{Dir: dir, Quota: 60}, 1000)
+	g.check(guardNow)
+	expectHours(t, dir, 0, 1, 2, 3, 4, 5)
+	expectDegraded(t, g, false)
+}
+
+func TestGuardFreeSpace(t *testing.T) {
+	dir := tempDir(t)
+	writePartitions(t, dir, 6, 10)
+	g := diskGuard(GuardOptions{Dir: dir, MinFree: 65, CriticalFree: 50}, 100)
+	g.check(guardNow)
+	// 60 used, 40 free: removes partitions until 65 are free
+	expectHours(t, dir, 3, 4, 5)
+	expectDegraded(t, g, false)
+
+	// below MinFree with nothing left to remove is still fine
+	g.opts.MinFree = 90
+	g.check(guardNow)
+	expectHours(t, dir, 4, 5)
+	expectDegraded(t, g, false)
+
+	// but not below CriticalFree
+	g.opts.CriticalFree = 85
+	g.check(guardNow)
+	expectDegraded(t, g, true)
+
+	g.opts.CriticalFree = 80
+	g.check(guardNow)
+	expectDegraded(t, g, false)
+}
+
+func TestGuardUnknownFreeSpace(t *testing.T) {
+	dir := tempDir(t)
+	writePartitions(t, dir, 6, 10)
+	g := diskGuard(GuardOptions{Dir: dir, MinFree: 1000, CriticalFree: 1000}, 0)
+	g.diskFree = func(string) (int64, error) { return -1, nil }
+	g.check(guardNow)
+	expectHours(t, dir, 0, 1, 2, 3, 4, 5)
+	expectDegraded(t, g, false)
+}
+
+func TestGuardUsageError(t *testing.T) {
+	dir := tempDir(t)
+	writePartitions(t, dir, 6, 10)
+	g := diskGuard(GuardOptions{Dir: dir, Quota: 1}, 1000)
+	broken := errors.New("broken disk")
+	g.dirSize = func(string) (int64, error) { return 0, broken }
+	g.check(guardNow)
+	if err := g.Healthy(); !errors.Is(err, broken) {
+		t.Errorf("expecting %v got %v", broken, err)
+	}
+	if g.Degraded() {
+		t.Error("errors should not degrade storage")
+	}
+
+	g.dirSize = partitionsSize
+	g.check(guardNow)
+	expectDegraded(t, g, true)
+}
+
+func TestGuardAdmit(t *testing.T) {
+	var nilGuard *Guard
+	if !nilGuard.Admit() || nilGuard.Healthy() != nil {
+		t.Error("a nil guard should admit every tweet")
+	}
+
+	for _, tc := range []struct {
+		rate int
+		kept int
+	}{
+		{0, 0},
+		{1, 9},
+		{3, 3},
+	} {
+		g := NewGuard(GuardOptions{SampleRate: tc.rate})
+		if !g.Admit() {
+			t.Fatal("guards should admit tweets until degraded")
+		}
+		g.setDegraded(true)
+		kept := 0
+		for i := 0; i < 9; i++ {
+			if g.Admit() {
+				kept++
+			}
+		}
+		if kept != tc.kept {
+			t.Errorf("sample rate %v: expecting %v tweets got %v", tc.rate, tc.kept, kept)
+		}
+		g.setDegraded(false)
+		if !g.Admit() {
+			t.Errorf("sample rate %v: expecting every tweet after leaving degraded mode", tc.rate)
+		}
+	}
+}
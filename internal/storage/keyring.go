@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+type (
+	// Keyring holds the keys used to encrypt tweets at rest.
+	//
+	// The first key encrypts new entries while every key can be used
+	// to decrypt, so keys are rotated by adding a new key at the top
+	// and removing old ones only after the data they protect is gone.
+	//
+	// A nil Keyring keeps entries in plain text.
+	Keyring struct {
+		active string
+		keys   map[string]cipher.AEAD
+	}
+)
+
+var (
+	// encryptedMagic marks an encrypted entry, which has the layout
+	// "vnE1" <key id length:1> <key id> <nonce> <aes-gcm(snappy(proto(tweet)))>
+	encryptedMagic = []byte("vnE1")
+
+	// ErrUnknownKey is returned when an entry was encrypted
+	// with a key which is not part of the keyring
+	ErrUnknownKey = errors.New("entry encrypted with unknown key")
+)
+
+// LoadKeyring reads keys from file, see ParseKeyring for the format
+func LoadKeyring(file string) (*Keyring, error) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyring(string(buf))
+}
+
+// ParseKeyring reads keys from text with one "<id>:<hex encoded 32 byte key>"
+// per line (or separated by commas), the first key is the active one.
+//
+// Empty lines and lines starting with # are ignored
+func ParseKeyring(text string) (*Keyring, error) {
+	kr := &Keyring{keys: make(map[string]cipher.AEAD)}
+	for _, line := range strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == ',' }) {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[0]) > 255 {
+			return nil, fmt.Errorf("invalid key entry, expecting <id>:<hex key>")
+		}
+		id := parts[0]
+		if _, dup := kr.keys[id]; dup {
+			return nil, fmt.Errorf("key %v appears more than once", id)
+		}
+		secret, err := hex.DecodeString(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("key %v is not hex encoded: %w", id, err)
+		}
+		if len(secret) != 32 {
+			return nil, fmt.Errorf("key %v must have 32 bytes, got %v", id, len(secret))
+		}
+		block, err := aes.NewCipher(secret)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		kr.keys[id] = aead
+		if kr.active == "" {
+			kr.active = id
+		}
+	}
+	if kr.active == "" {
+		return nil, errors.New("keyring is empty")
+	}
+	return kr, nil
+}
+
+// ActiveKey returns the id of the key used to encrypt new entries
+func (kr *Keyring) ActiveKey() string {
+	if kr == nil {
+		return ""
+	}
+	return kr.active
+}
+
+func (kr *Keyring) seal(plain []byte) ([]byte, error) {
+	if kr == nil {
+		return plain, nil
+	}
+	aead := kr.keys[kr.active]
+	out := make([]byte, 0, len(encryptedMagic)+1+len(kr.active)+aead.NonceSize()+len(plain)+aead.Overhead())
+	out = append(out, encryptedMagic...)
+	out = append(out, byte(len(kr.active)))
+	out = append(out, kr.active...)
+	nonce := out[len(out) : len(out)+aead.NonceSize()]
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate nonce: %w", err)
+	}
+	out = out[:len(out)+len(nonce)]
+	return aead.Seal(out, nonce, plain, nil), nil
+}
+
+// open returns the plain content of buf, entries which were
+// saved without encryption are returned unchanged
+func (kr *Keyring) open(buf []byte) ([]byte, error) {
+	if !bytes.HasPrefix(buf, encryptedMagic) || len(buf) < len(encryptedMagic)+1 {
+		return buf, nil
+	}
+	rest := buf[len(encryptedMagic):]
+	idlen := int(rest[0])
+	if len(rest) < 1+idlen {
+		return buf, nil
+	}
+	id := string(rest[1 : 1+idlen])
+	rest = rest[1+idlen:]
+	if kr == nil {
+		return nil, fmt.Errorf("%w: %v (no keyring configured)", ErrUnknownKey, id)
+	}
+	aead, ok := kr.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownKey, id)
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("encrypted entry is too short")
+	}
+	plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt entry with key %v: %w", id, err)
+	}
+	return plain, nil
+}
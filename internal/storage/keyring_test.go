@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+const testKeys = `old:000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f
+new:202122232425262728292a2b2c2d2e2f303132333435363738393a3b3c3d3e3f`
+
+func TestKeyringRoundTrip(t *testing.T) {
+	kr, err := ParseKeyring(testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kr.ActiveKey() != "old" {
+		t.Fatalf("the first key should be active, got %v", kr.ActiveKey())
+	}
+	plain := []byte("a tweet")
+	sealed, err := kr.seal(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(sealed, encryptedMagic) || bytes.Contains(sealed, plain) {
+		t.Fatalf("entry wasn't encrypted: %q", sealed)
+	}
+	got, err := kr.open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plain) {
+		t.Fatalf("expecting %q got %q", plain, got)
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old, err := ParseKeyring(testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := old.seal([]byte("before rotation"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a new key at the top, the old one is only used to decrypt
+	rotated, err := ParseKeyring("newest:404142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f\n" + testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := rotated.open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "before rotation" {
+		t.Fatalf("unexpected content %q", got)
+	}
+	resealed, err := rotated.seal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.open(resealed); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expecting ErrUnknownKey got %v", err)
+	}
+}
+
+func TestKeyringRejects(t *testing.T) {
+	kr, err := ParseKeyring(testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := kr.seal([]byte("a tweet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := kr.open(tampered); err == nil || errors.Is(err, ErrUnknownKey) {
+		t.Errorf("tampered entry should fail to decrypt, got %v", err)
+	}
+
+	other, err := ParseKeyring("other:606162636465666768696a6b6c6d6e6f707172737475767778797a7b7c7d7e7f")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.open(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expecting ErrUnknownKey got %v", err)
+	}
+	var none *Keyring
+	if _, err := none.open(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expecting ErrUnknownKey without a keyring got %v", err)
+	}
+}
+
+func TestKeyringPlainText(t *testing.T) {
+	kr, err := ParseKeyring(testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plain := range [][]byte{[]byte("saved before encryption"), {}, []byte("vnE")} {
+		got, err := kr.open(plain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("expecting %q unchanged got %q", plain, got)
+		}
+	}
+	var none *Keyring
+	sealed, err := none.seal([]byte("plain"))
+	if err != nil || string(sealed) != "plain" {
+		t.Errorf("a nil keyring should keep entries in plain text, got %q %v", sealed, err)
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"# only a comment",
+		"nokey",
+		"short:0011",
+		"bad:zz0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+		testKeys + "\n" + testKeys,
+	} {
+		if _, err := ParseKeyring(text); err == nil {
+			t.Errorf("expecting an error parsing %q", text)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger"
+)
+
+type (
+	// Layout records how the entries of a partition are encoded
+	Layout struct {
+		// Schema is the version of the value encoding
+		Schema int `json:"schema"`
+		// Key is the version of the key layout
+		Key int `json:"key"`
+	}
+
+	// keyDecoder extracts the moment and tweet id from a key
+	keyDecoder func(key []byte) (moment int64, id int64, err error)
+)
+
+var (
+	// CurrentLayout is used by every new partition
+	CurrentLayout = Layout{Schema: 1, Key: 1}
+
+	// legacyLayout is assumed for databases created before
+	// the layout was recorded, it is the same as version 1
+	legacyLayout = Layout{Schema: 1, Key: 1}
+
+	// layoutKey holds the Layout of a badger database, it doesn't
+	// start with 'l' so it never shows up when reading entries
+	layoutKey = []byte("m/layout")
+
+	// keyDecoders has one entry for every key version which
+	// can be migrated to the current version. Version 1 is the only
+	// one so far, a new key version must add the decoder of the
+	// previous one here before bumping CurrentLayout
+	keyDecoders = map[int]keyDecoder{
+		1: func(key []byte) (int64, int64, error) {
+			var lek LogEntryKey
+			err := lek.Parse(key)
+			return lek.Moment(), lek.ID(), err
+		},
+	}
+
+	// ErrLayoutMismatch is returned when a partition uses a layout
+	// other than CurrentLayout and must be migrated
+	ErrLayoutMismatch = errors.New("partition layout doesn't match current version, run vogelnest migrate")
+)
+
+// Current returns true if l matches CurrentLayout
+func (l Layout) Current() bool {
+	return l == CurrentLayout
+}
+
+func (l Layout) String() string {
+	return fmt.Sprintf("schema=%v key=%v", l.Schema, l.Key)
+}
+
+// readLayout returns the layout of db and false if it wasn't recorded
+func readLayout(db *badger.DB) (Layout, bool, error) {
+	var l Layout
+	found := false
+	err := db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(layoutKey)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		found = true
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &l)
+		})
+	})
+	if err != nil {
+		return l, false, fmt.Errorf("unable to read layout: %w", err)
+	}
+	if !found {
+		return legacyLayout, false, nil
+	}
+	return l, true, nil
+}
+
+func writeLayout(db *badger.DB, l Layout) error {
+	buf, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return db.Update(func(txn *badger.Txn) error {
+		return txn.Set(layoutKey, buf)
+	})
+}
+
+// ensureLayout records the current layout on databases which don't
+// have one and refuses to write to databases using another layout
+func ensureLayout(db *badger.DB) error {
+	l, found, err := readLayout(db)
+	if err != nil {
+		return err
+	}
+	if !l.Current() {
+		return fmt.Errorf("%w: found %v", ErrLayoutMismatch, l)
+	}
+	if found {
+		return nil
+	}
+	return writeLayout(db, CurrentLayout)
+}
+
+// checkLayout returns an error if db cannot be read with CurrentLayout
+func checkLayout(db *badger.DB) error {
+	l, _, err := readLayout(db)
+	if err != nil {
+		return err
+	}
+	if !l.Current() {
+		return fmt.Errorf("%w: found %v", ErrLayoutMismatch, l)
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dgraph-io/badger"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// MigrateOptions controls how Migrate rewrites partitions
+	MigrateOptions struct {
+		// OutDir receives a copy of every partition using the current layout,
+		// when empty partitions are replaced in place
+		OutDir string
+
+		// Force rewrites partitions even if they use the current layout,
+		// which re-encrypts old entries with the active key
+		Force bool
+
+		// Keys decrypts the existing entries and encrypts the new ones
+		Keys *Keyring
+	}
+
+	// MigrateReport describes what happened to one partition
+	MigrateReport struct {
+		Partition string
+		From      Layout
+		// Action is one of: up-to-date, stamped or rewritten
+		Action  string
+		Entries int
+		Err     error
+	}
+)
+
+// Migrate converts every partition under basedir to CurrentLayout.
+//
+// Databases which predate the layout metadata but match the current
+// layout are only stamped, others are rewritten into a new database
+// which replaces the original once complete.
+//
+// The server must be stopped while Migrate runs.
+func Migrate(basedir string, opts MigrateOptions) ([]MigrateReport, error) {
+	err := recoverMigrations(basedir)
+	if err != nil {
+		return nil, err
+	}
+	var reports []MigrateReport
+	logdir := filepath.Join(basedir, "tweetlog")
+	entries, err := ioutil.ReadDir(logdir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() || filepath.Ext(e.Name()) != "" {
+			continue
+		}
+		report := MigrateReport{Partition: e.Name()}
+		report.Err = migrateDB(&report, filepath.Join(logdir, e.Name()), opts)
+		reports = append(reports, report)
+	}
+
+	segdir := filepath.Join(basedir, "segments")
+	entries, err = ioutil.ReadDir(segdir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".seg" {
+			continue
+		}
+		report := MigrateReport{Partition: e.Name()}
+		report.Err = migrateSegment(&report, filepath.Join(segdir, e.Name()), opts)
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func migrateDB(report *MigrateReport, dbdir string, opts MigrateOptions) error {
+	src, err := openMigrateDB(dbdir)
+	if err != nil {
+		return err
+	}
+	srcOpen := true
+	defer func() {
+		if srcOpen {
+			src.Close()
+		}
+	}()
+
+	layout, found, err := readLayout(src)
+	if err != nil {
+		return err
+	}
+	report.From = layout
+	if len(opts.OutDir) == 0 && !opts.Force && layout.Current() {
+		if found {
+			report.Action = "up-to-date"
+			return nil
+		}
+		report.Action = "stamped"
+		return writeLayout(src, CurrentLayout)
+	}
+	decodeKey, ok := keyDecoders[layout.Key]
+	if !ok || layout.Schema != CurrentLayout.Schema {
+		return fmt.Errorf("no migration from %v to %v", layout, CurrentLayout)
+	}
+
+	target := dbdir + ".migrating"
+	if len(opts.OutDir) > 0 {
+		target = filepath.Join(opts.OutDir, "tweetlog", filepath.Base(dbdir))
+	}
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("%v already exists", target)
+	}
+	err = os.MkdirAll(target, 0755)
+	if err != nil {
+		return err
+	}
+	dst, err := openMigrateDB(target)
+	if err != nil {
+		return err
+	}
+	err = copyDB(report, src, dst, decodeKey, opts.Keys)
+	if err == nil {
+		err = writeLayout(dst, CurrentLayout)
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.RemoveAll(target)
+		return err
+	}
+	report.Action = "rewritten"
+	if len(opts.OutDir) > 0 {
+		return nil
+	}
+
+	srcOpen = false
+	err = src.Close()
+	if err != nil {
+		return err
+	}
+	return replace(dbdir, target)
+}
+
+func copyDB(report *MigrateReport, src, dst *badger.DB, decodeKey keyDecoder, keys *Keyring) error {
+	batch := dst.NewWriteBatch()
+	defer batch.Cancel()
+	err := src.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if bytes.Equal(item.Key(), layoutKey) {
+				continue
+			}
+			moment, id, err := decodeKey(item.Key())
+			if err != nil {
+				return fmt.Errorf("key %x: %w", item.Key(), err)
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			t, err := decodeEntry(value, keys)
+			if err != nil {
+				return fmt.Errorf("key %x: %w", item.Key(), err)
+			}
+			if t.Id != id {
+				return fmt.Errorf("key %x: has id %v but payload has id %v", item.Key(), id, t.Id)
+			}
+			var lek LogEntryKey
+			lek.Set(moment, t)
+			value, err = encodeEntry(t, keys)
+			if err != nil {
+				return err
+			}
+			err = batch.Set(lek.buf[:], value)
+			if err != nil {
+				return err
+			}
+			report.Entries++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return batch.Flush()
+}
+
+// migrateSegment rewrites a segment when forced or copied to OutDir,
+// otherwise it only checks the header since segments record their
+// version there
+func migrateSegment(report *MigrateReport, file string, opts MigrateOptions) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	err = checkSegmentHeader(in)
+	if err != nil {
+		return err
+	}
+	report.From = CurrentLayout
+	if len(opts.OutDir) == 0 && !opts.Force {
+		report.Action = "up-to-date"
+		return nil
+	}
+
+	target := file + ".migrating"
+	if len(opts.OutDir) > 0 {
+		target = filepath.Join(opts.OutDir, "segments", filepath.Base(file))
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("%v already exists", target)
+	}
+	out, err := openSegmentWriter(target)
+	if err != nil {
+		return err
+	}
+	var end int64
+	err = scanSegment(in, func(lek LogEntryKey, _ int64, value []byte) error {
+		t, err := decodeEntry(value, opts.Keys)
+		if err != nil {
+			return fmt.Errorf("key %x: %w", lek.buf[:], err)
+		}
+		value, err = encodeEntry(t, opts.Keys)
+		if err != nil {
+			return err
+		}
+		report.Entries++
+		return out.append(lek, value)
+	}, &end)
+	if serr := out.seal(); err == nil {
+		err = serr
+	}
+	if err != nil {
+		os.Remove(target)
+		return err
+	}
+	report.Action = "rewritten"
+	if len(opts.OutDir) > 0 {
+		return nil
+	}
+	in.Close()
+	return replace(file, target)
+}
+
+// replace moves migrated over original keeping a backup
+// until the rename is done
+func replace(original, migrated string) error {
+	backup := original + ".old"
+	err := os.Rename(original, backup)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(migrated, original)
+	if err != nil {
+		os.Rename(backup, original)
+		return err
+	}
+	return os.RemoveAll(backup)
+}
+
+// recoverMigrations finishes or rolls back replacements interrupted by
+// a crash: a backup (.old) without its partition is restored, a backup
+// next to the migrated partition is removed and so are unfinished
+// copies (.migrating)
+func recoverMigrations(basedir string) error {
+	for _, dir := range []string{"tweetlog", "segments"} {
+		entries, err := ioutil.ReadDir(filepath.Join(basedir, dir))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			name := filepath.Join(basedir, dir, e.Name())
+			var action string
+			switch filepath.Ext(name) {
+			case ".old":
+				original := strings.TrimSuffix(name, ".old")
+				_, err = os.Stat(original)
+				if os.IsNotExist(err) {
+					action = "restore-backup"
+					err = os.Rename(name, original)
+				} else if err == nil {
+					action = "remove-backup"
+					err = os.RemoveAll(name)
+				}
+			case ".migrating":
+				action = "remove-unfinished"
+				err = os.RemoveAll(name)
+			default:
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("unable to recover %v: %w", name, err)
+			}
+			log.Warn().Str("module", "migrate").Str("action", action).Str("path", name).Msg("Recovered interrupted migration")
+		}
+	}
+	return nil
+}
+
+func openMigrateDB(dir string) (*badger.DB, error) {
+	opts := badger.DefaultOptions(dir)
+	opts.Logger = &badgerLogger{log.Logger.With().Str("module", "migrate").Str("db", filepath.Base(dir)).Logger()}
+	return badger.Open(opts)
+}
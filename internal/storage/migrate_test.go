@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/dgraph-io/badger"
+)
+
+// legacyKey is the key layout used by the version 0 decoder registered
+// by the tests: 'k' <id> <moment>
+func legacyKey(moment, id int64) []byte {
+	var key [17]byte
+	key[0] = 'k'
+	binary.BigEndian.PutUint64(key[1:], uint64(id))
+	binary.BigEndian.PutUint64(key[9:], uint64(moment))
+	return key[:]
+}
+
+func currentKey(moment, id int64) []byte {
+	var lek LogEntryKey
+	lek.Set(moment, &schema.Tweet{Id: id})
+	return lek.buf[:]
+}
+
+// writeDB creates the badger partition of segmentHour with a tweet
+// for each id, layout is only recorded when given
+func writeDB(t *testing.T, basedir string, layout *Layout, key func(moment, id int64) []byte, ids ...int64) string {
+	dbdir := filepath.Join(basedir, "tweetlog", segmentHour.Format(partitionFormat))
+	if err := os.MkdirAll(filepath.Dir(dbdir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := openMigrateDB(dbdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = db.Update(func(txn *badger.Txn) error {
+		for _, id := range ids {
+			buf, err := encodeEntry(&schema.Tweet{Id: id, Text: "tweet"}, nil)
+			if err != nil {
+				return err
+			}
+			err = txn.Set(key(segmentHour.Unix(), id), buf)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil && layout != nil {
+		err = writeLayout(db, *layout)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dbdir
+}
+
+func dbLayout(t *testing.T, dbdir string) (Layout, bool) {
+	db, err := openMigrateDB(dbdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	l, found, err := readLayout(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, found
+}
+
+func readRange(t *testing.T, r TweetReader) ([]int64, error) {
+	var ids []int64
+	err := r.ReadRange(segmentHour, segmentHour.Add(time.Hour), func(t *schema.Tweet) error {
+		ids = append(ids, t.Id)
+		return nil
+	})
+	return ids, err
+}
+
+func migrate(t *testing.T, dir string, opts MigrateOptions) MigrateReport {
+	t.Helper()
+	reports, err := Migrate(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("expecting a single partition got %v", reports)
+	}
+	return reports[0]
+}
+
+func TestMigrateStamp(t *testing.T) {
+	dir := tempDir(t)
+	dbdir := writeDB(t, dir, nil, currentKey, 1, 2)
+
+	r := migrate(t, dir, MigrateOptions{})
+	if r.Err != nil || r.Action != "stamped" || r.From != legacyLayout {
+		t.Fatalf("expecting a stamped partition got %+v", r)
+	}
+	if l, found := dbLayout(t, dbdir); !found || !l.Current() {
+		t.Fatalf("expecting the current layout got %v (found %v)", l, found)
+	}
+	r = migrate(t, dir, MigrateOptions{})
+	if r.Err != nil || r.Action != "up-to-date" {
+		t.Fatalf("expecting an up-to-date partition got %+v", r)
+	}
+	ids, err := readRange(t, NewLogReader(dir, StoreOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids, 1, 2)
+}
+
+func TestLayoutMismatch(t *testing.T) {
+	dir := tempDir(t)
+	writeDB(t, dir, &Layout{Schema: 1, Key: 2}, currentKey, 1)
+
+	_, err := readRange(t, NewLogReader(dir, StoreOptions{}))
+	if !errors.Is(err, ErrLayoutMismatch) {
+		t.Errorf("expecting ErrLayoutMismatch got %v", err)
+	}
+	r := migrate(t, dir, MigrateOptions{})
+	if r.Err == nil {
+		t.Errorf("expecting an error for an unknown layout got %+v", r)
+	}
+}
+
+func TestMigrateRewrite(t *testing.T) {
+	keyDecoders[0] = func(key []byte) (int64, int64, error) {
+		if len(key) != 17 || key[0] != 'k' {
+			return 0, 0, ErrInvalidKey
+		}
+		return int64(binary.BigEndian.Uint64(key[9:])), int64(binary.BigEndian.Uint64(key[1:])), nil
+	}
+	defer delete(keyDecoders, 0)
+
+	for _, out := range []bool{false, true} {
+		dir := tempDir(t)
+		dbdir := writeDB(t, dir, &Layout{Schema: 1, Key: 0}, legacyKey, 3, 1, 2)
+		opts := MigrateOptions{}
+		if out {
+			opts.OutDir = tempDir(t)
+		}
+		r := migrate(t, dir, opts)
+		if r.Err != nil || r.Action != "rewritten" || r.Entries != 3 {
+			t.Fatalf("expecting 3 rewritten entries got %+v", r)
+		}
+		migrated := dir
+		if out {
+			migrated = opts.OutDir
+			if l, _ := dbLayout(t, dbdir); l.Key != 0 {
+				t.Errorf("the original should be kept, got %v", l)
+			}
+		}
+		ids, err := readRange(t, NewLogReader(migrated, StoreOptions{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectIDs(t, ids, 1, 2, 3)
+		leftovers, _ := filepath.Glob(dbdir + ".*")
+		if len(leftovers) > 0 {
+			t.Errorf("expecting no leftovers got %v", leftovers)
+		}
+	}
+}
+
+func TestMigrateForce(t *testing.T) {
+	keys, err := ParseKeyring(testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := tempDir(t)
+	writeDB(t, dir, &CurrentLayout, currentKey, 1, 2)
+	file := segmentFile(dir, segmentHour)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeSegment(t, file, true, 1, 2)
+
+	reports, err := Migrate(dir, MigrateOptions{Force: true, Keys: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range reports {
+		if r.Err != nil || r.Action != "rewritten" || r.Entries != 2 {
+			t.Errorf("expecting 2 rewritten entries got %+v", r)
+		}
+	}
+	for _, backend := range []string{"badger", "segment"} {
+		r, err := OpenReader(backend, dir, StoreOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readRange(t, r); !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("%v: entries should be encrypted, got %v", backend, err)
+		}
+		r, _ = OpenReader(backend, dir, StoreOptions{Keys: keys})
+		ids, err := readRange(t, r)
+		if err != nil {
+			t.Fatalf("%v: %v", backend, err)
+		}
+		expectIDs(t, ids, 1, 2)
+	}
+}
+
+func TestRecoverMigrations(t *testing.T) {
+	dir := tempDir(t)
+	// crashed between both renames: only the backup is left
+	dbdir := writeDB(t, dir, nil, currentKey, 1)
+	if err := os.Rename(dbdir, dbdir+".old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dbdir+".migrating", 0755); err != nil {
+		t.Fatal(err)
+	}
+	// crashed before removing the backup
+	file := segmentFile(dir, segmentHour)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeSegment(t, file, true, 2)
+	writeSegment(t, file+".old", true, 1)
+
+	if err := recoverMigrations(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{dbdir + ".old", dbdir + ".migrating", file + ".old"} {
+		if exists(t, name) {
+			t.Errorf("%v should be gone", name)
+		}
+	}
+	for backend, want := range map[string]int64{"badger": 1, "segment": 2} {
+		r, _ := OpenReader(backend, dir, StoreOptions{})
+		ids, err := readRange(t, r)
+		if err != nil {
+			t.Fatalf("%v: %v", backend, err)
+		}
+		expectIDs(t, ids, want)
+	}
+
+	// opening a store recovers as well
+	if err := os.Rename(file, file+".old"); err != nil {
+		t.Fatal(err)
+	}
+	store, err := OpenStore("segment", dir, StoreOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	if !exists(t, file) {
+		t.Error("opening the store should restore the backup")
+	}
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// partition is the data kept for one hour by any backend
+	partition struct {
+		hour time.Time
+		// path is a directory for badger and a file for segments
+		path string
+	}
+)
+
+// listPartitions returns every partition under basedir, oldest first
+func listPartitions(basedir string) ([]partition, error) {
+	var out []partition
+	for _, dir := range []struct{ name, ext string }{{"tweetlog", ""}, {"segments", ".seg"}} {
+		entries, err := ioutil.ReadDir(filepath.Join(basedir, dir.name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if filepath.Ext(name) != dir.ext {
+				continue
+			}
+			hour, err := time.ParseInLocation(partitionFormat, strings.TrimSuffix(name, dir.ext), time.Local)
+			if err != nil {
+				continue
+			}
+			out = append(out, partition{hour: hour, path: filepath.Join(basedir, dir.name, name)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].hour.Before(out[j].hour) })
+	return out, nil
+}
+
+// sealed returns true if no writer can be using p anymore,
+// the previous hour is kept as the writer only rotates on
+// the next append
+func (p partition) sealed(now time.Time) bool {
+	return p.hour.Before(now.Truncate(time.Hour).Add(-time.Hour))
+}
+
+// partitionsSize returns the number of bytes used by the partitions
+// of every backend under basedir, other directories (spill, cache,
+// quarantine) are left out as retention cannot free them
+func partitionsSize(basedir string) (int64, error) {
+	var total int64
+	for _, dir := range []string{"tweetlog", "segments"} {
+		size, err := dirSize(filepath.Join(basedir, dir))
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	return total, nil
+}
+
+// dirSize returns the number of bytes used by files under path
+func dirSize(path string) (int64, error) {
+	var total int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			// removed while walking
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
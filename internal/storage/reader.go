@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/dgraph-io/badger"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// TweetReader reads tweets saved by a TweetStore without
+	// writing anything to the storage directory
+	TweetReader interface {
+		// ReadRange calls fn for every tweet appended in the ten minute
+		// windows which overlap [from, to). Windows are read in order,
+		// inside a window badger returns tweets by id and segments in
+		// the order they were appended
+		ReadRange(from, to time.Time, fn func(*schema.Tweet) error) error
+	}
+
+	// TweetLogReader reads the hourly badger databases
+	// written by TweetLogWriter.
+	//
+	// Badger doesn't allow a database to be opened by two
+	// processes, so the hour which is being written by a
+	// running server cannot be read
+	TweetLogReader struct {
+		basedir string
+		opts    StoreOptions
+	}
+
+	// SegmentReader reads segment files written by SegmentLog
+	SegmentReader struct {
+		basedir string
+		opts    StoreOptions
+	}
+)
+
+// OpenReader returns the TweetReader for the given backend
+func OpenReader(backend, dir string, opts StoreOptions) (TweetReader, error) {
+	switch backend {
+	case "badger":
+		return NewLogReader(dir, opts), nil
+	case "segment":
+		return NewSegmentReader(dir, opts), nil
+	}
+	return nil, fmt.Errorf("unknown storage backend: %v", backend)
+}
+
+// NewLogReader returns a reader for the databases under dir
+func NewLogReader(dir string, opts StoreOptions) *TweetLogReader {
+	return &TweetLogReader{basedir: dir, opts: opts}
+}
+
+// ReadRange implements TweetReader
+func (tr *TweetLogReader) ReadRange(from, to time.Time, fn func(*schema.Tweet) error) error {
+	return forEachHour(from, to, func(hour time.Time) error {
+		return tr.readHour(hour, from, to, fn)
+	})
+}
+
+func (tr *TweetLogReader) readHour(hour, from, to time.Time, fn func(*schema.Tweet) error) error {
+	dbdir, err := locate(filepath.Join(tr.basedir, "tweetlog", hour.Format(partitionFormat)), hour, tr.opts.Tier)
+	if err != nil || len(dbdir) == 0 {
+		return err
+	}
+	opts := badger.DefaultOptions(dbdir)
+	opts.ReadOnly = true
+	opts.Logger = &badgerLogger{log.Logger.With().Str("module", "tweet-log-reader").Str("db", filepath.Base(dbdir)).Logger()}
+	db, err := badger.Open(opts)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	err = checkLayout(db)
+	if err != nil {
+		return fmt.Errorf("unable to read %v: %w", filepath.Base(dbdir), err)
+	}
+	return readLogDB(db, tr.opts.Keys, from, to, fn)
+}
+
+// NewSegmentReader returns a reader for the segments under dir
+func NewSegmentReader(dir string, opts StoreOptions) *SegmentReader {
+	return &SegmentReader{basedir: dir, opts: opts}
+}
+
+// ReadRange implements TweetReader, segments which are still open
+// are read up to their last complete record
+func (sr *SegmentReader) ReadRange(from, to time.Time, fn func(*schema.Tweet) error) error {
+	return forEachHour(from, to, func(hour time.Time) error {
+		file, err := locate(segmentFile(sr.basedir, hour), hour, sr.opts.Tier)
+		if err != nil || len(file) == 0 {
+			return err
+		}
+		return readSegmentFile(file, -1, sr.opts.Keys, from, to, fn)
+	})
+}
+
+func readLogDB(db *badger.DB, keys *Keyring, from, to time.Time, fn func(*schema.Tweet) error) error {
+	var start, end LogEntryKey
+	start.setMoment(from.Truncate(time.Minute * 10).Unix())
+	end.setMoment(to.Unix())
+	return db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Seek(start.buf[:]); it.ValidForPrefix(start.buf[:1]); it.Next() {
+			item := it.Item()
+			if bytes.Compare(item.Key(), end.buf[:]) >= 0 {
+				return nil
+			}
+			var t *schema.Tweet
+			err := item.Value(func(val []byte) error {
+				var err error
+				t, err = decodeEntry(val, keys)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			err = fn(t)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// forEachHour calls fn with every hour which overlaps [from, to)
+func forEachHour(from, to time.Time, fn func(time.Time) error) error {
+	for hour := from.Truncate(time.Hour); hour.Before(to); hour = hour.Add(time.Hour) {
+		err := fn(hour)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
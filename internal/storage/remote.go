@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/minio/minio-go/v6"
+)
+
+type (
+	// Remote is an object store which keeps sealed partitions
+	Remote interface {
+		// Upload copies file to the object name
+		Upload(name, file string) error
+		// Download copies the object name to file,
+		// it returns ErrNotFound if there isn't one
+		Download(name, file string) error
+	}
+
+	// S3Options configures the connection to an S3-compatible bucket
+	S3Options struct {
+		Endpoint  string
+		Bucket    string
+		Prefix    string
+		Region    string
+		AccessKey string
+		SecretKey string
+		// Insecure uses plain HTTP, eg.: for a local minio
+		Insecure bool
+	}
+
+	s3Remote struct {
+		client *minio.Client
+		bucket string
+		prefix string
+	}
+)
+
+var (
+	// ErrNotFound is returned when an object doesn't exist
+	ErrNotFound = errors.New("not found")
+)
+
+// NewS3Remote returns a Remote using an S3-compatible bucket,
+// the bucket is created if it doesn't exist
+func NewS3Remote(opts S3Options) (Remote, error) {
+	client, err := minio.NewWithRegion(opts.Endpoint, opts.AccessKey, opts.SecretKey, !opts.Insecure, opts.Region)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := client.BucketExists(opts.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("unable to check bucket %v: %w", opts.Bucket, err)
+	}
+	if !exists {
+		err = client.MakeBucket(opts.Bucket, opts.Region)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create bucket %v: %w", opts.Bucket, err)
+		}
+	}
+	return &s3Remote{client: client, bucket: opts.Bucket, prefix: opts.Prefix}, nil
+}
+
+func (r *s3Remote) Upload(name, file string) error {
+	_, err := r.client.FPutObject(r.bucket, path.Join(r.prefix, name), file, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	return err
+}
+
+func (r *s3Remote) Download(name, file string) error {
+	err := r.client.FGetObject(r.bucket, path.Join(r.prefix, name), file, minio.GetObjectOptions{})
+	if err != nil && minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
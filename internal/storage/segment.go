@@ -0,0 +1,532 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Segment files are append-only and have the following layout
+//
+//	header:  "VNSG" <version:1>
+//	record:  <length:4> <crc32c:4> <LogEntryKey:18> <snappy(proto(tweet))>
+//	...
+//	index:   (<LogEntryKey:18> <offset:8>)*
+//	trailer: <index offset:8> <index count:4> "VNIX"
+//
+// The index and trailer are only written once a segment is sealed,
+// open segments are read by scanning every record.
+//
+// All integers are big endian and length covers key and value.
+type (
+	// SegmentLog writes tweets to one append-only segment file per hour
+	SegmentLog struct {
+		sync.Mutex
+
+		basedir    string
+		opts       StoreOptions
+		activehour time.Time
+		active     *segmentWriter
+		closed     bool
+
+		entriesWritten prometheus.Counter
+		bytesWritten   prometheus.Counter
+	}
+
+	segmentWriter struct {
+		file   *os.File
+		out    *bufio.Writer
+		offset int64
+		index  []segmentIndexEntry
+	}
+
+	segmentIndexEntry struct {
+		key    LogEntryKey
+		offset int64
+	}
+)
+
+const (
+	segmentVersion     = 1
+	segmentHeaderSize  = 5
+	segmentTrailerSize = 8 + 4 + 4
+	segmentIndexSize   = 18 + 8
+	segmentRecordHead  = 4 + 4
+	// segmentMaxRecord is far larger than any tweet, larger sizes
+	// come from damaged records and are not allocated
+	segmentMaxRecord = 16 << 20
+)
+
+var (
+	segmentMagic = []byte("VNSG")
+	indexMagic   = []byte("VNIX")
+	crcTable     = crc32.MakeTable(crc32.Castagnoli)
+
+	// ErrCorruptSegment is returned when a segment cannot be read
+	ErrCorruptSegment = errors.New("corrupt segment")
+)
+
+// NewSegmentLog takes a directory and creates one segment file per hour
+func NewSegmentLog(dir string, opts StoreOptions) (*SegmentLog, error) {
+	err := recoverMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(filepath.Join(dir, "segments"), 0755)
+	if err != nil {
+		return nil, err
+	}
+	sl := &SegmentLog{basedir: dir, opts: opts}
+	err = sl.rotate(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return sl, nil
+}
+
+// Close seals the active segment, it is safe to call multiple times
+func (sl *SegmentLog) Close() error {
+	sl.Lock()
+	defer sl.Unlock()
+	if sl.closed {
+		return nil
+	}
+	sl.closed = true
+	return sl.active.seal()
+}
+
+// Append entries to the active segment
+func (sl *SegmentLog) Append(entries ...*schema.Tweet) error {
+	sl.Lock()
+	defer sl.Unlock()
+	if sl.closed {
+		return ErrClosed
+	}
+	err := sl.rotate(time.Now())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Truncate(time.Minute * 10).Unix()
+	start, indexed := sl.active.offset, len(sl.active.index)
+	err = sl.active.appendAll(now, entries, sl.opts)
+	if err != nil {
+		// nothing from this batch is kept, so retrying
+		// won't write the same records twice
+		if rerr := sl.active.rollback(start, indexed); rerr != nil {
+			return fmt.Errorf("%v, unable to discard partial batch: %w", err, rerr)
+		}
+		return err
+	}
+	sl.bytesWritten.Add(float64(sl.active.offset - start))
+	sl.entriesWritten.Add(float64(len(entries)))
+	return nil
+}
+
+// ReadRange implements TweetStore
+func (sl *SegmentLog) ReadRange(from, to time.Time, fn func(*schema.Tweet) error) error {
+	return forEachHour(from, to, func(hour time.Time) error {
+		sl.Lock()
+		if sl.closed {
+			sl.Unlock()
+			return ErrClosed
+		}
+		// anything before offset was already flushed and won't change
+		if hour.Equal(sl.activehour) {
+			limit := sl.active.offset
+			sl.Unlock()
+			return readSegmentFile(sl.segmentFile(hour), limit, sl.opts.Keys, from, to, fn)
+		}
+		sl.Unlock()
+
+		file, err := locate(sl.segmentFile(hour), hour, sl.opts.Tier)
+		if err != nil || len(file) == 0 {
+			return err
+		}
+		return readSegmentFile(file, -1, sl.opts.Keys, from, to, fn)
+	})
+}
+
+func (sl *SegmentLog) segmentFile(hour time.Time) string {
+	return segmentFile(sl.basedir, hour)
+}
+
+func segmentFile(basedir string, hour time.Time) string {
+	return filepath.Join(basedir, "segments", hour.Format(partitionFormat)+".seg")
+}
+
+// rotate seals the active segment if it doesn't match the hour of now
+// and opens a new one
+func (sl *SegmentLog) rotate(now time.Time) error {
+	hour := now.Truncate(time.Hour)
+	if sl.active != nil && hour.Equal(sl.activehour) {
+		return nil
+	}
+	w, err := openSegmentWriter(sl.segmentFile(hour))
+	if err != nil {
+		return err
+	}
+	if sl.active != nil {
+		err = sl.active.seal()
+		if err != nil {
+			w.file.Close()
+			return fmt.Errorf("unable to seal segment for %v: %w", sl.activehour.Format(partitionFormat), err)
+		}
+	}
+	name := filepath.Base(w.file.Name())
+	sl.active = w
+	sl.activehour = hour
+	sl.entriesWritten = entriesWrittenVec.With(prometheus.Labels{"activeFile": name})
+	sl.bytesWritten = bytesWrittenVec.With(prometheus.Labels{"activeFile": name})
+	return nil
+}
+
+// openSegmentWriter opens name for appending, if the file already
+// exists its index is discarded and any torn record at the end is removed
+func openSegmentWriter(name string) (*segmentWriter, error) {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	w := &segmentWriter{file: f}
+	err = w.recover()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("unable to recover segment %v: %w", name, err)
+	}
+	_, err = f.Seek(w.offset, io.SeekStart)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.out = bufio.NewWriter(f)
+	return w, nil
+}
+
+func (w *segmentWriter) recover() error {
+	st, err := w.file.Stat()
+	if err != nil {
+		return err
+	}
+	if st.Size() == 0 {
+		var header [segmentHeaderSize]byte
+		copy(header[:], segmentMagic)
+		header[4] = segmentVersion
+		_, err = w.file.Write(header[:])
+		w.offset = segmentHeaderSize
+		return err
+	}
+	err = scanSegment(w.file, func(lek LogEntryKey, offset int64, _ []byte) error {
+		w.index = append(w.index, segmentIndexEntry{key: lek, offset: offset})
+		return nil
+	}, &w.offset)
+	if w.offset < segmentHeaderSize {
+		// not a segment we know how to handle
+		return err
+	}
+	if err != nil && !errors.Is(err, ErrCorruptSegment) {
+		return err
+	}
+	// drop the index (when sealed) or a torn record (after a crash),
+	// they will be written again
+	return w.file.Truncate(w.offset)
+}
+
+// appendAll writes entries and flushes them to the file
+func (w *segmentWriter) appendAll(now int64, entries []*schema.Tweet, opts StoreOptions) error {
+	for _, e := range entries {
+		buf, err := encodeEntry(e, opts.Keys)
+		if err != nil {
+			return err
+		}
+		var lek LogEntryKey
+		lek.Set(now, e)
+		err = w.append(lek, buf)
+		if err != nil {
+			return err
+		}
+	}
+	err := w.out.Flush()
+	if err == nil && opts.SyncWrites {
+		err = w.file.Sync()
+	}
+	if err != nil {
+		return fmt.Errorf("unable to save data to disk: %w", err)
+	}
+	return nil
+}
+
+// rollback discards what was written after offset, including the
+// buffered data and the index entries after the first indexed
+func (w *segmentWriter) rollback(offset int64, indexed int) error {
+	w.out.Reset(w.file)
+	w.offset = offset
+	w.index = w.index[:indexed]
+	err := w.file.Truncate(offset)
+	if err != nil {
+		return err
+	}
+	_, err = w.file.Seek(offset, io.SeekStart)
+	return err
+}
+
+func (w *segmentWriter) append(lek LogEntryKey, value []byte) error {
+	var head [segmentRecordHead]byte
+	crc := crc32.Update(crc32.Checksum(lek.buf[:], crcTable), crcTable, value)
+	binary.BigEndian.PutUint32(head[:], uint32(len(lek.buf)+len(value)))
+	binary.BigEndian.PutUint32(head[4:], crc)
+	for _, b := range [][]byte{head[:], lek.buf[:], value} {
+		_, err := w.out.Write(b)
+		if err != nil {
+			return fmt.Errorf("unable to write record: %w", err)
+		}
+	}
+	w.index = append(w.index, segmentIndexEntry{key: lek, offset: w.offset})
+	w.offset += int64(len(head) + len(lek.buf) + len(value))
+	return nil
+}
+
+// seal writes the index and closes the file
+func (w *segmentWriter) seal() error {
+	var entry [segmentIndexSize]byte
+	for _, ie := range w.index {
+		copy(entry[:], ie.key.buf[:])
+		binary.BigEndian.PutUint64(entry[18:], uint64(ie.offset))
+		_, err := w.out.Write(entry[:])
+		if err != nil {
+			w.file.Close()
+			return err
+		}
+	}
+	var trailer [segmentTrailerSize]byte
+	binary.BigEndian.PutUint64(trailer[:], uint64(w.offset))
+	binary.BigEndian.PutUint32(trailer[8:], uint32(len(w.index)))
+	copy(trailer[12:], indexMagic)
+	_, err := w.out.Write(trailer[:])
+	if err == nil {
+		err = w.out.Flush()
+	}
+	if err == nil {
+		err = w.file.Sync()
+	}
+	if err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// readSegmentFile decodes every tweet from file in the range [from, to)
+func readSegmentFile(file string, limit int64, keys *Keyring, from, to time.Time, fn func(*schema.Tweet) error) error {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+	return readSegment(f, limit, from, to, func(_ LogEntryKey, value []byte) error {
+		t, err := decodeEntry(value, keys)
+		if err != nil {
+			return err
+		}
+		return fn(t)
+	})
+}
+
+// readSegment calls fn for every record in the range [from, to),
+// limit is the size of file which can be read or -1 to read it all.
+//
+// Sealed segments use their index to skip records before from
+func readSegment(f *os.File, limit int64, from, to time.Time, fn func(LogEntryKey, []byte) error) error {
+	var start, end LogEntryKey
+	start.setMoment(from.Truncate(time.Minute * 10).Unix())
+	end.setMoment(to.Unix())
+
+	err := checkSegmentHeader(f)
+	if err != nil {
+		return err
+	}
+	var offset int64 = segmentHeaderSize
+	open := false
+	if limit < 0 {
+		var index []segmentIndexEntry
+		index, limit, err = readSegmentIndex(f)
+		if errors.Is(err, ErrCorruptSegment) {
+			// scan the records instead, like an open segment
+			index, limit, err = nil, -1, nil
+		}
+		if err != nil {
+			return err
+		}
+		open = index == nil
+		if index != nil {
+			pos := sort.Search(len(index), func(i int) bool {
+				return bytes.Compare(index[i].key.buf[:], start.buf[:]) >= 0
+			})
+			if pos == len(index) {
+				return nil
+			}
+			offset = index[pos].offset
+		}
+	}
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	errDone := errors.New("done")
+	err = scanRecords(bufio.NewReader(f), offset, limit, func(lek LogEntryKey, _ int64, value []byte) error {
+		if bytes.Compare(lek.buf[:], start.buf[:]) < 0 {
+			return nil
+		}
+		if bytes.Compare(lek.buf[:], end.buf[:]) >= 0 {
+			return errDone
+		}
+		return fn(lek, value)
+	}, nil)
+	if errors.Is(err, errDone) {
+		return nil
+	} else if open && errors.Is(err, ErrCorruptSegment) {
+		// the writer might be in the middle of a record,
+		// fsck is the one responsible for reporting damage
+		return nil
+	}
+	return err
+}
+
+func checkSegmentHeader(f *os.File) error {
+	var header [segmentHeaderSize]byte
+	_, err := f.ReadAt(header[:], 0)
+	if err != nil {
+		return fmt.Errorf("%w: unable to read header: %v", ErrCorruptSegment, err)
+	}
+	if !bytes.Equal(header[:4], segmentMagic) {
+		return fmt.Errorf("%w: invalid magic", ErrCorruptSegment)
+	}
+	if header[4] != segmentVersion {
+		return fmt.Errorf("%w: unsupported version %v", ErrCorruptSegment, header[4])
+	}
+	return nil
+}
+
+// readSegmentIndex returns the index of a sealed segment and where
+// the records end. If the segment isn't sealed the index is nil
+// and the end is -1
+func readSegmentIndex(f *os.File) ([]segmentIndexEntry, int64, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	if st.Size() < segmentHeaderSize+segmentTrailerSize {
+		return nil, -1, nil
+	}
+	var trailer [segmentTrailerSize]byte
+	_, err = f.ReadAt(trailer[:], st.Size()-segmentTrailerSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !bytes.Equal(trailer[12:], indexMagic) {
+		return nil, -1, nil
+	}
+	indexOffset := int64(binary.BigEndian.Uint64(trailer[:]))
+	count := int64(binary.BigEndian.Uint32(trailer[8:]))
+	if indexOffset+count*segmentIndexSize+segmentTrailerSize != st.Size() {
+		return nil, 0, fmt.Errorf("%w: index doesn't match file size", ErrCorruptSegment)
+	}
+	buf := make([]byte, count*segmentIndexSize)
+	_, err = f.ReadAt(buf, indexOffset)
+	if err != nil {
+		return nil, 0, err
+	}
+	index := make([]segmentIndexEntry, count)
+	last := int64(segmentHeaderSize - 1)
+	for i := range index {
+		entry := buf[int64(i)*segmentIndexSize:]
+		copy(index[i].key.buf[:], entry[:18])
+		index[i].offset = int64(binary.BigEndian.Uint64(entry[18:]))
+		if index[i].offset <= last || index[i].offset >= indexOffset {
+			return nil, 0, fmt.Errorf("%w: invalid index entry %v", ErrCorruptSegment, i)
+		}
+		last = index[i].offset
+	}
+	return index, indexOffset, nil
+}
+
+// scanSegment validates the header and calls fn for every record
+// until the index, end of file or a damaged record is found.
+//
+// end receives the offset just after the last valid record
+func scanSegment(f *os.File, fn func(LogEntryKey, int64, []byte) error, end *int64) error {
+	*end = 0
+	err := checkSegmentHeader(f)
+	if err != nil {
+		return err
+	}
+	_, limit, err := readSegmentIndex(f)
+	if errors.Is(err, ErrCorruptSegment) {
+		// records end at the first one which can't be read,
+		// the index is written again when the segment is sealed
+		limit, err = -1, nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = f.Seek(segmentHeaderSize, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	return scanRecords(bufio.NewReader(f), segmentHeaderSize, limit, fn, end)
+}
+
+func scanRecords(in *bufio.Reader, offset, limit int64, fn func(LogEntryKey, int64, []byte) error, end *int64) error {
+	if end != nil {
+		*end = offset
+	}
+	var head [segmentRecordHead]byte
+	for limit < 0 || offset < limit {
+		_, err := io.ReadFull(in, head[:])
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: torn record at %v", ErrCorruptSegment, offset)
+		}
+		size := binary.BigEndian.Uint32(head[:])
+		if size < 18 || size > segmentMaxRecord || (limit >= 0 && offset+int64(len(head))+int64(size) > limit) {
+			return fmt.Errorf("%w: invalid record size at %v", ErrCorruptSegment, offset)
+		}
+		record := make([]byte, size)
+		_, err = io.ReadFull(in, record)
+		if err != nil {
+			return fmt.Errorf("%w: torn record at %v", ErrCorruptSegment, offset)
+		}
+		if crc32.Checksum(record, crcTable) != binary.BigEndian.Uint32(head[4:]) {
+			return fmt.Errorf("%w: checksum mismatch at %v", ErrCorruptSegment, offset)
+		}
+		var lek LogEntryKey
+		err = lek.Parse(record[:18])
+		if err != nil {
+			return fmt.Errorf("%w: %v at %v", ErrCorruptSegment, err, offset)
+		}
+		err = fn(lek, offset, record[18:])
+		if err != nil {
+			return err
+		}
+		offset += int64(len(head)) + int64(size)
+		if end != nil {
+			*end = offset
+		}
+	}
+	return nil
+}
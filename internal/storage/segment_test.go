@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+var segmentHour = time.Date(2020, 10, 18, 10, 0, 0, 0, time.UTC)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "vogelnest-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// writeSegment appends a tweet for each id to file, the segment
+// is sealed only when seal is set
+func writeSegment(t *testing.T, file string, seal bool, ids ...int64) {
+	w, err := openSegmentWriter(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		tw := &schema.Tweet{Id: id, Text: "tweet"}
+		buf, err := encodeEntry(tw, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lek LogEntryKey
+		lek.Set(segmentHour.Unix(), tw)
+		if err := w.append(lek, buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if seal {
+		err = w.seal()
+	} else {
+		err = w.out.Flush()
+		w.file.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readIDs returns the ids of the tweets in file
+func readIDs(file string) ([]int64, error) {
+	var ids []int64
+	err := readSegmentFile(file, -1, nil, segmentHour, segmentHour.Add(time.Hour), func(t *schema.Tweet) error {
+		ids = append(ids, t.Id)
+		return nil
+	})
+	return ids, err
+}
+
+func expectIDs(t *testing.T, got []int64, want ...int64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expecting ids %v got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expecting ids %v got %v", want, got)
+		}
+	}
+}
+
+func TestSegmentRoundTrip(t *testing.T) {
+	file := filepath.Join(tempDir(t), "test.seg")
+	writeSegment(t, file, true, 1, 2, 3)
+	ids, err := readIDs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids, 1, 2, 3)
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	index, _, err := readSegmentIndex(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index) != 3 {
+		t.Fatalf("expecting 3 index entries got %v", len(index))
+	}
+
+	// reopening drops the index, it is written again on seal
+	writeSegment(t, file, true, 4)
+	ids, err = readIDs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids, 1, 2, 3, 4)
+}
+
+func TestSegmentLogRoundTrip(t *testing.T) {
+	sl, err := NewSegmentLog(tempDir(t), StoreOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sl.Close()
+	err = sl.Append(&schema.Tweet{Id: 1, Text: "one"}, &schema.Tweet{Id: 2, Text: "two"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	now := time.Now()
+	err = sl.ReadRange(now.Add(-time.Hour), now.Add(time.Hour), func(t *schema.Tweet) error {
+		ids = append(ids, t.Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids, 1, 2)
+}
+
+func TestSegmentTornRecord(t *testing.T) {
+	file := filepath.Join(tempDir(t), "test.seg")
+	writeSegment(t, file, false, 1, 2, 3)
+	st, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// cut the last record in half, like a crash in the middle of a write
+	if err := os.Truncate(file, st.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := readIDs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids, 1, 2)
+
+	writeSegment(t, file, true, 4)
+	ids, err = readIDs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids, 1, 2, 4)
+}
+
+func TestSegmentChecksumMismatch(t *testing.T) {
+	file := filepath.Join(tempDir(t), "test.seg")
+	writeSegment(t, file, true, 1, 2, 3)
+	f, err := os.OpenFile(file, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, _, err := readSegmentIndex(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// flip the last byte of the second record
+	var b [1]byte
+	at := index[2].offset - 1
+	f.ReadAt(b[:], at)
+	b[0] ^= 0xff
+	if _, err := f.WriteAt(b[:], at); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	ids, err := readIDs(file)
+	if !errors.Is(err, ErrCorruptSegment) {
+		t.Fatalf("expecting ErrCorruptSegment got %v", err)
+	}
+	expectIDs(t, ids, 1)
+}
+
+func TestSegmentMissingIndex(t *testing.T) {
+	file := filepath.Join(tempDir(t), "test.seg")
+	// a segment which was never sealed has no index
+	writeSegment(t, file, false, 1, 2, 3)
+	ids, err := readIDs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids, 1, 2, 3)
+
+	writeSegment(t, file, true)
+	expectIndex(t, file, 3)
+}
+
+func TestSegmentCorruptIndex(t *testing.T) {
+	file := filepath.Join(tempDir(t), "test.seg")
+	writeSegment(t, file, true, 1, 2, 3)
+	f, err := os.OpenFile(file, os.O_RDWR, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, indexOffset, err := readSegmentIndex(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the offset of the first entry points past the records
+	garbage := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	if _, err := f.WriteAt(garbage, indexOffset+18); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	ids, err := readIDs(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids, 1, 2, 3)
+
+	writeSegment(t, file, true)
+	expectIndex(t, file, 3)
+}
+
+func expectIndex(t *testing.T, file string, size int) {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	index, _, err := readSegmentIndex(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index) != size {
+		t.Fatalf("expecting %v index entries got %v", size, len(index))
+	}
+}
+
+func TestSegmentLogFailedBatch(t *testing.T) {
+	sl, err := NewSegmentLog(tempDir(t), StoreOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sl.Close()
+	// larger than the write buffer, so part of it reaches the file
+	big := &schema.Tweet{Id: 1, Text: strings.Repeat("a", 10000)}
+	// strings must be valid UTF-8, encoding fails
+	bad := &schema.Tweet{Id: 2, Text: "\xff"}
+	if err := sl.Append(big, bad); err == nil {
+		t.Fatal("expecting the batch to fail")
+	}
+	if err := sl.Append(big); err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	now := time.Now()
+	err = sl.ReadRange(now.Add(-time.Hour), now.Add(time.Hour), func(t *schema.Tweet) error {
+		ids = append(ids, t.Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids, 1)
+	if n := len(sl.active.index); n != 1 {
+		t.Fatalf("expecting 1 index entry got %v", n)
+	}
+}
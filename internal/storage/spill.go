@@ -0,0 +1,338 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// spillQueue is a FIFO of tweets kept on disk, it holds tweets
+	// while the TweetStore can't keep up with the stream.
+	//
+	// Records use the layout <length:4> <crc32c:4> <snappy(proto(tweet))>,
+	// the offset of the first record which wasn't drained is saved
+	// to a separate file, once everything is drained both files
+	// are truncated.
+	//
+	// Records which can't be decoded are moved, with the same layout,
+	// to a quarantine file so they don't block the queue.
+	spillQueue struct {
+		sync.Mutex
+
+		file           *os.File
+		offsetFile     string
+		quarantineFile string
+		keys           *Keyring
+
+		readOffset  int64
+		writeOffset int64
+		depth       int
+	}
+)
+
+var (
+	spillDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "spillDepth",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Tweets waiting in the spill file",
+	})
+	spillBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "spillBytes",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Bytes waiting in the spill file",
+	})
+	spilled = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "spilled",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Tweets sent to the spill file",
+	})
+	spillDrained = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "spillDrained",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Tweets moved from the spill file to the tweet store",
+	})
+	spillQuarantined = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "spillQuarantined",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Spill records which could not be decoded and were moved to the quarantine file",
+	})
+)
+
+// errUndecodableSpill is returned by Peek when the head of the
+// queue can't be decoded, it must be quarantined to make progress
+var errUndecodableSpill = errors.New("undecodable spill record")
+
+func init() {
+	prometheus.MustRegister(spillDepth, spillBytes, spilled, spillDrained, spillQuarantined)
+}
+
+// openSpill opens (or creates) the spill queue kept in dir, any
+// tweet left from a previous run is kept and drained first
+func openSpill(dir string, keys *Keyring) (*spillQueue, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "spill.log"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	sq := &spillQueue{
+		file:           f,
+		offsetFile:     filepath.Join(dir, "spill.offset"),
+		quarantineFile: filepath.Join(dir, "spill.quarantine"),
+		keys:           keys,
+	}
+	err = sq.recover()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("unable to recover spill file: %w", err)
+	}
+	return sq, nil
+}
+
+func (sq *spillQueue) recover() error {
+	buf, err := ioutil.ReadFile(sq.offsetFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(buf) > 0 {
+		sq.readOffset, err = strconv.ParseInt(strings.TrimSpace(string(buf)), 10, 64)
+		if err != nil {
+			return err
+		}
+	}
+	st, err := sq.file.Stat()
+	if err != nil {
+		return err
+	}
+	if sq.readOffset > st.Size() {
+		// the file was truncated after being drained,
+		// but the offset wasn't saved
+		sq.readOffset = 0
+	}
+	_, err = sq.file.Seek(sq.readOffset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	in := bufio.NewReader(sq.file)
+	sq.writeOffset = sq.readOffset
+	for {
+		record, err := readSpillRecord(in)
+		if err != nil {
+			if err != io.EOF {
+				log.Warn().Err(err).Str("module", "spill").Int64("offset", sq.writeOffset).Msg("Discarding torn spill record")
+			}
+			break
+		}
+		sq.writeOffset += int64(8 + len(record))
+		sq.depth++
+	}
+	err = sq.file.Truncate(sq.writeOffset)
+	if err != nil {
+		return err
+	}
+	sq.updateMetrics()
+	return nil
+}
+
+// Depth returns how many tweets are waiting to be drained
+func (sq *spillQueue) Depth() int {
+	sq.Lock()
+	defer sq.Unlock()
+	return sq.depth
+}
+
+// push adds t to the end of the queue, callers must hold the lock
+func (sq *spillQueue) push(t *schema.Tweet) error {
+	value, err := encodeEntry(t, sq.keys)
+	if err != nil {
+		return err
+	}
+	record := spillRecord(value)
+	_, err = sq.file.WriteAt(record, sq.writeOffset)
+	if err != nil {
+		return fmt.Errorf("unable to write to spill file: %w", err)
+	}
+	sq.writeOffset += int64(len(record))
+	sq.depth++
+	spilled.Inc()
+	sq.updateMetrics()
+	return nil
+}
+
+// Peek returns up to max tweets from the head of the queue
+// and the offset which must be passed to Commit once they
+// are saved.
+//
+// The batch stops before the first record which can't be decoded,
+// errUndecodableSpill is returned once that record is at the head
+func (sq *spillQueue) Peek(max int) ([]*schema.Tweet, int64, error) {
+	sq.Lock()
+	start, end := sq.readOffset, sq.writeOffset
+	sq.Unlock()
+
+	in := bufio.NewReader(io.NewSectionReader(sq.file, start, end-start))
+	var out []*schema.Tweet
+	next := start
+	for len(out) < max {
+		record, err := readSpillRecord(in)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, 0, err
+		}
+		t, err := decodeEntry(record, sq.keys)
+		if err != nil {
+			if len(out) > 0 {
+				break
+			}
+			return nil, 0, fmt.Errorf("%w at offset %v: %v", errUndecodableSpill, next, err)
+		}
+		out = append(out, t)
+		next += int64(8 + len(record))
+	}
+	return out, next, nil
+}
+
+// Commit removes count tweets, ending at next, from the head of the queue
+func (sq *spillQueue) Commit(next int64, count int) error {
+	sq.Lock()
+	defer sq.Unlock()
+	spillDrained.Add(float64(count))
+	return sq.advance(next, count)
+}
+
+// Quarantine moves the record at the head of the queue to the
+// quarantine file
+func (sq *spillQueue) Quarantine() error {
+	sq.Lock()
+	defer sq.Unlock()
+	in := bufio.NewReader(io.NewSectionReader(sq.file, sq.readOffset, sq.writeOffset-sq.readOffset))
+	record, err := readSpillRecord(in)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(sq.quarantineFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(spillRecord(record))
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write to spill quarantine: %w", err)
+	}
+	spillQuarantined.Inc()
+	return sq.advance(sq.readOffset+int64(8+len(record)), 1)
+}
+
+// advance moves the head of the queue to next, callers must hold the lock
+func (sq *spillQueue) advance(next int64, count int) error {
+	sq.readOffset = next
+	sq.depth -= count
+	defer sq.updateMetrics()
+	if sq.readOffset == sq.writeOffset {
+		// everything was drained, start over
+		sq.readOffset, sq.writeOffset = 0, 0
+		err := sq.file.Truncate(0)
+		if err != nil {
+			return err
+		}
+	}
+	return writeFileSync(sq.offsetFile, []byte(strconv.FormatInt(sq.readOffset, 10)))
+}
+
+// writeFileSync replaces name with data, a crash leaves
+// either the old or the new content
+func writeFileSync(name string, data []byte) error {
+	tmp := name + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// the rename is only durable once the directory reaches the disk,
+	// not every platform can sync a directory
+	if dir, err := os.Open(filepath.Dir(name)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// Close the spill file, tweets which were not drained are kept
+func (sq *spillQueue) Close() error {
+	sq.Lock()
+	defer sq.Unlock()
+	return sq.file.Close()
+}
+
+func (sq *spillQueue) updateMetrics() {
+	spillDepth.Set(float64(sq.depth))
+	spillBytes.Set(float64(sq.writeOffset - sq.readOffset))
+}
+
+// spillRecord returns value framed with its length and checksum
+func spillRecord(value []byte) []byte {
+	record := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint32(record, uint32(len(value)))
+	binary.BigEndian.PutUint32(record[4:], crc32.Checksum(value, crcTable))
+	copy(record[8:], value)
+	return record
+}
+
+func readSpillRecord(in *bufio.Reader) ([]byte, error) {
+	var head [8]byte
+	_, err := io.ReadFull(in, head[:])
+	if err == io.EOF {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("torn spill record: %w", err)
+	}
+	record := make([]byte, binary.BigEndian.Uint32(head[:]))
+	_, err = io.ReadFull(in, record)
+	if err != nil {
+		return nil, fmt.Errorf("torn spill record: %w", err)
+	}
+	if crc32.Checksum(record, crcTable) != binary.BigEndian.Uint32(head[4:]) {
+		return nil, fmt.Errorf("spill record checksum mismatch")
+	}
+	return record, nil
+}
@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/rs/zerolog"
+)
+
+type (
+	// memoryStore keeps appended tweets in memory,
+	// appends fail while down is set
+	memoryStore struct {
+		down   bool
+		tweets []*schema.Tweet
+	}
+)
+
+var errStoreDown = errors.New("store is down")
+
+func (m *memoryStore) Append(entries ...*schema.Tweet) error {
+	if m.down {
+		return errStoreDown
+	}
+	m.tweets = append(m.tweets, entries...)
+	return nil
+}
+
+func (m *memoryStore) ReadRange(from, to time.Time, fn func(*schema.Tweet) error) error {
+	for _, t := range m.tweets {
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) Close() error { return nil }
+
+func (m *memoryStore) ids() []int64 {
+	var out []int64
+	for _, t := range m.tweets {
+		out = append(out, t.Id)
+	}
+	return out
+}
+
+func pushSpill(t *testing.T, sq *spillQueue, ids ...int64) {
+	sq.Lock()
+	defer sq.Unlock()
+	for _, id := range ids {
+		if err := sq.push(&schema.Tweet{Id: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func peekIDs(t *testing.T, sq *spillQueue, max int) ([]int64, int64) {
+	t.Helper()
+	batch, next, err := sq.Peek(max)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, tw := range batch {
+		ids = append(ids, tw.Id)
+	}
+	return ids, next
+}
+
+func TestSpillDrainsBeforeNewTweets(t *testing.T) {
+	sq, err := openSpill(tempDir(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sq.Close()
+	store := &memoryStore{down: true}
+	s := &Server{log: store, spill: sq}
+	logctx := zerolog.Nop()
+	queue := make(chan *schema.Tweet, 1)
+
+	for id := int64(1); id <= 3; id++ {
+		if err := s.route(&schema.Tweet{Id: id}, queue); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 1 is in memory, the queue is full so 2 and 3 are spilled
+	if got := (<-queue).Id; got != 1 {
+		t.Fatalf("expecting 1 in memory got %v", got)
+	}
+	if s.drainSpill(logctx, 10) {
+		t.Fatal("drain should fail while the store is down")
+	}
+	if sq.Depth() != 2 {
+		t.Fatalf("expecting 2 spilled tweets got %v", sq.Depth())
+	}
+	// the queue has room, but new tweets go after the spilled ones
+	if err := s.route(&schema.Tweet{Id: 4}, queue); err != nil {
+		t.Fatal(err)
+	}
+	if len(queue) != 0 {
+		t.Fatal("tweet skipped the spill file")
+	}
+
+	store.down = false
+	for sq.Depth() > 0 {
+		if !s.drainSpill(logctx, 2) {
+			t.Fatal("unable to drain spill")
+		}
+	}
+	expectIDs(t, store.ids(), 2, 3, 4)
+
+	if err := s.route(&schema.Tweet{Id: 5}, queue); err != nil {
+		t.Fatal(err)
+	}
+	if len(queue) != 1 {
+		t.Fatal("expecting new tweets in memory once the spill is drained")
+	}
+}
+
+func TestSpillRestartDuringDrain(t *testing.T) {
+	dir := tempDir(t)
+	sq, err := openSpill(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushSpill(t, sq, 1, 2, 3, 4, 5)
+	ids, next := peekIDs(t, sq, 2)
+	expectIDs(t, ids, 1, 2)
+	if err := sq.Commit(next, len(ids)); err != nil {
+		t.Fatal(err)
+	}
+	// 3 and 4 were read but not committed when the process stopped
+	ids, _ = peekIDs(t, sq, 2)
+	expectIDs(t, ids, 3, 4)
+	sq.Close()
+
+	sq, err = openSpill(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sq.Depth() != 3 {
+		t.Fatalf("expecting 3 tweets after restart got %v", sq.Depth())
+	}
+	pushSpill(t, sq, 6)
+	ids, next = peekIDs(t, sq, 10)
+	expectIDs(t, ids, 3, 4, 5, 6)
+	if err := sq.Commit(next, len(ids)); err != nil {
+		t.Fatal(err)
+	}
+	sq.Close()
+
+	sq, err = openSpill(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sq.Close()
+	if sq.Depth() != 0 {
+		t.Fatalf("drained tweets came back after restart: %v", sq.Depth())
+	}
+	pushSpill(t, sq, 7)
+	ids, _ = peekIDs(t, sq, 10)
+	expectIDs(t, ids, 7)
+}
+
+func TestSpillStaleOffset(t *testing.T) {
+	dir := tempDir(t)
+	sq, err := openSpill(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushSpill(t, sq, 1, 2)
+	sq.Close()
+	// drained and truncated, but the offset file wasn't updated
+	if err := os.Truncate(filepath.Join(dir, "spill.log"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "spill.offset"), []byte("1000"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sq, err = openSpill(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sq.Close()
+	pushSpill(t, sq, 3)
+	ids, _ := peekIDs(t, sq, 10)
+	expectIDs(t, ids, 3)
+	if _, err := os.Stat(filepath.Join(dir, "spill.offset.tmp")); !os.IsNotExist(err) {
+		t.Fatalf("temporary offset file left behind: %v", err)
+	}
+}
+
+func TestSpillQuarantine(t *testing.T) {
+	keys, err := ParseKeyring(testKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := tempDir(t)
+	sq, err := openSpill(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sq.Close()
+	// 2 is encrypted with a key the queue doesn't have
+	pushSpill(t, sq, 1)
+	sq.keys = keys
+	pushSpill(t, sq, 2)
+	sq.keys = nil
+	pushSpill(t, sq, 3)
+
+	ids, _ := peekIDs(t, sq, 10)
+	expectIDs(t, ids, 1)
+
+	store := &memoryStore{}
+	s := &Server{log: store, spill: sq}
+	for i := 0; sq.Depth() > 0; i++ {
+		if i > 3 || !s.drainSpill(zerolog.Nop(), 10) {
+			t.Fatalf("unable to drain spill, %v tweets left", sq.Depth())
+		}
+	}
+	expectIDs(t, store.ids(), 1, 3)
+
+	f, err := os.Open(filepath.Join(dir, "spill.quarantine"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	in := bufio.NewReader(f)
+	record, err := readSpillRecord(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw, err := decodeEntry(record, keys)
+	if err != nil || tw.Id != 2 {
+		t.Fatalf("expecting tweet 2 in the quarantine got %v (%v)", tw, err)
+	}
+	if _, err := readSpillRecord(in); err != io.EOF {
+		t.Fatalf("expecting a single quarantined record got %v", err)
+	}
+}
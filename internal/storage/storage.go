@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/andrebq/vogelnest/internal/schema"
@@ -18,12 +19,44 @@ import (
 )
 
 type (
-	// TweetLogWriter writes tweets to a boltdb
+	// TweetStore keeps tweets ordered by the moment they were appended
+	TweetStore interface {
+		// Append entries as a single batch
+		Append(entries ...*schema.Tweet) error
+		// ReadRange calls fn for every tweet appended in the ten minute
+		// windows which overlap [from, to). Windows are read in order,
+		// inside a window badger returns tweets by id and segments in
+		// the order they were appended
+		ReadRange(from, to time.Time, fn func(*schema.Tweet) error) error
+		// Close releases any resource held by the store
+		Close() error
+	}
+
+	// StoreOptions are shared by every TweetStore implementation
+	StoreOptions struct {
+		// SyncWrites waits for data to reach the disk before
+		// Append returns
+		SyncWrites bool
+		// Keys encrypts entries, when nil entries are kept in plain text
+		Keys *Keyring
+		// Tier fetches partitions which were moved to a remote,
+		// when nil only local partitions are read
+		Tier *Tier
+	}
+
+	// TweetLogWriter writes tweets to one badger database per hour
 	TweetLogWriter struct {
+		sync.Mutex
+
 		// directory where data is kept
+		basedir    string
+		opts       StoreOptions
+		activehour time.Time
 		activefile string
 
 		activedb *badger.DB
+		closed   bool
+		reader   *TweetLogReader
 
 		entriesWritten prometheus.Counter
 		bytesWritten   prometheus.Counter
@@ -39,10 +72,18 @@ type (
 	}
 )
 
+const (
+	// partitionFormat is used to name the data kept for each hour
+	partitionFormat = "2006-01-02_15"
+)
+
 var (
 	// ErrClosed is sent when the user tries to write to a closed log
 	ErrClosed = errors.New("already closed")
 
+	// ErrInvalidKey is returned when a key doesn't follow the LogEntryKey layout
+	ErrInvalidKey = errors.New("invalid log entry key")
+
 	bytesWrittenVec = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name:      "bytesWritten",
 		Namespace: "vogelnest",
@@ -60,45 +101,58 @@ func init() {
 	prometheus.MustRegister(bytesWrittenVec, entriesWrittenVec)
 }
 
-// NewLog takes a directory and creates one WAL file 15 minutes
-func NewLog(dir string) (*TweetLogWriter, error) {
-	now := time.Now().Truncate(time.Hour)
-	activefile := filepath.Join(dir, "tweetlog", now.Format("2006-01-02_15"))
-	err := os.MkdirAll(activefile, 0755)
+// OpenStore returns the TweetStore implementation named by backend
+// keeping its data under dir
+func OpenStore(backend, dir string, opts StoreOptions) (TweetStore, error) {
+	switch backend {
+	case "badger":
+		return NewLog(dir, opts)
+	case "segment":
+		return NewSegmentLog(dir, opts)
+	}
+	return nil, fmt.Errorf("unknown storage backend: %v", backend)
+}
+
+// NewLog takes a directory and creates one badger database per hour
+func NewLog(dir string, opts StoreOptions) (*TweetLogWriter, error) {
+	err := recoverMigrations(dir)
 	if err != nil {
 		return nil, err
 	}
-	opts := badger.DefaultOptions(activefile)
-	opts.Logger = &badgerLogger{log.Logger.With().Str("module", "tweet-log-writer").Str("db", filepath.Base(activefile)).Logger()}
-	db, err := badger.Open(opts)
+	tl := &TweetLogWriter{basedir: dir, opts: opts, reader: NewLogReader(dir, opts)}
+	err = tl.rotate(time.Now())
 	if err != nil {
 		return nil, err
 	}
-	return &TweetLogWriter{
-		activefile: activefile,
-		activedb:   db,
-
-		entriesWritten: entriesWrittenVec.With(prometheus.Labels{"activeFile": filepath.Base(activefile)}),
-		bytesWritten:   bytesWrittenVec.With(prometheus.Labels{"activeFile": filepath.Base(activefile)}),
-	}, nil
+	return tl, nil
 }
 
 // Close the underlying file, it is safe to call
 // multiple times as only the first time will actually
 // interact with the underlying fs
 func (tl *TweetLogWriter) Close() error {
-	if tl.activedb == nil {
+	tl.Lock()
+	defer tl.Unlock()
+	if tl.closed {
 		return nil
 	}
+	tl.closed = true
 	return tl.activedb.Close()
 }
 
 // Append an entry to the log
 func (tl *TweetLogWriter) Append(entries ...*schema.Tweet) error {
-	if tl.activedb == nil {
+	tl.Lock()
+	defer tl.Unlock()
+	if tl.closed {
 		return ErrClosed
 	}
 
+	err := tl.rotate(time.Now())
+	if err != nil {
+		return err
+	}
+
 	now := time.Now().Truncate(time.Minute * 10).Unix()
 
 	bw := tl.activedb.NewWriteBatch()
@@ -110,15 +164,11 @@ func (tl *TweetLogWriter) Append(entries ...*schema.Tweet) error {
 	totalBytes := float64(0)
 	totalEntries := float64(0)
 	for _, e := range entries {
-		buf, err := proto.Marshal(e)
+		buf, err := encodeEntry(e, tl.opts.Keys)
 		if err != nil {
-			return fmt.Errorf("unable to encode message: %w", err)
+			return err
 		}
 
-		// wasting memory here, could re-use a temporary buffer
-		// or sync.pool
-		buf = snappy.Encode(nil, buf)
-
 		var lek LogEntryKey
 		lek.Set(now, e)
 		err = bw.Set(lek.buf[:], buf)
@@ -128,7 +178,7 @@ func (tl *TweetLogWriter) Append(entries ...*schema.Tweet) error {
 		totalBytes += float64(len(buf))
 		totalEntries++
 	}
-	err := bw.Flush()
+	err = bw.Flush()
 	if err != nil {
 		return fmt.Errorf("unable to save data to disk: %v", err)
 	}
@@ -138,10 +188,126 @@ func (tl *TweetLogWriter) Append(entries ...*schema.Tweet) error {
 	return nil
 }
 
+// ReadRange implements TweetStore, hours other than the active one
+// are read by a TweetLogReader
+func (tl *TweetLogWriter) ReadRange(from, to time.Time, fn func(*schema.Tweet) error) error {
+	return forEachHour(from, to, func(hour time.Time) error {
+		tl.Lock()
+		if tl.closed {
+			tl.Unlock()
+			return ErrClosed
+		}
+		if hour.Equal(tl.activehour) {
+			db := tl.activedb
+			tl.Unlock()
+			return readLogDB(db, tl.opts.Keys, from, to, fn)
+		}
+		tl.Unlock()
+		return tl.reader.readHour(hour, from, to, fn)
+	})
+}
+
+// rotate closes the active database if it doesn't match the hour of now
+// and opens a new one
+func (tl *TweetLogWriter) rotate(now time.Time) error {
+	hour := now.Truncate(time.Hour)
+	if tl.activedb != nil && hour.Equal(tl.activehour) {
+		return nil
+	}
+	activefile := filepath.Join(tl.basedir, "tweetlog", hour.Format(partitionFormat))
+	err := os.MkdirAll(activefile, 0755)
+	if err != nil {
+		return err
+	}
+	opts := badger.DefaultOptions(activefile)
+	opts.SyncWrites = tl.opts.SyncWrites
+	opts.Logger = &badgerLogger{log.Logger.With().Str("module", "tweet-log-writer").Str("db", filepath.Base(activefile)).Logger()}
+	db, err := badger.Open(opts)
+	if err != nil {
+		return err
+	}
+	err = ensureLayout(db)
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("unable to use %v: %w", filepath.Base(activefile), err)
+	}
+	if tl.activedb != nil {
+		err = tl.activedb.Close()
+		if err != nil {
+			log.Error().Err(err).Str("module", "tweet-log-writer").Str("db", filepath.Base(tl.activefile)).Msg("Unable to close previous hour")
+		}
+	}
+	tl.activehour = hour
+	tl.activefile = activefile
+	tl.activedb = db
+	tl.entriesWritten = entriesWrittenVec.With(prometheus.Labels{"activeFile": filepath.Base(activefile)})
+	tl.bytesWritten = bytesWrittenVec.With(prometheus.Labels{"activeFile": filepath.Base(activefile)})
+	return nil
+}
+
 // Set the content of this key
 func (l *LogEntryKey) Set(moment int64, e *schema.Tweet) {
+	l.setMoment(moment)
+	binary.BigEndian.PutUint64(l.buf[1+8+1:], uint64(e.Id))
+}
+
+func (l *LogEntryKey) setMoment(moment int64) {
 	l.buf[0] = byte('l')
 	binary.BigEndian.PutUint64(l.buf[1:], uint64(moment))
 	l.buf[9] = byte('/')
-	binary.BigEndian.PutUint64(l.buf[1+8+1:], uint64(e.Id))
+}
+
+// Parse copies b into l after checking it follows the
+// 'l' <moment> '/' <id> layout
+func (l *LogEntryKey) Parse(b []byte) error {
+	switch {
+	case len(b) != len(l.buf):
+		return fmt.Errorf("%w: expecting %v bytes got %v", ErrInvalidKey, len(l.buf), len(b))
+	case b[0] != byte('l'):
+		return fmt.Errorf("%w: missing 'l' prefix", ErrInvalidKey)
+	case b[9] != byte('/'):
+		return fmt.Errorf("%w: missing '/' separator", ErrInvalidKey)
+	}
+	copy(l.buf[:], b)
+	return nil
+}
+
+// Moment returns the unix timestamp (in seconds) when the entry was appended
+func (l *LogEntryKey) Moment() int64 {
+	return int64(binary.BigEndian.Uint64(l.buf[1:]))
+}
+
+// ID returns the tweet id
+func (l *LogEntryKey) ID() int64 {
+	return int64(binary.BigEndian.Uint64(l.buf[1+8+1:]))
+}
+
+// encodeEntry returns the snappy compressed protobuf
+// representation of t, encrypted when keys isn't nil
+func encodeEntry(t *schema.Tweet, keys *Keyring) ([]byte, error) {
+	buf, err := proto.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode message: %w", err)
+	}
+	// wasting memory here, could re-use a temporary buffer
+	// or sync.pool
+	return keys.seal(snappy.Encode(nil, buf))
+}
+
+// decodeEntry reverses encodeEntry
+func decodeEntry(buf []byte, keys *Keyring) (*schema.Tweet, error) {
+	buf, err := keys.open(buf)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := snappy.Decode(nil, buf)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress entry: %w", err)
+	}
+	var t schema.Tweet
+	err = proto.Unmarshal(raw, &t)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode message: %w", err)
+	}
+	return &t, nil
 }
@@ -1,35 +1,91 @@
 package storage
 
 import (
+	"errors"
 	"time"
 
 	"github.com/andrebq/vogelnest/internal/schema"
 	"github.com/andrebq/vogelnest/internal/tweets"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
 type (
+	// Server reads tweets from a stream and saves them to a TweetStore
+	//
+	// Tweets which arrive while the store is busy are kept in memory,
+	// once that is full they go to a spill file and are saved
+	// in order when the store catches up.
 	Server struct {
-		log *TweetLogWriter
+		log   TweetStore
+		spill *spillQueue
+		opts  ServerOptions
 
 		stream *tweets.Stream
+		// hour of the last append
+		hour time.Time
 
 		done chan struct{}
 		stop chan struct{}
 	}
+
+	// ServerOptions controls how tweets are grouped before
+	// being written to the store
+	ServerOptions struct {
+		// SpillDir holds tweets while the store falls behind
+		SpillDir string
+		// BatchSize is the maximum number of tweets written at once
+		BatchSize int
+		// FlushInterval is the maximum time a tweet waits in memory
+		FlushInterval time.Duration
+		// Keys encrypts the spill file, it should match the store
+		Keys *Keyring
+		// Guard drops or samples tweets when the disk is under pressure,
+		// when nil every tweet is kept
+		Guard *Guard
+	}
 )
 
-// NewServer saving files to basedir and reading content from stream
-func NewServer(basedir string, stream *tweets.Stream) (*Server, error) {
-	s := &Server{}
-	var err error
-	s.log, err = NewLog(basedir)
-	s.stream = stream
+var (
+	flushLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:      "flushLatency",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Seconds taken to write one batch to the store",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	})
+	flushErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "flushErrors",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(flushLatency, flushErrors)
+}
+
+// NewServer saving tweets to store and reading content from stream
+//
+// The server takes ownership of store and closes it when stopped
+func NewServer(store TweetStore, stream *tweets.Stream, opts ServerOptions) (*Server, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	spill, err := openSpill(opts.SpillDir, opts.Keys)
 	if err != nil {
 		return nil, err
 	}
-	return s, nil
+	return &Server{
+		log:    store,
+		spill:  spill,
+		opts:   opts,
+		stream: stream,
+	}, nil
 }
 
 func (s *Server) Serve() {
@@ -37,59 +93,202 @@ func (s *Server) Serve() {
 	s.done = make(chan struct{})
 	defer close(s.done)
 
-	// avoid blocking at the cost of more memory
-	// and maybe some data loss if the stream is closed
-	sub := s.stream.NewSink(1000)
-	defer s.stream.RemoveSink(sub)
-
 	logctx := log.Logger.With().Str("service", "storage-server").Logger()
+	defer s.closeTweetLog(logctx)
+
+	// the stream waits for us instead of dropping tweets,
+	// receive must be quick to avoid blocking other sinks
+	sub := s.stream.NewLosslessSink(100)
+	queue := make(chan *schema.Tweet, 1000)
+	receiverDone := make(chan struct{})
+	go s.receive(logctx, sub, queue, receiverDone)
 
-	buf := make([]*schema.Tweet, 0, 100)
+	buf := make([]*schema.Tweet, 0, s.opts.BatchSize)
+	defer func() {
+		s.stream.RemoveSink(sub)
+		<-receiverDone
+		buf = s.flush(logctx, append(buf, drainQueue(queue)...))
+		if len(buf) > 0 {
+			// keep them for the next run, even if out of order
+			logctx.Warn().Str("action", "stop").Int("pending", len(buf)).Msg("Moving pending tweets to the spill file")
+			s.spill.Lock()
+			defer s.spill.Unlock()
+			for _, t := range buf {
+				err := s.spill.push(t)
+				if err != nil {
+					logctx.Error().Err(err).Str("action", "spill").Int64("tweet", t.Id).Msg("Unable to spill tweet")
+				}
+			}
+		}
+	}()
 
-	syncInterval := time.NewTicker(time.Second)
+	syncInterval := time.NewTicker(s.opts.FlushInterval)
 	defer syncInterval.Stop()
 
-	defer s.closeTweetLog(logctx)
 	for {
+		if len(queue) == 0 && s.spill.Depth() > 0 {
+			// only drain the spill once everything in memory
+			// is saved, otherwise tweets would be out of order
+			buf = s.flush(logctx, buf)
+			if len(buf) == 0 && s.drainSpill(logctx, s.opts.BatchSize) {
+				select {
+				case <-s.stop:
+					logctx.Info().Str("action", "stop").Msg("Got signal to stop storage server")
+					return
+				default:
+					continue
+				}
+			}
+		}
+		in := queue
+		if len(buf) >= s.opts.BatchSize {
+			// the last flush failed, wait for the next tick to try again
+			// while new tweets go to the spill file
+			in = nil
+		}
 		select {
 		case <-syncInterval.C:
 			buf = s.flush(logctx, buf)
-			continue
 		case <-s.stop:
-			buf = s.flush(logctx, buf)
 			logctx.Info().Str("action", "stop").Msg("Got signal to stop storage server")
 			return
-		case t, open := <-sub:
+		case <-receiverDone:
+			logctx.Warn().Str("action", "subscription-closed").Msg("Input stream closed. There won't be any new messages")
+			return
+		case st := <-in:
+			buf = append(buf, st)
+			if len(buf) >= s.opts.BatchSize {
+				buf = s.flush(logctx, buf)
+			}
+		}
+	}
+}
+
+// receive sends tweets from sub to queue,
+// if the queue is full (or already spilling) they go to the spill file
+func (s *Server) receive(logctx zerolog.Logger, sub <-chan *schema.Tweet, queue chan<- *schema.Tweet, done chan<- struct{}) {
+	defer close(done)
+	for {
+		var t *schema.Tweet
+		var open bool
+		select {
+		case t, open = <-sub:
 			if !open {
-				s.flush(logctx, buf)
-				logctx.Warn().Str("action", "subscription-closed").Msg("Input stream closed. There won't be any new messages")
 				return
 			}
-			st := schema.Tweet{}
-			err := st.Populate(t)
-			if err != nil {
-				logctx.Error().Err(err).Str("action", "populate").Msg("Unable to process tweet")
-				continue
-			}
-			buf = append(buf, &st)
-			if len(buf) == 100 {
-				buf = s.flush(logctx, buf)
-			}
+		case <-s.stop:
+			return
 		}
+		if !s.opts.Guard.Admit() {
+			continue
+		}
+		err := s.route(t, queue)
+		if err != nil {
+			logctx.Error().Err(err).Str("action", "spill").Int64("tweet", t.Id).Msg("Unable to spill tweet")
+		}
+	}
+}
+
+func (s *Server) route(t *schema.Tweet, queue chan<- *schema.Tweet) error {
+	s.spill.Lock()
+	defer s.spill.Unlock()
+	if s.spill.depth == 0 {
+		select {
+		case queue <- t:
+			return nil
+		default:
+		}
+	}
+	if s.opts.Guard.Degraded() {
+		// the spill file lives on the same disk
+		degradedDropped.Inc()
+		return nil
 	}
+	return s.spill.push(t)
 }
 
+// drainSpill moves up to max tweets from the spill file to the store
+// and returns false if that wasn't possible
+func (s *Server) drainSpill(ctx zerolog.Logger, max int) bool {
+	batch, next, err := s.spill.Peek(max)
+	if errors.Is(err, errUndecodableSpill) {
+		// retrying would block the queue forever
+		ctx.Error().Err(err).Str("action", "quarantine-spill").Msg("Moving undecodable tweet to the spill quarantine")
+		err = s.spill.Quarantine()
+		if err != nil {
+			ctx.Error().Err(err).Str("action", "quarantine-spill").Msg("Unable to quarantine spill record")
+			return false
+		}
+		return true
+	}
+	if err != nil {
+		ctx.Error().Err(err).Str("action", "drain-spill").Msg("Unable to read spill file")
+		return false
+	}
+	err = s.append(batch)
+	if err != nil {
+		ctx.Error().Err(err).Str("action", "drain-spill").Send()
+		return false
+	}
+	err = s.spill.Commit(next, len(batch))
+	if err != nil {
+		ctx.Error().Err(err).Str("action", "drain-spill").Msg("Unable to update spill file")
+		return false
+	}
+	return true
+}
+
+func drainQueue(queue <-chan *schema.Tweet) []*schema.Tweet {
+	var out []*schema.Tweet
+	for {
+		select {
+		case t := <-queue:
+			out = append(out, t)
+		default:
+			return out
+		}
+	}
+}
+
+// flush writes buf to the store and returns it empty,
+// if the write fails buf is returned unchanged so the same
+// tweets are written by the next flush
 func (s *Server) flush(ctx zerolog.Logger, buf []*schema.Tweet) []*schema.Tweet {
-	err := s.log.Append(buf...)
+	if len(buf) == 0 {
+		if !s.hour.Equal(time.Now().Truncate(time.Hour)) {
+			// an empty append rotates the store, so the partition
+			// of a quiet hour is closed and can be moved or removed
+			s.append(nil)
+		}
+		return buf
+	}
+	err := s.append(buf)
 	if err != nil {
-		ctx.Error().Err(err).Str("action", "flush").Send()
+		ctx.Error().Err(err).Str("action", "flush").Int("pending", len(buf)).Send()
+		return buf
 	}
 	// clear it
-	return buf[:]
+	return buf[:0]
+}
+
+func (s *Server) append(batch []*schema.Tweet) error {
+	start := time.Now()
+	err := s.log.Append(batch...)
+	flushLatency.Observe(time.Since(start).Seconds())
+	if err != nil {
+		flushErrors.Inc()
+		return err
+	}
+	s.hour = start.Truncate(time.Hour)
+	return nil
 }
 
 func (s *Server) closeTweetLog(ctx zerolog.Logger) {
-	err := s.log.Close()
+	err := s.spill.Close()
+	if err != nil {
+		ctx.Error().Err(err).Str("action", "close-spill").Msg("Unable to close spill file")
+	}
+	err = s.log.Close()
 	if err != nil {
 		ctx.Error().Err(err).Str("action", "close-tweet-log").Msg("Unable to close tweet log")
 		return
@@ -0,0 +1,392 @@
+package storage
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// TierOptions controls how partitions move to a Remote
+	TierOptions struct {
+		// Dir is the storage directory
+		Dir string
+		// Remote receives sealed partitions
+		Remote Remote
+		// Grace is how long partitions are kept locally after
+		// being uploaded
+		Grace time.Duration
+		// CacheSize limits the bytes used by partitions downloaded
+		// for reading
+		CacheSize int64
+		// Interval between uploads
+		Interval time.Duration
+	}
+
+	// Tier is a suture service which uploads sealed partitions to
+	// a Remote and removes them from the local disk once Grace is over.
+	//
+	// Readers use it to download partitions which are not
+	// on the local disk anymore, they are kept under Dir/cache
+	Tier struct {
+		opts   TierOptions
+		logCtx zerolog.Logger
+
+		// cache serializes downloads and evictions
+		cache sync.Mutex
+
+		stop chan struct{}
+		done chan struct{}
+	}
+)
+
+var (
+	tierUploaded = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "tierUploaded",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Partitions uploaded to the remote",
+	})
+	tierUploadErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "tierUploadErrors",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+	})
+	tierFetched = prometheus.NewCounter(prometheus.CounterOpts{
+		Name:      "tierFetched",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Partitions downloaded from the remote to be read",
+	})
+	tierCacheBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "tierCacheBytes",
+		Namespace: "vogelnest",
+		Subsystem: "storage",
+		Help:      "Bytes used by partitions downloaded from the remote",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(tierUploaded, tierUploadErrors, tierFetched, tierCacheBytes)
+}
+
+// NewTier returns a tier for the given options
+func NewTier(opts TierOptions) *Tier {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.CacheSize <= 0 {
+		opts.CacheSize = 1 << 30
+	}
+	return &Tier{
+		opts:   opts,
+		logCtx: log.With().Str("service", "storage-tier").Logger(),
+	}
+}
+
+// Serve uploads partitions until Stop is called
+func (t *Tier) Serve() {
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.opts.Interval)
+	defer ticker.Stop()
+	for {
+		err := t.sync(time.Now())
+		if err != nil {
+			t.logCtx.Error().Err(err).Str("action", "sync").Send()
+		}
+		select {
+		case <-ticker.C:
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// Stop the service
+func (t *Tier) Stop() {
+	close(t.stop)
+	<-t.done
+}
+
+func (t *Tier) String() string { return "storage-tier" }
+
+// sync uploads sealed partitions and removes the ones
+// uploaded more than Grace ago
+func (t *Tier) sync(now time.Time) error {
+	parts, err := listPartitions(t.opts.Dir)
+	if err != nil {
+		return err
+	}
+	for _, p := range parts {
+		if !p.sealed(now) {
+			break
+		}
+		select {
+		case <-t.stop:
+			return nil
+		default:
+		}
+		marker := t.marker(p)
+		st, err := os.Stat(marker)
+		if os.IsNotExist(err) {
+			err = t.upload(p, marker)
+			if err != nil {
+				tierUploadErrors.Inc()
+				t.logCtx.Error().Err(err).Str("action", "upload").Str("path", p.path).Send()
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		if now.Sub(st.ModTime()) < t.opts.Grace {
+			continue
+		}
+		err = os.RemoveAll(p.path)
+		if err != nil {
+			return err
+		}
+		os.Remove(marker)
+		t.logCtx.Info().Str("action", "remove-local").Str("path", p.path).Send()
+	}
+	return nil
+}
+
+// marker is the file created once p is uploaded
+func (t *Tier) marker(p partition) string {
+	return filepath.Join(t.opts.Dir, "tier", p.object())
+}
+
+// uploaded returns true if p is safe to remove from the local disk
+// because the remote already has a copy
+func (t *Tier) uploaded(p partition) bool {
+	_, err := os.Stat(t.marker(p))
+	return err == nil
+}
+
+// upload sends p to the remote and creates marker once it is done
+func (t *Tier) upload(p partition, marker string) error {
+	file := p.path
+	if p.isDir() {
+		tmp, err := ioutil.TempFile(t.opts.Dir, "upload-*.tar")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		err = writeTar(tmp, p.path)
+		if cerr := tmp.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		file = tmp.Name()
+	}
+	err := t.opts.Remote.Upload(p.object(), file)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(marker), 0755)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(marker, nil, 0644)
+	if err != nil {
+		return err
+	}
+	tierUploaded.Inc()
+	t.logCtx.Info().Str("action", "upload").Str("path", p.path).Send()
+	return nil
+}
+
+// fetch returns the local copy of p downloaded from the remote,
+// or an empty string if the remote doesn't have it
+func (t *Tier) fetch(p partition) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+	t.cache.Lock()
+	defer t.cache.Unlock()
+
+	local := filepath.Join(t.opts.Dir, "cache", filepath.Base(filepath.Dir(p.path)), filepath.Base(p.path))
+	if _, err := os.Stat(local); err == nil {
+		now := time.Now()
+		os.Chtimes(local, now, now)
+		return local, nil
+	}
+	err := os.MkdirAll(filepath.Dir(local), 0755)
+	if err != nil {
+		return "", err
+	}
+	tmp := local + ".download"
+	defer os.Remove(tmp)
+	err = t.opts.Remote.Download(p.object(), tmp)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("unable to download %v: %w", p.object(), err)
+	}
+	if p.isDir() {
+		err = readTar(tmp, local)
+	} else {
+		err = os.Rename(tmp, local)
+	}
+	if err != nil {
+		os.RemoveAll(local)
+		return "", err
+	}
+	tierFetched.Inc()
+	t.evict(local)
+	return local, nil
+}
+
+// evict removes the least recently used partitions from the cache,
+// except keep, until it fits CacheSize
+func (t *Tier) evict(keep string) {
+	type cached struct {
+		path string
+		used time.Time
+		size int64
+	}
+	var entries []cached
+	var total int64
+	for _, dir := range []string{"tweetlog", "segments"} {
+		infos, err := ioutil.ReadDir(filepath.Join(t.opts.Dir, "cache", dir))
+		if err != nil {
+			continue
+		}
+		for _, info := range infos {
+			p := filepath.Join(t.opts.Dir, "cache", dir, info.Name())
+			size, err := dirSize(p)
+			if err != nil {
+				continue
+			}
+			entries = append(entries, cached{path: p, used: info.ModTime(), size: size})
+			total += size
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].used.Before(entries[j].used) })
+	for _, e := range entries {
+		if total <= t.opts.CacheSize {
+			break
+		}
+		if e.path == keep {
+			continue
+		}
+		err := os.RemoveAll(e.path)
+		if err != nil {
+			t.logCtx.Error().Err(err).Str("action", "evict").Str("path", e.path).Send()
+			continue
+		}
+		total -= e.size
+	}
+	tierCacheBytes.Set(float64(total))
+}
+
+// object is the name used by p in the remote
+func (p partition) object() string {
+	name := filepath.Base(p.path)
+	if p.isDir() {
+		name += ".tar"
+	}
+	return filepath.Base(filepath.Dir(p.path)) + "/" + name
+}
+
+// isDir returns true for partitions kept as a directory (badger)
+func (p partition) isDir() bool {
+	return filepath.Ext(p.path) == ""
+}
+
+// locate returns the path to the partition of hour, downloading it from
+// the remote when it isn't on the local disk. The path is empty if the
+// partition doesn't exist
+func locate(local string, hour time.Time, tier *Tier) (string, error) {
+	if _, err := os.Stat(local); err == nil {
+		return local, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	return tier.fetch(partition{hour: hour, path: local})
+}
+
+// writeTar writes the files of dir, which must not have
+// subdirectories, to out
+func writeTar(out io.Writer, dir string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(out)
+	for _, info := range infos {
+		if info.IsDir() || info.Name() == "LOCK" {
+			continue
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		err = tw.WriteHeader(hdr)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// readTar extracts the archive file into dir
+func readTar(file, dir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		out, err := os.OpenFile(filepath.Join(dir, filepath.Base(hdr.Name)), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, tr)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
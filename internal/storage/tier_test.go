@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+type (
+	// memoryRemote is a Remote which keeps objects in memory
+	memoryRemote struct {
+		sync.Mutex
+		objects map[string][]byte
+		uploads int
+		err     error
+	}
+)
+
+func (m *memoryRemote) Upload(name, file string) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if m.objects == nil {
+		m.objects = make(map[string][]byte)
+	}
+	m.objects[name] = buf
+	m.uploads++
+	return nil
+}
+
+func (m *memoryRemote) Download(name, file string) error {
+	m.Lock()
+	defer m.Unlock()
+	buf, ok := m.objects[name]
+	if !ok {
+		return ErrNotFound
+	}
+	return ioutil.WriteFile(file, buf, 0644)
+}
+
+func (m *memoryRemote) uploaded() int {
+	m.Lock()
+	defer m.Unlock()
+	return m.uploads
+}
+
+func exists(t *testing.T, file string) bool {
+	t.Helper()
+	_, err := os.Stat(file)
+	if os.IsNotExist(err) {
+		return false
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return true
+}
+
+func TestTierUpload(t *testing.T) {
+	dir := tempDir(t)
+	file := segmentFile(dir, segmentHour)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeSegment(t, file, true, 1, 2, 3)
+
+	remote := &memoryRemote{}
+	tier := NewTier(TierOptions{Dir: dir, Remote: remote, Grace: time.Hour})
+	now := time.Now()
+	if err := tier.sync(now); err != nil {
+		t.Fatal(err)
+	}
+	if remote.uploaded() != 1 {
+		t.Fatalf("expecting 1 upload got %v", remote.uploaded())
+	}
+	if !exists(t, file) {
+		t.Fatal("partition removed before the grace period")
+	}
+
+	// the marker skips partitions which were already uploaded
+	if err := tier.sync(now); err != nil {
+		t.Fatal(err)
+	}
+	if remote.uploaded() != 1 {
+		t.Fatalf("partition uploaded again, %v uploads", remote.uploaded())
+	}
+
+	if err := tier.sync(now.Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if exists(t, file) {
+		t.Fatal("partition should be removed after the grace period")
+	}
+
+	var ids []int64
+	reader := NewSegmentReader(dir, StoreOptions{Tier: tier})
+	err := reader.ReadRange(segmentHour, segmentHour.Add(time.Hour), func(t *schema.Tweet) error {
+		ids = append(ids, t.Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids, 1, 2, 3)
+	if exists(t, file) {
+		t.Fatal("downloads should go to the cache")
+	}
+
+	// hours which the remote doesn't have are empty
+	ids = nil
+	err = reader.ReadRange(segmentHour.Add(time.Hour), segmentHour.Add(2*time.Hour), func(t *schema.Tweet) error {
+		ids = append(ids, t.Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ids)
+}
+
+func TestGuardKeepsPartitionsNotUploaded(t *testing.T) {
+	dir := tempDir(t)
+	file := segmentFile(dir, segmentHour)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeSegment(t, file, true, 1)
+
+	remote := &memoryRemote{err: errors.New("remote is down")}
+	tier := NewTier(TierOptions{Dir: dir, Remote: remote, Grace: time.Hour})
+	guard := NewGuard(GuardOptions{Dir: dir, Retention: time.Hour, Tier: tier})
+	now := time.Now()
+	if err := tier.sync(now); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.enforce(now); err != nil {
+		t.Fatal(err)
+	}
+	if !exists(t, file) {
+		t.Fatal("guard removed a partition which was not uploaded")
+	}
+
+	remote.Lock()
+	remote.err = nil
+	remote.Unlock()
+	if err := tier.sync(now); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.enforce(now); err != nil {
+		t.Fatal(err)
+	}
+	if exists(t, file) {
+		t.Fatal("guard should remove uploaded partitions past retention")
+	}
+}
+
+func TestTierDirectoryPartition(t *testing.T) {
+	dir := tempDir(t)
+	db := filepath.Join(dir, "tweetlog", segmentHour.Format(partitionFormat))
+	if err := os.MkdirAll(db, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{"000001.vlog": "values", "MANIFEST": "manifest"}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(db, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	remote := &memoryRemote{}
+	tier := NewTier(TierOptions{Dir: dir, Remote: remote})
+	if err := tier.sync(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := remote.objects["tweetlog/"+segmentHour.Format(partitionFormat)+".tar"]; !ok || remote.uploaded() != 1 {
+		t.Fatalf("expecting a single tar object, got %v uploads", remote.uploaded())
+	}
+	if err := tier.sync(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if exists(t, db) {
+		t.Fatal("partition should be removed without a grace period")
+	}
+
+	local, err := locate(db, segmentHour, tier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local == "" || local == db {
+		t.Fatalf("expecting a cached copy, got %q", local)
+	}
+	for name, content := range files {
+		buf, err := ioutil.ReadFile(filepath.Join(local, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != content {
+			t.Errorf("%v: expecting %q got %q", name, content, buf)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package text
+
+import (
+	"unicode"
+)
+
+type (
+	// segment is a piece of text which is either a word or an emoji
+	segment struct {
+		text  string
+		emoji bool
+	}
+)
+
+const (
+	zwj               = '\u200d'
+	variationSelector = '\ufe0f'
+)
+
+// segments splits s into words and emoji, everything else
+// (spaces, punctuation and symbols) separates segments.
+//
+// This is a simplified version of the Unicode word boundaries (UAX #29):
+// letters, marks and digits form words, apostrophes are kept inside words
+// and Han characters, which are written without spaces, produce one
+// segment each.
+func segments(s string) []segment {
+	var out []segment
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isEmoji(r):
+			end := emojiEnd(runes, i)
+			out = append(out, segment{text: string(runes[i:end]), emoji: true})
+			i = end
+		case isIdeographic(r):
+			out = append(out, segment{text: string(r)})
+			i++
+		case isWord(r):
+			end := i + 1
+			for end < len(runes) {
+				if isWord(runes[end]) && !isIdeographic(runes[end]) {
+					end++
+				} else if isApostrophe(runes[end]) && end+1 < len(runes) && isWord(runes[end+1]) && !isIdeographic(runes[end+1]) {
+					end += 2
+				} else {
+					break
+				}
+			}
+			out = append(out, segment{text: string(runes[i:end])})
+			i = end
+		default:
+			i++
+		}
+	}
+	return out
+}
+
+// emojiEnd returns the end of the emoji sequence starting at i,
+// which includes modifiers, ZWJ sequences and flags
+func emojiEnd(runes []rune, i int) int {
+	if isRegionalIndicator(runes[i]) {
+		if i+1 < len(runes) && isRegionalIndicator(runes[i+1]) {
+			return i + 2
+		}
+		return i + 1
+	}
+	end := i + 1
+	for end < len(runes) {
+		switch r := runes[end]; {
+		case r == variationSelector || isSkinTone(r):
+			end++
+		case r == zwj && end+1 < len(runes) && isEmoji(runes[end+1]):
+			end += 2
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r)
+}
+
+func isIdeographic(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '\u2019'
+}
+
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1f000 && r <= 0x1faff:
+		// mahjong tiles up to symbols and pictographs extended-a
+		return !isSkinTone(r)
+	case r >= 0x2600 && r <= 0x27bf:
+		// miscellaneous symbols and dingbats
+		return true
+	case r >= 0x2b00 && r <= 0x2bff && unicode.Is(unicode.So, r):
+		return true
+	}
+	return false
+}
+
+func isSkinTone(r rune) bool {
+	return r >= 0x1f3fb && r <= 0x1f3ff
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
@@ -0,0 +1,80 @@
+package text
+
+import (
+	"strings"
+)
+
+// defaultStopWords has the most common words of each language,
+// which carry little meaning on their own. Lists are casefolded.
+var defaultStopWords = map[string]string{
+	"en": `a about above after again against all am an and any are as at be because
+been before being below between both but by can could did do does doing down
+during each few for from further had has have having he her here hers herself
+him himself his how i if in into is it its itself just me more most my myself
+no nor not now of off on once only or other our ours ourselves out over own
+same she should so some such than that the their theirs them themselves then
+there these they this those through to too under until up very was we were
+what when where which while who whom why will with would you your yours
+yourself yourselves rt amp i'm it's don't can't`,
+
+	"pt": `a ao aos aquela aquelas aquele aqueles aquilo as até com como da das de
+dela delas dele deles depois do dos e ela elas ele eles em entre era eram essa
+essas esse esses esta estas este estes eu foi fomos for foram há isso isto já
+lhe lhes mais mas me mesmo meu meus minha minhas muito na nas nem no nos nós
+nossa nossas nosso nossos num numa não o os ou para pela pelas pelo pelos por
+qual quando que quem se sem ser seu seus sua suas só também te tem tinha tu
+tua tuas um uma você vocês vos é está estão rt q vc pq`,
+
+	"es": `a al algo algunas algunos ante antes como con contra cual cuando de del
+desde donde durante e el ella ellas ellos en entre era es esa esas ese eso esos
+esta estas este esto estos está fue ha hay la las le les lo los me mi mis mucho
+muy más nada ni no nos nosotros o os otra otros para pero poco por porque que
+quien se sea ser si sin sobre su sus también te tiene todo tu tus un una uno
+unos y ya yo él rt`,
+
+	"fr": `a au aux avec ce ces dans de des du elle en est et eux il ils je la le les
+leur lui ma mais me mes moi mon ne nos notre nous on ou où par pas pour qu que
+qui sa se ses son sur ta te tes toi ton tu un une vos votre vous y à été être
+c'est rt`,
+
+	"de": `aber alle als am an auch auf aus bei bin bis das dass dein dem den der des
+die doch du ein eine einem einen einer er es für hat hatte ich ihr im in ist
+ja kann mein mich mit nach nicht noch nur oder sich sie sind so um und uns von
+vor war was wie wir zu zum zur über rt`,
+
+	"it": `a ad al alla alle anche che chi ci come con da dal dalla dei del della di
+e ed era gli ha ho i il in io la le lei lo loro lui ma mi mia mio ne negli nei
+nel nella noi non o per più quello questo se si sono su sua suo tra tu un una
+uno voi è rt`,
+}
+
+// StopWords is a set of words which are removed from the
+// tokens of tweets written in one language
+type StopWords map[string]struct{}
+
+// NewStopWords returns a set with the given words, casefolded
+func NewStopWords(words []string) StopWords {
+	sw := make(StopWords, len(words))
+	for _, w := range words {
+		if w = Normalize(strings.TrimSpace(w)); len(w) > 0 {
+			sw[w] = struct{}{}
+		}
+	}
+	return sw
+}
+
+// Has returns true if w is a stop word
+func (sw StopWords) Has(w string) bool {
+	_, ok := sw[w]
+	return ok
+}
+
+// DefaultStopWords returns the built-in list for lang,
+// or nil if there isn't one
+func DefaultStopWords(lang string) StopWords {
+	words, ok := defaultStopWords[lang]
+	if !ok {
+		return nil
+	}
+	return NewStopWords(strings.Fields(words))
+}
@@ -0,0 +1,135 @@
+// Package text extracts normalized words from tweets
+package text
+
+import (
+	"html"
+	"strings"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+type (
+	// Options controls how tweets are tokenized
+	Options struct {
+		// NGrams adds sequences of up to NGrams consecutive words joined
+		// by a space, values below 2 only produce single words
+		NGrams int
+		// KeepStopWords disables stop-word removal
+		KeepStopWords bool
+		// StopWords replaces the built-in list of the given languages
+		StopWords map[string]StopWords
+	}
+
+	// Tokenizer turns tweets into normalized tokens, it is safe
+	// for concurrent use
+	Tokenizer struct {
+		opts      Options
+		stopWords map[string]StopWords
+	}
+)
+
+// NewTokenizer returns a tokenizer using opts
+func NewTokenizer(opts Options) *Tokenizer {
+	t := &Tokenizer{
+		opts:      opts,
+		stopWords: make(map[string]StopWords),
+	}
+	for lang := range defaultStopWords {
+		t.stopWords[lang] = DefaultStopWords(lang)
+	}
+	for lang, sw := range opts.StopWords {
+		t.stopWords[lang] = sw
+	}
+	return t
+}
+
+// Tokens returns the words and emoji of tw without hashtags, symbols,
+// mentions and URLs, which are already kept as entities.
+//
+// Retweets use the text of the original tweet, as the text of the
+// retweet is truncated
+func (t *Tokenizer) Tokens(tw *schema.Tweet) []string {
+	if tw.Retweet != nil {
+		tw = tw.Retweet
+	}
+	return t.Words(StripEntities(tw.Text, tw.Entities), tw.Lang)
+}
+
+// Words returns the tokens of s, lang selects the list
+// of stop words
+func (t *Tokenizer) Words(s, lang string) []string {
+	var stopWords StopWords
+	if !t.opts.KeepStopWords {
+		if i := strings.IndexByte(lang, '-'); i > 0 {
+			lang = lang[:i]
+		}
+		stopWords = t.stopWords[lang]
+	}
+
+	var tokens []string
+	var words []string
+	for _, seg := range segments(html.UnescapeString(s)) {
+		if seg.emoji {
+			tokens = append(tokens, seg.text)
+			continue
+		}
+		w := Normalize(seg.text)
+		if stopWords.Has(w) {
+			continue
+		}
+		tokens = append(tokens, w)
+		words = append(words, w)
+	}
+	for n := 2; n <= t.opts.NGrams; n++ {
+		for i := 0; i+n <= len(words); i++ {
+			tokens = append(tokens, strings.Join(words[i:i+n], " "))
+		}
+	}
+	return tokens
+}
+
+// Normalize applies NFKC and casefolding to w, typographic
+// apostrophes become ASCII
+func Normalize(w string) string {
+	// casers keep state and cannot be shared
+	return strings.Replace(cases.Fold().String(norm.NFKC.String(w)), "\u2019", "'", -1)
+}
+
+// StripEntities replaces hashtags, symbols, mentions and URLs in s by spaces,
+// entity indices count code points
+func StripEntities(s string, e *schema.Entities) string {
+	if e == nil {
+		return s
+	}
+	runes := []rune(s)
+	blank := func(i *schema.Indices) {
+		if i == nil {
+			return
+		}
+		for p := int(i.Start); p < int(i.End) && p < len(runes); p++ {
+			if p >= 0 {
+				runes[p] = ' '
+			}
+		}
+	}
+	for _, h := range e.Hashtags {
+		blank(h.Indices)
+	}
+	for _, s := range e.Symbols {
+		blank(s.Indices)
+	}
+	for _, m := range e.Mentions {
+		blank(m.Indices)
+	}
+	for _, u := range e.Urls {
+		blank(u.Indices)
+	}
+	for _, m := range e.Media {
+		if m.Url != nil {
+			blank(m.Url.Indices)
+		}
+	}
+	return string(runes)
+}
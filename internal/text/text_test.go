@@ -0,0 +1,154 @@
+package text
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+// indices returns the position of entity in s, counting code points
+// like twitter does
+func indices(s, entity string) *schema.Indices {
+	i := strings.Index(s, entity)
+	if i < 0 {
+		panic("entity not found: " + entity)
+	}
+	start := len([]rune(s[:i]))
+	return &schema.Indices{Start: int32(start), End: int32(start + len([]rune(entity)))}
+}
+
+func TestTokens(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		text     string
+		lang     string
+		entities func(s string) *schema.Entities
+		opts     Options
+		want     []string
+	}{
+		{
+			name: "url",
+			text: "Read this https://t.co/abc123 later",
+			entities: func(s string) *schema.Entities {
+				return &schema.Entities{Urls: []*schema.URLInfo{{Indices: indices(s, "https://t.co/abc123")}}}
+			},
+			want: []string{"read", "later"},
+		},
+		{
+			name: "mention",
+			text: "@Gopher thanks a lot",
+			entities: func(s string) *schema.Entities {
+				return &schema.Entities{Mentions: []*schema.Mention{{ScreenName: "Gopher", Indices: indices(s, "@Gopher")}}}
+			},
+			want: []string{"thanks", "lot"},
+		},
+		{
+			name: "hashtag",
+			text: "Learning #golang today",
+			entities: func(s string) *schema.Entities {
+				return &schema.Entities{Hashtags: []*schema.Hashtag{{Text: "golang", Indices: indices(s, "#golang")}}}
+			},
+			want: []string{"learning", "today"},
+		},
+		{
+			name: "cashtag",
+			text: "$TWTR rallies after hours",
+			entities: func(s string) *schema.Entities {
+				return &schema.Entities{Symbols: []*schema.Symbol{{Text: "TWTR", Indices: indices(s, "$TWTR")}}}
+			},
+			want: []string{"rallies", "hours"},
+		},
+		{
+			name: "entities after emoji",
+			text: "🎉🎉 #launch day",
+			entities: func(s string) *schema.Entities {
+				return &schema.Entities{Hashtags: []*schema.Hashtag{{Text: "launch", Indices: indices(s, "#launch")}}}
+			},
+			want: []string{"🎉", "🎉", "day"},
+		},
+		{
+			name: "emoji",
+			text: "Great 👍🏽 game!🇧🇷 👨‍👩‍👧 ❤️",
+			want: []string{"great", "👍🏽", "game", "🇧🇷", "👨‍👩‍👧", "❤️"},
+		},
+		{
+			name: "cjk",
+			text: "東京タワーに行きました",
+			lang: "ja",
+			want: []string{"東", "京", "タワーに", "行", "きました"},
+		},
+		{
+			name: "hangul",
+			text: "안녕하세요 세계",
+			lang: "ko",
+			want: []string{"안녕하세요", "세계"},
+		},
+		{
+			name: "stop words",
+			text: "The cat and the hat",
+			want: []string{"cat", "hat"},
+		},
+		{
+			name: "stop words by region",
+			text: "O gato e o rato",
+			lang: "pt-BR",
+			want: []string{"gato", "rato"},
+		},
+		{
+			name: "keep stop words",
+			text: "The cat and the hat",
+			opts: Options{KeepStopWords: true},
+			want: []string{"the", "cat", "and", "the", "hat"},
+		},
+		{
+			name: "normalized",
+			text: "Don’t STOP ＦＵＬＬ Straße",
+			want: []string{"stop", "full", "strasse"},
+		},
+		{
+			name: "html entities",
+			text: "Tom &amp; Jerry &lt;3",
+			want: []string{"tom", "jerry", "3"},
+		},
+		{
+			name: "ngrams",
+			text: "big data rocks",
+			opts: Options{NGrams: 2},
+			want: []string{"big", "data", "rocks", "big data", "data rocks"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tw := &schema.Tweet{Text: tc.text, Lang: tc.lang}
+			if tw.Lang == "" {
+				tw.Lang = "en"
+			}
+			if tc.entities != nil {
+				tw.Entities = tc.entities(tc.text)
+			}
+			got := NewTokenizer(tc.opts).Tokens(tw)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expecting %q got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTokensRetweet(t *testing.T) {
+	text := "RT @author: Truncated…"
+	original := "Full text of the #original"
+	tw := &schema.Tweet{
+		Text: text,
+		Lang: "en",
+		Retweet: &schema.Tweet{
+			Text:     original,
+			Lang:     "en",
+			Entities: &schema.Entities{Hashtags: []*schema.Hashtag{{Text: "original", Indices: indices(original, "#original")}}},
+		},
+	}
+	got := NewTokenizer(Options{}).Tokens(tw)
+	if want := []string{"full", "text"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expecting %q got %q", want, got)
+	}
+}
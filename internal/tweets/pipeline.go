@@ -0,0 +1,164 @@
+package tweets
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+type (
+	// stage enriches tweets before they are sent to the sinks,
+	// tweets are dropped when fn returns false
+	stage struct {
+		name     string
+		fn       func(*schema.Tweet) bool
+		duration prometheus.Observer
+		panics   prometheus.Counter
+		filtered prometheus.Counter
+	}
+
+	// pipeline runs the stages over up to workers tweets at once,
+	// tweets leave the pipeline in the same order they entered
+	pipeline struct {
+		workers int
+		logCtx  zerolog.Logger
+		out     func(*schema.Tweet)
+
+		jobs    chan *job
+		pending chan *job
+		done    chan struct{}
+	}
+
+	job struct {
+		tweet  *schema.Tweet
+		stages []*stage
+		keep   bool
+		done   chan struct{}
+	}
+)
+
+var (
+	stageSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:      "stageSeconds",
+		Namespace: "vogelnest",
+		Subsystem: "enrich",
+		Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+	}, []string{"stage"})
+	stagePanics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "stagePanics",
+		Namespace: "vogelnest",
+		Subsystem: "enrich",
+	}, []string{"stage"})
+	stageFiltered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "stageFiltered",
+		Namespace: "vogelnest",
+		Subsystem: "enrich",
+	}, []string{"stage"})
+	enrichBacklog = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "backlog",
+		Namespace: "vogelnest",
+		Subsystem: "enrich",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(stageSeconds, stagePanics, stageFiltered, enrichBacklog)
+}
+
+func newStage(name string, fn func(*schema.Tweet) bool) *stage {
+	return &stage{
+		name:     name,
+		fn:       fn,
+		duration: stageSeconds.WithLabelValues(name),
+		panics:   stagePanics.WithLabelValues(name),
+		filtered: stageFiltered.WithLabelValues(name),
+	}
+}
+
+// start the workers, out is called with every tweet once its
+// stages are done
+func (p *pipeline) start(out func(*schema.Tweet)) {
+	p.out = out
+	if p.workers <= 1 {
+		return
+	}
+	p.jobs = make(chan *job, p.workers)
+	p.pending = make(chan *job, p.workers*2)
+	p.done = make(chan struct{})
+	for i := 0; i < p.workers; i++ {
+		go p.work()
+	}
+	go p.emit()
+}
+
+// push t through stages, it blocks while the pipeline is full
+func (p *pipeline) push(t *schema.Tweet, stages []*stage) {
+	if p.jobs == nil {
+		if p.run(t, stages) {
+			p.out(t)
+		}
+		return
+	}
+	j := &job{tweet: t, stages: stages, done: make(chan struct{})}
+	p.pending <- j
+	enrichBacklog.Inc()
+	p.jobs <- j
+}
+
+// stop waits for every tweet pushed so far
+func (p *pipeline) stop() {
+	if p.jobs == nil {
+		return
+	}
+	close(p.jobs)
+	close(p.pending)
+	<-p.done
+}
+
+func (p *pipeline) work() {
+	for j := range p.jobs {
+		j.keep = p.run(j.tweet, j.stages)
+		close(j.done)
+	}
+}
+
+// emit tweets in the order they were pushed
+func (p *pipeline) emit() {
+	defer close(p.done)
+	for j := range p.pending {
+		<-j.done
+		enrichBacklog.Dec()
+		if j.keep {
+			p.out(j.tweet)
+		}
+	}
+}
+
+// run the stages over t, returns false if one of them dropped it
+func (p *pipeline) run(t *schema.Tweet, stages []*stage) bool {
+	for _, st := range stages {
+		if !p.runStage(st, t) {
+			st.filtered.Inc()
+			return false
+		}
+	}
+	return true
+}
+
+// runStage calls st with t, a stage which panics doesn't
+// prevent the tweet from reaching the sinks
+func (p *pipeline) runStage(st *stage, t *schema.Tweet) (keep bool) {
+	start := time.Now()
+	defer func() {
+		st.duration.Observe(time.Since(start).Seconds())
+		if r := recover(); r != nil {
+			st.panics.Inc()
+			p.logCtx.Error().Err(fmt.Errorf("%v", r)).Str("action", "enrich").Str("stage", st.name).Int64("tweet", t.Id).Msg("Stage failed")
+			keep = true
+		}
+	}()
+	return st.fn(t)
+}
@@ -0,0 +1,149 @@
+package tweets
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+// collect returns a pipeline with the given workers and the ids
+// of the tweets which left it, read them after stop
+func collect(workers int) (*pipeline, *[]int64) {
+	var ids []int64
+	p := &pipeline{workers: workers}
+	p.start(func(t *schema.Tweet) { ids = append(ids, t.Id) })
+	return p, &ids
+}
+
+func pushIDs(p *pipeline, stages []*stage, n int) {
+	for i := 1; i <= n; i++ {
+		p.push(&schema.Tweet{Id: int64(i)}, stages)
+	}
+}
+
+func sequence(from, to int, skip func(int) bool) []int64 {
+	var out []int64
+	for i := from; i <= to; i++ {
+		if skip == nil || !skip(i) {
+			out = append(out, int64(i))
+		}
+	}
+	return out
+}
+
+// stopWithin fails the test if the pipeline doesn't stop in time
+func stopWithin(t *testing.T, p *pipeline, d time.Duration) {
+	t.Helper()
+	stopped := make(chan struct{})
+	go func() {
+		p.stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(d):
+		t.Fatal("pipeline stalled")
+	}
+}
+
+func TestPipelineOrder(t *testing.T) {
+	// earlier tweets take longer, so they finish last
+	slow := newStage("slow", func(t *schema.Tweet) bool {
+		time.Sleep(time.Duration(20-t.Id) * time.Millisecond)
+		return true
+	})
+	for _, workers := range []int{0, 1, 4} {
+		p, ids := collect(workers)
+		pushIDs(p, []*stage{slow}, 20)
+		stopWithin(t, p, 5*time.Second)
+		if want := sequence(1, 20, nil); !reflect.DeepEqual(*ids, want) {
+			t.Errorf("%v workers: expecting %v got %v", workers, want, *ids)
+		}
+	}
+}
+
+func TestPipelineFilter(t *testing.T) {
+	even := func(i int) bool { return i%2 == 0 }
+	for _, workers := range []int{0, 4} {
+		var after int32
+		stages := []*stage{
+			newStage("odd", func(t *schema.Tweet) bool { return !even(int(t.Id)) }),
+			newStage("after", func(t *schema.Tweet) bool {
+				atomic.AddInt32(&after, 1)
+				return true
+			}),
+		}
+		p, ids := collect(workers)
+		pushIDs(p, stages, 10)
+		stopWithin(t, p, 5*time.Second)
+		if want := sequence(1, 10, even); !reflect.DeepEqual(*ids, want) {
+			t.Errorf("%v workers: expecting %v got %v", workers, want, *ids)
+		}
+		if after != 5 {
+			t.Errorf("%v workers: dropped tweets should skip the next stages, got %v calls", workers, after)
+		}
+	}
+}
+
+func TestPipelinePanic(t *testing.T) {
+	for _, workers := range []int{0, 4} {
+		var after int32
+		stages := []*stage{
+			newStage("broken", func(t *schema.Tweet) bool {
+				if t.Id%3 == 0 {
+					panic("broken stage")
+				}
+				return true
+			}),
+			newStage("after", func(t *schema.Tweet) bool {
+				atomic.AddInt32(&after, 1)
+				return true
+			}),
+		}
+		p, ids := collect(workers)
+		pushIDs(p, stages, 10)
+		stopWithin(t, p, 5*time.Second)
+		// the tweet keeps going, without what the stage would add
+		if want := sequence(1, 10, nil); !reflect.DeepEqual(*ids, want) {
+			t.Errorf("%v workers: expecting %v got %v", workers, want, *ids)
+		}
+		if after != 10 {
+			t.Errorf("%v workers: expecting every tweet in the next stage, got %v", workers, after)
+		}
+	}
+}
+
+func TestPipelineBackpressure(t *testing.T) {
+	const workers, total = 2, 50
+	release := make(chan struct{})
+	blocked := newStage("blocked", func(t *schema.Tweet) bool {
+		<-release
+		return true
+	})
+	p, ids := collect(workers)
+	var pushed int32
+	go func() {
+		for i := 1; i <= total; i++ {
+			p.push(&schema.Tweet{Id: int64(i)}, []*stage{blocked})
+			atomic.AddInt32(&pushed, 1)
+		}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	// workers, their queue and the tweets waiting to be emitted
+	if n := atomic.LoadInt32(&pushed); n == 0 || n > workers*3+1 {
+		t.Errorf("push should block once the pipeline is full, got %v tweets in", n)
+	}
+	close(release)
+	for atomic.LoadInt32(&pushed) < total {
+		time.Sleep(time.Millisecond)
+	}
+	// stop waits for every tweet pushed so far
+	stopWithin(t, p, 5*time.Second)
+	if want := sequence(1, total, nil); !reflect.DeepEqual(*ids, want) {
+		t.Errorf("expecting every tweet after stop, got %v", *ids)
+	}
+}
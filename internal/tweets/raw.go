@@ -0,0 +1,135 @@
+package tweets
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+type (
+	// rawTransport lets the stream read the JSON of each message
+	// while go-twitter decodes it, to extract the entities which
+	// go-twitter doesn't know about
+	rawTransport struct {
+		base   http.RoundTripper
+		onLine func([]byte)
+	}
+
+	// lineReader calls onLine with every line read from body
+	lineReader struct {
+		body   io.ReadCloser
+		buf    []byte
+		onLine func([]byte)
+	}
+
+	// rawIndex keeps raw entities until their tweet is converted,
+	// the oldest are discarded once it holds maxRawEntries
+	rawIndex struct {
+		sync.Mutex
+		byID  map[int64]*schema.RawEntities
+		order []int64
+	}
+)
+
+const (
+	maxRawEntries = 10000
+	// maxLineSize is larger than any tweet, longer lines are skipped
+	maxLineSize = 1 << 20
+)
+
+var (
+	// rawMarkers are only present in messages with raw entities,
+	// other messages are not decoded twice
+	rawMarkers = [][]byte{[]byte(`"symbols":[{`), []byte(`"polls":[{`)}
+)
+
+func (rt *rawTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := rt.base.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+	res.Body = &lineReader{body: res.Body, onLine: rt.onLine}
+	return res, nil
+}
+
+func (lr *lineReader) Read(p []byte) (int, error) {
+	n, err := lr.body.Read(p)
+	lr.buf = append(lr.buf, p[:n]...)
+	for {
+		i := bytes.IndexByte(lr.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if line := bytes.TrimSpace(lr.buf[:i]); len(line) > 0 {
+			lr.onLine(line)
+		}
+		lr.buf = lr.buf[i+1:]
+	}
+	if len(lr.buf) > maxLineSize {
+		lr.buf = nil
+	}
+	return n, err
+}
+
+func (lr *lineReader) Close() error {
+	return lr.body.Close()
+}
+
+// parse the raw entities of the message in line
+func (ri *rawIndex) parse(line []byte) {
+	found := false
+	for _, m := range rawMarkers {
+		found = found || bytes.Contains(line, m)
+	}
+	if !found {
+		return
+	}
+	entities, err := schema.ParseRawEntities(line)
+	if err != nil {
+		return
+	}
+	ri.Lock()
+	defer ri.Unlock()
+	if ri.byID == nil {
+		ri.byID = make(map[int64]*schema.RawEntities)
+	}
+	for id, e := range entities {
+		if _, ok := ri.byID[id]; !ok {
+			ri.order = append(ri.order, id)
+		}
+		ri.byID[id] = e
+	}
+	for len(ri.order) > maxRawEntries {
+		delete(ri.byID, ri.order[0])
+		ri.order = ri.order[1:]
+	}
+}
+
+// take removes and returns the raw entities of t and
+// the tweets it retweets or quotes
+func (ri *rawIndex) take(t *schema.Tweet) map[int64]*schema.RawEntities {
+	ri.Lock()
+	defer ri.Unlock()
+	if len(ri.byID) == 0 {
+		return nil
+	}
+	out := make(map[int64]*schema.RawEntities)
+	var walk func(t *schema.Tweet)
+	walk = func(t *schema.Tweet) {
+		if t == nil {
+			return
+		}
+		if e, ok := ri.byID[t.Id]; ok {
+			out[t.Id] = e
+			// order is cleaned once it grows
+			delete(ri.byID, t.Id)
+		}
+		walk(t.Retweet)
+		walk(t.QuotedStatus)
+	}
+	walk(t)
+	return out
+}
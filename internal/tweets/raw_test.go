@@ -0,0 +1,86 @@
+package tweets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/andrebq/vogelnest/internal/schema"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return fn(req) }
+
+func symbolLine(id int64, symbol string) string {
+	return fmt.Sprintf(`{"id":%v,"entities":{"symbols":[{"text":%q,"indices":[0,5]}]}}`, id, symbol)
+}
+
+func TestRawTransport(t *testing.T) {
+	body := symbolLine(1, "TWTR") + "\r\n\r\n" + `{"id":2}` + "\n" + `{"id":3`
+	var lines []string
+	rt := &rawTransport{
+		base: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			// one byte at a time, so lines are split between reads
+			return &http.Response{Body: ioutil.NopCloser(iotest.OneByteReader(strings.NewReader(body)))}, nil
+		}),
+		onLine: func(line []byte) { lines = append(lines, string(line)) },
+	}
+	res, err := rt.RoundTrip(httptest.NewRequest("GET", "/stream", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != body {
+		t.Errorf("the body should be read unchanged, got %q", buf)
+	}
+	// blank lines are skipped and the last line is incomplete
+	if want := []string{symbolLine(1, "TWTR"), `{"id":2}`}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("expecting lines %q got %q", want, lines)
+	}
+}
+
+func TestRawIndex(t *testing.T) {
+	var ri rawIndex
+	ri.parse([]byte(`{"id":3,"retweeted_status":` + symbolLine(2, "TWTR") + `}`))
+	// no markers, the message isn't decoded
+	ri.parse([]byte(`{"id":4,"entities":{"symbols":[]}}`))
+	ri.parse([]byte(`{"id":5,"entities":{"polls":[{ broken`))
+
+	tw := &schema.Tweet{Id: 3, Retweet: &schema.Tweet{Id: 2}}
+	raw := ri.take(tw)
+	if len(raw) != 1 || raw[2] == nil || raw[2].Symbols[0].Text != "TWTR" {
+		t.Fatalf("expecting the symbols of the retweeted tweet got %v", raw)
+	}
+	if raw := ri.take(tw); len(raw) != 0 {
+		t.Errorf("entities should be taken once, got %v", raw)
+	}
+	if raw := ri.take(&schema.Tweet{Id: 4}); len(raw) != 0 {
+		t.Errorf("expecting nothing for tweet 4 got %v", raw)
+	}
+}
+
+func TestRawIndexEviction(t *testing.T) {
+	var ri rawIndex
+	for id := int64(1); id <= maxRawEntries+1; id++ {
+		ri.parse([]byte(symbolLine(id, "TWTR")))
+	}
+	// the oldest tweet was discarded, it is converted without symbols
+	if raw := ri.take(&schema.Tweet{Id: 1}); len(raw) != 0 {
+		t.Errorf("expecting tweet 1 to be evicted got %v", raw)
+	}
+	if raw := ri.take(&schema.Tweet{Id: 2}); len(raw) != 1 {
+		t.Errorf("expecting tweet 2 to be kept got %v", raw)
+	}
+	if len(ri.byID) != maxRawEntries-1 {
+		t.Errorf("expecting %v entries got %v", maxRawEntries-1, len(ri.byID))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"sync"
 
+	"github.com/andrebq/vogelnest/internal/schema"
 	"github.com/dghubble/go-twitter/twitter"
 	"github.com/dghubble/oauth1"
 	"github.com/rs/zerolog"
@@ -23,14 +24,41 @@ type (
 		sampledLog zerolog.Logger
 
 		terms chan []string
+		// tracking has the terms of the current filter
+		tracking struct {
+			sync.Mutex
+			terms []string
+		}
 
 		client *twitter.Client
+		raw    rawIndex
+
+		enrichers struct {
+			sync.Mutex
+			list []*stage
+		}
+		pipeline pipeline
 
 		outputList struct {
 			sync.Mutex
-			output []chan *twitter.Tweet
+			output   []chan *schema.Tweet
+			lossless []*losslessSink
 		}
 	}
+
+	// StreamOptions configure how tweets are processed
+	StreamOptions struct {
+		// Workers is how many tweets are enriched at once,
+		// tweets still reach the sinks in the order they were received
+		Workers int
+	}
+
+	// losslessSink blocks the stream instead of dropping tweets,
+	// removed is closed to unblock the stream when the consumer is gone
+	losslessSink struct {
+		output  chan *schema.Tweet
+		removed chan struct{}
+	}
 )
 
 var (
@@ -61,10 +89,11 @@ func init() {
 }
 
 // NewStream with tweets
-func NewStream() *Stream {
+func NewStream(opts StreamOptions) *Stream {
 	s := &Stream{
 		terms: make(chan []string),
 	}
+	s.pipeline.workers = opts.Workers
 	return s
 }
 
@@ -73,6 +102,8 @@ func (s *Stream) Serve() {
 	s.validState()
 	s.init()
 	defer s.cleanup()
+	s.pipeline.start(s.writeOutput)
+	defer s.pipeline.stop()
 	s.logCtx.Info().Msg("Starting stream, waiting for terms")
 	var terms []string
 	select {
@@ -115,7 +146,7 @@ func (s *Stream) Serve() {
 				percentFull.Set(float64(t.PercentFull))
 			case *twitter.Tweet:
 				tweetsRecvd.Inc()
-				s.writeOutput(t)
+				s.convert(t)
 			case *twitter.StreamDisconnect:
 				s.logCtx.Warn().Str("event", "disconnect").Str("reason", t.Reason).Str("stream", t.StreamName).Send()
 				return
@@ -142,12 +173,22 @@ func (s *Stream) SetTerms(t []string) bool {
 	}
 }
 
+// Terms returns the terms being tracked, empty
+// until the first filter is obtained
+func (s *Stream) Terms() []string {
+	s.tracking.Lock()
+	defer s.tracking.Unlock()
+	return append([]string(nil), s.tracking.terms...)
+}
+
 // NewSink adds a new tweet sink to this stream
 // slow consumers will have their messages dropped.
 //
+// Tweets are shared by every sink and must not be modified.
+//
 // When the stream is done accepting new tweets the output will be closed
-func (s *Stream) NewSink(buf int) <-chan *twitter.Tweet {
-	o := make(chan *twitter.Tweet, buf)
+func (s *Stream) NewSink(buf int) <-chan *schema.Tweet {
+	o := make(chan *schema.Tweet, buf)
 	s.outputList.Lock()
 	s.outputList.output = append(s.outputList.output, o)
 	s.outputList.Unlock()
@@ -159,33 +200,99 @@ func (s *Stream) NewSink(buf int) <-chan *twitter.Tweet {
 	return o
 }
 
+// NewLosslessSink adds a new tweet sink which never drops tweets,
+// the stream waits for slow consumers instead.
+//
+// Consumers must read from the sink until they call RemoveSink
+// otherwise every other sink is blocked.
+//
+// When the stream is done accepting new tweets the output will be closed
+func (s *Stream) NewLosslessSink(buf int) <-chan *schema.Tweet {
+	ls := &losslessSink{
+		output:  make(chan *schema.Tweet, buf),
+		removed: make(chan struct{}),
+	}
+	s.outputList.Lock()
+	s.outputList.lossless = append(s.outputList.lossless, ls)
+	s.outputList.Unlock()
+	select {
+	case <-s.stop:
+		s.RemoveSink(ls.output)
+	default:
+	}
+	return ls.output
+}
+
 // RemoveSink removes o from the sink and closes it
 //
 // Valid only if the output was part of this sink
-func (s *Stream) RemoveSink(o <-chan *twitter.Tweet) {
+func (s *Stream) RemoveSink(o <-chan *schema.Tweet) {
 	s.outputList.Lock()
 	defer s.outputList.Unlock()
-	last := len(s.outputList.output) - 1
 	for i, v := range s.outputList.output {
 		if v == o {
-			s.outputList.output[i] = nil
-			switch {
-			case i == 0 && last == 0:
-				s.outputList.output = s.outputList.output[:0]
-			case i == last:
-				s.outputList.output = s.outputList.output[:last-1]
-			default:
-				s.outputList.output[i] = s.outputList.output[last]
-				s.outputList.output[last] = nil
-				s.outputList.output = s.outputList.output[:last-1]
-			}
+			last := len(s.outputList.output) - 1
+			s.outputList.output[i] = s.outputList.output[last]
+			s.outputList.output[last] = nil
+			s.outputList.output = s.outputList.output[:last]
+			return
+		}
+	}
+	for i, v := range s.outputList.lossless {
+		if v.output == o {
+			// writeOutput might be waiting on this sink
+			close(v.removed)
+			last := len(s.outputList.lossless) - 1
+			s.outputList.lossless[i] = s.outputList.lossless[last]
+			s.outputList.lossless[last] = nil
+			s.outputList.lossless = s.outputList.lossless[:last]
+			return
 		}
 	}
 }
 
-func (s *Stream) writeOutput(t *twitter.Tweet) {
+// Enrich adds fn to the stages called with every tweet before it
+// is sent to the sinks, in the order they were added. Stages of
+// different tweets run concurrently, name labels their metrics
+func (s *Stream) Enrich(name string, fn func(*schema.Tweet)) {
+	s.addStage(newStage(name, func(t *schema.Tweet) bool {
+		fn(t)
+		return true
+	}))
+}
+
+// Filter adds fn to the stages like Enrich, tweets are not sent
+// to the sinks nor to the following stages when fn returns false
+func (s *Stream) Filter(name string, fn func(*schema.Tweet) bool) {
+	s.addStage(newStage(name, fn))
+}
+
+func (s *Stream) addStage(st *stage) {
+	s.enrichers.Lock()
+	s.enrichers.list = append(s.enrichers.list, st)
+	s.enrichers.Unlock()
+}
+
+// convert t to the schema used by every sink
+func (s *Stream) convert(t *twitter.Tweet) {
+	st := &schema.Tweet{}
+	err := st.Populate(t)
+	if err == nil {
+		err = st.PopulateRaw(s.raw.take(st))
+	}
+	if err != nil {
+		s.logCtx.Error().Err(err).Str("action", "populate").Int64("tweet", t.ID).Msg("Unable to process tweet")
+		droppedTweets.Inc()
+		return
+	}
+	s.enrichers.Lock()
+	enrichers := s.enrichers.list
+	s.enrichers.Unlock()
+	s.pipeline.push(st, enrichers)
+}
+
+func (s *Stream) writeOutput(t *schema.Tweet) {
 	s.outputList.Lock()
-	defer s.outputList.Unlock()
 	none := true
 	for _, v := range s.outputList.output {
 		select {
@@ -194,12 +301,24 @@ func (s *Stream) writeOutput(t *twitter.Tweet) {
 		default:
 		}
 	}
+	// lossless sinks are written without holding the lock,
+	// otherwise RemoveSink would wait for the consumer which is gone
+	lossless := append([]*losslessSink(nil), s.outputList.lossless...)
+	s.outputList.Unlock()
+	for _, v := range lossless {
+		select {
+		case v.output <- t:
+			none = false
+		case <-v.removed:
+		case <-s.stop:
+		}
+	}
 	if none {
 		s.dropTweet(t)
 	}
 }
 
-func (s *Stream) dropTweet(t *twitter.Tweet) {
+func (s *Stream) dropTweet(t *schema.Tweet) {
 	droppedTweets.Inc()
 }
 
@@ -210,6 +329,9 @@ func (s *Stream) cleanup() {
 	for _, v := range s.outputList.output {
 		close(v)
 	}
+	for _, v := range s.outputList.lossless {
+		close(v.output)
+	}
 	s.logCtx.Info().Msg("Output closed")
 }
 
@@ -217,6 +339,7 @@ func (s *Stream) connect() {
 	config := oauth1.NewConfig(os.Getenv("TWITTER_API_KEY"), os.Getenv("TWITTER_API_SECRET_KEY"))
 	token := oauth1.NewToken(os.Getenv("TWITTER_ACCESS_TOKEN"), os.Getenv("TWITTER_ACCESS_TOKEN_SECRET"))
 	httpClient := config.Client(oauth1.NoContext, token)
+	httpClient.Transport = &rawTransport{base: httpClient.Transport, onLine: s.raw.parse}
 
 	s.client = twitter.NewClient(httpClient)
 }
@@ -228,6 +351,7 @@ func (s *Stream) init() {
 	s.stop = make(chan struct{})
 	s.cleanShutdown = make(chan struct{})
 	s.logCtx = log.With().Str("service", "stream").Logger()
+	s.pipeline.logCtx = s.logCtx
 	s.sampledLog = s.logCtx.Sample(zerolog.Sometimes)
 
 	s.connect()
@@ -255,6 +379,9 @@ func (s *Stream) changeTerms(ts *twitter.Stream, terms []string) (*twitter.Strea
 		s.logCtx.Error().Strs("terms", terms).Err(err).Msg("Unable to obtain stream from twitter")
 		return nil, err
 	}
+	s.tracking.Lock()
+	s.tracking.terms = append([]string(nil), terms...)
+	s.tracking.Unlock()
 	return ts, err
 }
 
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/andrebq/vogelnest/internal/storage"
+)
+
+var (
+	keysFile = flag.String("storage-keys", "", "File with the keys used to encrypt stored tweets (or use VOGELNEST_STORAGE_KEYS)")
+)
+
+// loadKeys returns the keyring configured by -storage-keys or
+// VOGELNEST_STORAGE_KEYS, nil means tweets are kept in plain text
+func loadKeys() (*storage.Keyring, error) {
+	if len(*keysFile) > 0 {
+		return storage.LoadKeyring(*keysFile)
+	}
+	if env := os.Getenv("VOGELNEST_STORAGE_KEYS"); len(env) > 0 {
+		return storage.ParseKeyring(env)
+	}
+	return nil, nil
+}
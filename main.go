@@ -4,10 +4,12 @@ import (
 	"flag"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/andrebq/vogelnest/internal/api"
+	"github.com/andrebq/vogelnest/internal/graph"
 	"github.com/andrebq/vogelnest/internal/storage"
 	"github.com/andrebq/vogelnest/internal/tweets"
 	"github.com/rs/zerolog/log"
@@ -20,29 +22,144 @@ var (
 	port        = flag.Int("port", 8080, "Port to listen for incoming requests")
 	serveStatic = flag.String("serve-static", "", "When set, serve static files from this directory")
 	storageDir  = flag.String("storage", "/var/data/vogelnest/tweets", "Where to keep the downloaded data for post-processing")
+	storageKind = flag.String("storage-backend", "badger", "How tweets are stored: badger or segment (append-only files)")
+	batchSize   = flag.Int("storage-batch-size", 100, "Maximum number of tweets written to storage at once")
+	flushEvery  = flag.Duration("storage-flush-interval", time.Second, "Maximum time a tweet waits in memory before being written")
+	syncWrites  = flag.Bool("storage-sync", false, "Wait for writes to reach the disk (slower but survives power loss)")
+	ngrams      = flag.Int("text-ngrams", 1, "Add sequences of up to N words to the tokens of each tweet")
+	stopWords   = flag.Bool("text-stop-words", true, "Remove common words (the, a, of...) from the tokens of each tweet")
+	lexicons    = flag.String("sentiment-lexicons", "", "Directory with the lexicons used to score the sentiment of tweets, empty disables it")
+	sentBucket  = flag.Duration("sentiment-bucket", time.Hour, "Size of the time buckets used to aggregate sentiment")
+	sentBuckets = flag.Int("sentiment-buckets", 48, "Number of sentiment buckets kept in memory")
+	retention   = flag.Duration("storage-retention", 0, "Remove partitions older than this, zero keeps everything")
+	sampleRate  = flag.Int("storage-degraded-sample", 0, "Keep one out of N tweets while storage is degraded, zero drops all")
+	langDetect  = flag.String("lang-detect", "always", "When to detect the language of tweets: always, unknown (only when twitter doesn't know it) or never")
+	langMinConf = flag.Float64("lang-min-confidence", 0, "Confidence required to replace an unknown language, zero uses the detector's own reliability check")
+
+	quota        byteSize
+	minFree      byteSize
+	criticalFree byteSize
 )
 
+func init() {
+	flag.Var(&quota, "storage-quota", "Maximum size of the stored partitions (eg.: 20G), spill and tier cache excluded, zero disables it")
+	flag.Var(&minFree, "storage-min-free", "Remove old partitions when the free disk space drops below this")
+	flag.Var(&criticalFree, "storage-critical-free", "Stop storing tweets when the free disk space drops below this")
+}
+
 func main() {
 	flag.Parse()
 
+	switch flag.Arg(0) {
+	case "", "serve":
+		serve()
+	case "fsck":
+		os.Exit(fsck(flag.Args()[1:]))
+	case "export":
+		os.Exit(export(flag.Args()[1:]))
+	case "migrate":
+		os.Exit(migrate(flag.Args()[1:]))
+	case "graph":
+		os.Exit(graphCommand(flag.Args()[1:]))
+	default:
+		log.Fatal().Str("command", flag.Arg(0)).Msg("Unknown command")
+	}
+}
+
+func serve() {
 	rootLogger := log.With().Str("supervisor", "root").Logger()
 	rootSupervisor := suture.New("root", suture.Spec{
 		Log:     func(s string) { rootLogger.Warn().Msg(s) },
 		Timeout: time.Minute,
 	})
 
-	stream := tweets.NewStream()
+	stream := tweets.NewStream(tweets.StreamOptions{Workers: *enrichWorkers})
+	stages, err := addStages(stream)
+	if err != nil {
+		panic(err)
+	}
 	rootSupervisor.Add(stream)
-	st, err := storage.NewServer(*storageDir, stream)
+	if stages.hooks != nil {
+		rootSupervisor.Add(stages.hooks)
+	}
+	keys, err := loadKeys()
+	if err != nil {
+		panic(err)
+	}
+	tier, err := loadTier()
+	if err != nil {
+		panic(err)
+	}
+	if tier != nil {
+		rootSupervisor.Add(tier)
+	}
+	store, err := storage.OpenStore(*storageKind, *storageDir, storage.StoreOptions{SyncWrites: *syncWrites, Keys: keys, Tier: tier})
+	if err != nil {
+		panic(err)
+	}
+	guard := storage.NewGuard(storage.GuardOptions{
+		Dir:          *storageDir,
+		Quota:        int64(quota),
+		MinFree:      int64(minFree),
+		CriticalFree: int64(criticalFree),
+		Retention:    *retention,
+		SampleRate:   *sampleRate,
+		Tier:         tier,
+	})
+	rootSupervisor.Add(guard)
+	st, err := storage.NewServer(store, stream, storage.ServerOptions{
+		SpillDir:      filepath.Join(*storageDir, "spill"),
+		BatchSize:     *batchSize,
+		FlushInterval: *flushEvery,
+		Keys:          keys,
+		Guard:         guard,
+	})
 	if err != nil {
 		panic(err)
 	}
 	rootSupervisor.Add(st)
-	rootSupervisor.Add(api.NewServer(*bind, *port, *serveStatic,
+	graphs, err := openGraphs()
+	if err != nil {
+		panic(err)
+	}
+	if graphs != nil {
+		builder, err := graph.NewBuilder(graphs, stream, graph.BuilderOptions{
+			Graphs:        graphList(),
+			FlushInterval: *graphFlushes,
+		})
+		if err != nil {
+			panic(err)
+		}
+		rootSupervisor.Add(builder)
+		analyzer, err := newAnalyzer(graphs, stream.Terms)
+		if err != nil {
+			panic(err)
+		}
+		if analyzer != nil {
+			rootSupervisor.Add(analyzer)
+		}
+	}
+	apiServer := api.NewServer(*bind, *port, *serveStatic,
 		strings.Split(os.Getenv("CORS_ORIGINS"), ","),
-		stream.SetTerms, stream.NewSink, stream.RemoveSink))
+		stream.SetTerms, stream.NewSink, stream.RemoveSink, guard.Healthy)
+	if stages.sentiments != nil {
+		apiServer.Handle("/sentiment", stages.sentiments)
+	}
+	if stages.hooks != nil {
+		apiServer.Handle("/hooks/events", stages.hooks)
+	}
+	if graphs != nil {
+		apiServer.Handle("/graph/", graph.NewHandler(graphs, stream.Terms))
+	}
+	rootSupervisor.Add(apiServer)
 	rootSupervisor.ServeBackground()
 	wait(rootSupervisor)
+	if graphs != nil {
+		err = graphs.Close()
+		if err != nil {
+			log.Error().Err(err).Str("action", "close-graphs").Msg("Unable to close graph store")
+		}
+	}
 }
 
 func wait(rootSupervisor *suture.Supervisor) {
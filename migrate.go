@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/andrebq/vogelnest/internal/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// migrate converts the tweet log to the current layout and returns the process exit code
+func migrate(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	out := fs.String("out", "", "Write migrated partitions to this directory instead of replacing them")
+	force := fs.Bool("force", false, "Rewrite partitions already using the current layout (re-encrypts with the active key)")
+	fs.Parse(args)
+
+	keys, err := loadKeys()
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to load storage keys")
+		return 2
+	}
+	reports, err := storage.Migrate(*storageDir, storage.MigrateOptions{
+		OutDir: *out,
+		Force:  *force,
+		Keys:   keys,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("storage", *storageDir).Msg("Unable to migrate tweet log")
+		return 2
+	}
+	code := 0
+	for _, r := range reports {
+		if r.Err != nil {
+			fmt.Printf("%v: unable to migrate: %v\n", r.Partition, r.Err)
+			code = 1
+			continue
+		}
+		fmt.Printf("%v: %v, %v -> %v, %v entries\n", r.Partition, r.Action, r.From, storage.CurrentLayout, r.Entries)
+	}
+	return code
+}
@@ -17,5 +17,8 @@ exec.execute("docker", "run", "--name", "vogelnest", "--rm", "-ti",
     "-e", 'TWITTER_API_SECRET_KEY',
     "-e", 'TWITTER_ACCESS_TOKEN',
     "-e", 'TWITTER_ACCESS_TOKEN_SECRET',
+    "-e", 'VOGELNEST_STORAGE_KEYS',
+    "-e", 'VOGELNEST_TIER_ACCESS_KEY',
+    "-e", 'VOGELNEST_TIER_SECRET_KEY',
     "-p", "8080:8080",
     "andrebq/vogelnest:latest")
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/andrebq/vogelnest/internal/storage"
+)
+
+var (
+	tierEndpoint = flag.String("tier-endpoint", "", "S3-compatible endpoint (host:port) receiving sealed partitions, empty keeps everything local")
+	tierBucket   = flag.String("tier-bucket", "vogelnest", "Bucket receiving sealed partitions")
+	tierPrefix   = flag.String("tier-prefix", "", "Prefix added to the name of uploaded partitions")
+	tierRegion   = flag.String("tier-region", "", "Region of the bucket")
+	tierInsecure = flag.Bool("tier-insecure", false, "Use plain HTTP to talk to the endpoint")
+	tierGrace    = flag.Duration("tier-grace", 24*time.Hour, "How long partitions are kept locally after being uploaded")
+
+	tierCacheSize = byteSize(1 << 30)
+)
+
+func init() {
+	flag.Var(&tierCacheSize, "tier-cache-size", "Disk space used by partitions downloaded from the bucket for reading")
+}
+
+// loadTier returns the tier configured by the -tier flags, credentials are
+// read from VOGELNEST_TIER_ACCESS_KEY and VOGELNEST_TIER_SECRET_KEY.
+// nil means partitions are only kept locally
+func loadTier() (*storage.Tier, error) {
+	if len(*tierEndpoint) == 0 {
+		return nil, nil
+	}
+	remote, err := storage.NewS3Remote(storage.S3Options{
+		Endpoint:  *tierEndpoint,
+		Bucket:    *tierBucket,
+		Prefix:    *tierPrefix,
+		Region:    *tierRegion,
+		AccessKey: os.Getenv("VOGELNEST_TIER_ACCESS_KEY"),
+		SecretKey: os.Getenv("VOGELNEST_TIER_SECRET_KEY"),
+		Insecure:  *tierInsecure,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return storage.NewTier(storage.TierOptions{
+		Dir:       *storageDir,
+		Remote:    remote,
+		Grace:     *tierGrace,
+		CacheSize: int64(tierCacheSize),
+	}), nil
+}